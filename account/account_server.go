@@ -4,11 +4,13 @@ import (
 	"crypto/md5"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"sync"
 	"time"
 	"towerdefense/repository"
+	"towerdefense/storage"
 	"towerdefense/utils"
 	
 	"github.com/google/uuid"
@@ -17,9 +19,9 @@ import (
 // AccountServer 账号服务器
 type AccountServer struct {
 	accounts       map[string]*Account  // username -> account (内存缓存)
-	tokens         map[string]*Session  // token -> session
 	gameServers    []*GameServerInfo    // 区服列表
 	accountRepo    *repository.AccountRepository  // 账号仓储
+	sessionRepo    *repository.SessionRepository  // 会话仓储（token 存于共享存储，到期自动清理）
 	mu             sync.RWMutex
 }
 
@@ -61,50 +63,17 @@ func GetAccountServer() *AccountServer {
 	accountOnce.Do(func() {
 		accountServer = &AccountServer{
 			accounts:    make(map[string]*Account),
-			tokens:      make(map[string]*Session),
 			accountRepo: repository.NewAccountRepository(),
+			sessionRepo: repository.NewSessionRepository(),
 		}
 		accountServer.InitGameServers()
 		accountServer.LoadAccountsFromStorage()
 		
-		// 启动定时清理过期 token 的协程
-		go accountServer.tokenCleanupRoutine()
-		
 		utils.Info("账号服务器初始化完成")
 	})
 	return accountServer
 }
 
-// tokenCleanupRoutine 定时清理过期 token（防止内存泄漏）
-func (as *AccountServer) tokenCleanupRoutine() {
-	ticker := time.NewTicker(10 * time.Minute) // 每10分钟清理一次
-	defer ticker.Stop()
-	
-	for range ticker.C {
-		as.cleanupExpiredTokens()
-	}
-}
-
-// cleanupExpiredTokens 清理过期的 token
-func (as *AccountServer) cleanupExpiredTokens() {
-	as.mu.Lock()
-	defer as.mu.Unlock()
-	
-	now := time.Now()
-	expiredCount := 0
-	
-	for token, session := range as.tokens {
-		if now.After(session.ExpireTime) {
-			delete(as.tokens, token)
-			expiredCount++
-		}
-	}
-	
-	if expiredCount > 0 {
-		utils.Info("清理过期token: %d 个，剩余: %d 个", expiredCount, len(as.tokens))
-	}
-}
-
 // LoadAccountsFromStorage 从存储加载账号数据到内存缓存
 func (as *AccountServer) LoadAccountsFromStorage() {
 	// 启动时加载所有账号到内存（可选优化）
@@ -163,6 +132,10 @@ func (as *AccountServer) RegisterAccount(username, password string) (*Account, e
 	}
 	
 	if err := as.accountRepo.Save(accountData); err != nil {
+		if errors.Is(err, storage.ErrVersionConflict) {
+			// 并发注册同名账号，其他请求已先写入
+			return nil, fmt.Errorf("用户名已存在")
+		}
 		utils.Error("保存账号到存储失败: %v", err)
 		return nil, fmt.Errorf("注册失败: %v", err)
 	}
@@ -227,37 +200,45 @@ func (as *AccountServer) Login(username, password string) (*Session, error) {
 		ExpireTime: time.Now().Add(24 * time.Hour), // 24小时有效
 	}
 	
-	as.tokens[token] = session
+	if err := as.sessionRepo.Save(toSessionData(session)); err != nil {
+		utils.Error("保存会话失败: %v", err)
+		return nil, fmt.Errorf("登录失败: %v", err)
+	}
 	utils.Info("玩家登录成功: %s, PlayerID: %s, Token: %s", username, account.PlayerID, token)
 	
 	return session, nil
 }
 
-// invalidateUserTokens 使指定玩家的所有旧token失效（内部方法，调用时已持有锁）
+// invalidateUserTokens 使指定玩家的旧token失效（内部方法，调用时已持有锁）
 func (as *AccountServer) invalidateUserTokens(playerID string) {
-	for token, session := range as.tokens {
-		if session.PlayerID == playerID {
-			delete(as.tokens, token)
-			utils.Info("使旧token失效: %s", token)
-		}
+	token := as.sessionRepo.GetTokenByPlayer(playerID)
+	if token == "" {
+		return
+	}
+	
+	session, err := as.sessionRepo.Get(token)
+	if err != nil {
+		return
 	}
+	if err := as.sessionRepo.Delete(session); err != nil {
+		utils.Warn("使旧token失效失败: %v", err)
+		return
+	}
+	utils.Info("使旧token失效: %s", token)
 }
 
 // VerifyToken 验证token
 func (as *AccountServer) VerifyToken(token string) (*Session, error) {
-	as.mu.RLock()
-	defer as.mu.RUnlock()
-	
-	session, exists := as.tokens[token]
-	if !exists {
+	data, err := as.sessionRepo.Get(token)
+	if err != nil {
 		return nil, fmt.Errorf("token不存在")
 	}
 	
-	if time.Now().After(session.ExpireTime) {
+	if time.Now().After(data.ExpireTime) {
 		return nil, fmt.Errorf("token已过期")
 	}
 	
-	return session, nil
+	return fromSessionData(data), nil
 }
 
 // InvalidateToken 主动使 token 失效（客户端断开连接时调用）
@@ -265,17 +246,45 @@ func (as *AccountServer) InvalidateToken(token string) {
 	as.mu.Lock()
 	defer as.mu.Unlock()
 	
-	if session, exists := as.tokens[token]; exists {
-		delete(as.tokens, token)
-		utils.Info("主动使token失效: %s (玩家: %s)", token, session.Username)
+	session, err := as.sessionRepo.Get(token)
+	if err != nil {
+		return
+	}
+	if err := as.sessionRepo.Delete(session); err != nil {
+		utils.Warn("使token失效失败: %v", err)
+		return
 	}
+	utils.Info("主动使token失效: %s (玩家: %s)", token, session.Username)
 }
 
-// GetTokenCount 获取当前 token 数量（用于监控）
+// GetTokenCount 获取当前有效 token 数量（用于监控）
 func (as *AccountServer) GetTokenCount() int {
-	as.mu.RLock()
-	defer as.mu.RUnlock()
-	return len(as.tokens)
+	count, err := as.sessionRepo.Count()
+	if err != nil {
+		utils.Warn("统计token数量失败: %v", err)
+		return 0
+	}
+	return count
+}
+
+// toSessionData 会话转换为存储模型
+func toSessionData(session *Session) *repository.SessionData {
+	return &repository.SessionData{
+		Token:      session.Token,
+		PlayerID:   session.PlayerID,
+		Username:   session.Username,
+		ExpireTime: session.ExpireTime,
+	}
+}
+
+// fromSessionData 存储模型转换为会话
+func fromSessionData(data *repository.SessionData) *Session {
+	return &Session{
+		Token:      data.Token,
+		PlayerID:   data.PlayerID,
+		Username:   data.Username,
+		ExpireTime: data.ExpireTime,
+	}
 }
 
 // GetGameServerList 获取区服列表
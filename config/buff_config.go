@@ -0,0 +1,159 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"towerdefense/utils"
+)
+
+// Buff 效果类型
+const (
+	BuffEffectSlow        = "slow"         // 减速：移动速度降低 value 比例
+	BuffEffectPoison      = "poison"       // 持续伤害：每 tick_interval 秒造成 value 点真实伤害
+	BuffEffectStun        = "stun"         // 眩晕：无法移动
+	BuffEffectArmorShred  = "armor_shred"  // 破甲：护甲降低 value 点
+	BuffEffectAttackSpeed = "attack_speed" // 攻速光环：防御塔攻击间隔缩短 value 比例
+	BuffEffectDamageAmp   = "damage_amp"   // 易伤：受到的伤害提高 value 比例
+)
+
+// Buff 叠加规则
+const (
+	BuffStackRefresh     = "refresh"     // 同一目标只保留一个，再次施加时刷新持续时间
+	BuffStackStack       = "stack"       // 再次施加时层数 +1（不超过 max_stacks）并刷新持续时间，效果按层数计算
+	BuffStackIndependent = "independent" // 每个来源各自保留一个，同一来源再次施加时刷新持续时间
+)
+
+// BuffDef Buff 定义
+type BuffDef struct {
+	ID           int     `json:"id"`
+	Name         string  `json:"name"`
+	Effect       string  `json:"effect"`
+	Value        float64 `json:"value"`         // 每层的效果数值，含义见效果类型
+	Duration     float32 `json:"duration"`      // 持续时间（秒）
+	TickInterval float32 `json:"tick_interval"` // 结算间隔（秒），仅持续伤害使用
+	StackRule    string  `json:"stack_rule"`    // refresh, stack, independent，为空表示 refresh
+	MaxStacks    int     `json:"max_stacks"`    // 最大层数（仅 stack）
+}
+
+// TargetsTower 是否为施加给防御塔的 Buff（其余施加给敌人）
+func (bd *BuffDef) TargetsTower() bool {
+	return bd.Effect == BuffEffectAttackSpeed
+}
+
+// BuffConfig Buff 配置
+type BuffConfig struct {
+	Buffs []BuffDef `json:"buffs"`
+
+	byID map[int]*BuffDef
+}
+
+var Buffs BuffConfig
+
+// GetBuff 获取 Buff 定义
+func (bc *BuffConfig) GetBuff(id int) (*BuffDef, bool) {
+	buff, ok := bc.byID[id]
+	return buff, ok
+}
+
+// defaultBuffConfig 默认 Buff 配置
+func defaultBuffConfig() BuffConfig {
+	cfg := BuffConfig{
+		Buffs: []BuffDef{
+			{ID: 1, Name: "减速", Effect: BuffEffectSlow, Value: 0.3, Duration: 2, StackRule: BuffStackRefresh},
+			{ID: 2, Name: "中毒", Effect: BuffEffectPoison, Value: 4, Duration: 3, TickInterval: 0.5, StackRule: BuffStackIndependent},
+			{ID: 3, Name: "眩晕", Effect: BuffEffectStun, Duration: 0.3, StackRule: BuffStackRefresh},
+			{ID: 4, Name: "破甲", Effect: BuffEffectArmorShred, Value: 2, Duration: 4, StackRule: BuffStackStack, MaxStacks: 5},
+			{ID: 5, Name: "战鼓", Effect: BuffEffectAttackSpeed, Value: 0.2, Duration: 1, StackRule: BuffStackRefresh},
+			{ID: 6, Name: "易伤", Effect: BuffEffectDamageAmp, Value: 0.15, Duration: 3, StackRule: BuffStackRefresh},
+		},
+	}
+	cfg.buildIndex()
+	return cfg
+}
+
+// LoadBuffConfig 加载 Buff 配置，文件不存在时使用默认配置
+// 防御塔配置会引用 Buff，需在 LoadTowerConfig 之前调用
+func LoadBuffConfig(path string) error {
+	file, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		utils.Info("未找到 Buff 配置 %s，使用默认配置", path)
+		Buffs = defaultBuffConfig()
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("读取 Buff 配置失败: %v", err)
+	}
+
+	var cfg BuffConfig
+	if err := json.Unmarshal(file, &cfg); err != nil {
+		return fmt.Errorf("Buff 配置解析失败: %v", err)
+	}
+	if err := cfg.validate(); err != nil {
+		return fmt.Errorf("Buff 配置无效: %v", err)
+	}
+	cfg.buildIndex()
+
+	Buffs = cfg
+	utils.Info("Buff 配置加载成功: %s, Buff 数量: %d", path, len(cfg.Buffs))
+	return nil
+}
+
+// validate 校验配置
+func (bc *BuffConfig) validate() error {
+	seen := make(map[int]bool)
+	for _, buff := range bc.Buffs {
+		if buff.ID <= 0 {
+			return fmt.Errorf("Buff ID 必须大于 0: %s", buff.Name)
+		}
+		if seen[buff.ID] {
+			return fmt.Errorf("Buff ID 重复: %d", buff.ID)
+		}
+		seen[buff.ID] = true
+
+		if buff.Name == "" {
+			return fmt.Errorf("Buff %d: 名称不能为空", buff.ID)
+		}
+		switch buff.Effect {
+		case BuffEffectSlow, BuffEffectAttackSpeed:
+			if buff.Value <= 0 || buff.Value >= 1 {
+				return fmt.Errorf("Buff %d: %s 的 value 必须在 0~1 之间", buff.ID, buff.Effect)
+			}
+		case BuffEffectPoison:
+			if buff.Value <= 0 || buff.TickInterval <= 0 {
+				return fmt.Errorf("Buff %d: 持续伤害需要配置大于 0 的 value 和 tick_interval", buff.ID)
+			}
+		case BuffEffectArmorShred, BuffEffectDamageAmp:
+			if buff.Value <= 0 {
+				return fmt.Errorf("Buff %d: value 必须大于 0", buff.ID)
+			}
+		case BuffEffectStun:
+		default:
+			return fmt.Errorf("Buff %d: 未知的效果类型 %s", buff.ID, buff.Effect)
+		}
+		if buff.Effect != BuffEffectPoison && buff.TickInterval != 0 {
+			return fmt.Errorf("Buff %d: 只有持续伤害可以配置 tick_interval", buff.ID)
+		}
+		if buff.Duration <= 0 {
+			return fmt.Errorf("Buff %d: duration 必须大于 0", buff.ID)
+		}
+		switch buff.StackRule {
+		case "", BuffStackRefresh, BuffStackIndependent:
+		case BuffStackStack:
+			if buff.MaxStacks <= 0 {
+				return fmt.Errorf("Buff %d: 叠层 Buff 需要配置大于 0 的 max_stacks", buff.ID)
+			}
+		default:
+			return fmt.Errorf("Buff %d: 未知的叠加规则 %s", buff.ID, buff.StackRule)
+		}
+	}
+	return nil
+}
+
+// buildIndex 建立 Buff ID 索引
+func (bc *BuffConfig) buildIndex() {
+	bc.byID = make(map[int]*BuffDef, len(bc.Buffs))
+	for i := range bc.Buffs {
+		bc.byID[bc.Buffs[i].ID] = &bc.Buffs[i]
+	}
+}
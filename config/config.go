@@ -2,6 +2,7 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"towerdefense/utils"
 )
@@ -15,6 +16,7 @@ type ServerConfig struct {
 	HeartbeatInterval int     `json:"heartbeat_interval"` // 秒
 	SessionTimeout    int     `json:"session_timeout"`    // 秒
 	TickRate          int     `json:"tick_rate"`          // 游戏逻辑帧率
+	GMToken           string  `json:"gm_token"`           // GM 接口鉴权令牌，为空时关闭 GM 接口
 }
 
 // GameConfig 游戏配置
@@ -25,6 +27,15 @@ type GameConfig struct {
 	EnemySpawnInterval float64 `json:"enemy_spawn_interval"` // 敌人生成间隔（秒）
 }
 
+// NameConfig 玩家名称规则
+type NameConfig struct {
+	MinLength         int    `json:"min_length"`          // 最少字符数（按字符计，中文算 1 个）
+	MaxLength         int    `json:"max_length"`
+	RenameCost        int    `json:"rename_cost"`         // 改名消耗钻石（首次改名免费）
+	RenameCooldown    int    `json:"rename_cooldown"`     // 改名冷却（小时）
+	SensitiveWordFile string `json:"sensitive_word_file"` // 敏感词库（与聊天共用）
+}
+
 // StorageConfig 存储配置
 type StorageConfig struct {
 	Type     string                 `json:"type"`      // txt, mysql, redis
@@ -34,6 +45,7 @@ type StorageConfig struct {
 var (
 	Server  ServerConfig
 	Game    GameConfig
+	Name    NameConfig
 	Storage StorageConfig
 )
 
@@ -57,6 +69,14 @@ func LoadConfig() {
 		EnemySpawnInterval: 1.0,
 	}
 	
+	Name = NameConfig{
+		MinLength:         2,
+		MaxLength:         12,
+		RenameCost:        100,
+		RenameCooldown:    24,
+		SensitiveWordFile: "sensitive_words.txt",
+	}
+	
 	// 默认使用TXT存储
 	Storage = StorageConfig{
 		Type: "txt",
@@ -71,13 +91,16 @@ func LoadConfig() {
 		var cfg struct {
 			Server  ServerConfig  `json:"server"`
 			Game    GameConfig    `json:"game"`
+			Name    NameConfig    `json:"name"`
 			Storage StorageConfig `json:"storage"`
 		}
+		cfg.Name = Name // 未配置 name 段时保留默认规则
 		
 		if err := json.Unmarshal(file, &cfg); err == nil {
 			// 应用配置
 			Server = cfg.Server
 			Game = cfg.Game
+			Name = cfg.Name
 			Storage = cfg.Storage
 			utils.Info("配置文件加载成功: config.json")
 		} else {
@@ -87,3 +110,23 @@ func LoadConfig() {
 		utils.Info("未找到配置文件，使用默认配置")
 	}
 }
+
+// LoadStorageConfig 从指定配置文件读取存储配置（用于迁移、备份等工具指定源/目标存储）
+// 文件格式与 config.json 相同，只读取其中的 storage 段
+func LoadStorageConfig(path string) (StorageConfig, error) {
+	var cfg struct {
+		Storage StorageConfig `json:"storage"`
+	}
+	
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return cfg.Storage, fmt.Errorf("读取配置文件失败: %v", err)
+	}
+	if err := json.Unmarshal(file, &cfg); err != nil {
+		return cfg.Storage, fmt.Errorf("配置文件解析失败: %v", err)
+	}
+	if cfg.Storage.Type == "" {
+		return cfg.Storage, fmt.Errorf("配置文件缺少 storage.type: %s", path)
+	}
+	return cfg.Storage, nil
+}
@@ -0,0 +1,222 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"towerdefense/utils"
+)
+
+// 成长曲线类型
+const (
+	ScalingCurveLinear      = "linear"      // 倍率 = 1 + rate*(波次-1)
+	ScalingCurveExponential = "exponential" // 倍率 = (1+rate)^(波次-1)
+)
+
+// EnemyScaling 属性随波次成长的曲线
+type EnemyScaling struct {
+	Curve string  `json:"curve"` // linear, exponential，为空表示不成长
+	Rate  float64 `json:"rate"`
+	Max   float64 `json:"max"` // 倍率上限，0 表示不限制（exponential 必须配置）
+}
+
+// Multiplier 第 waveNum 波的属性倍率
+func (es EnemyScaling) Multiplier(waveNum int) float64 {
+	if waveNum < 1 {
+		waveNum = 1
+	}
+	multiplier := 1.0
+	switch es.Curve {
+	case ScalingCurveLinear:
+		multiplier = 1 + es.Rate*float64(waveNum-1)
+	case ScalingCurveExponential:
+		multiplier = math.Pow(1+es.Rate, float64(waveNum-1))
+	}
+	if es.Max > 0 && multiplier > es.Max {
+		multiplier = es.Max
+	}
+	return multiplier
+}
+
+// EnemyReward 击杀奖励公式：金币 = base_gold + gold_per_wave*(波次-1)
+type EnemyReward struct {
+	BaseGold    int     `json:"base_gold"`
+	GoldPerWave float64 `json:"gold_per_wave"`
+}
+
+// Gold 第 waveNum 波的击杀金币，再乘以关卡难度倍率
+func (er EnemyReward) Gold(waveNum int, difficulty float64) int {
+	if waveNum < 1 {
+		waveNum = 1
+	}
+	return int((float64(er.BaseGold) + er.GoldPerWave*float64(waveNum-1)) * difficulty)
+}
+
+// DifficultyScaling 关卡难度对敌人属性和击杀奖励的倍率
+// 以关卡的 difficulty 代替波次套用成长曲线，难度 1 为基准倍率 1
+type DifficultyScaling struct {
+	HP    EnemyScaling `json:"hp"`
+	Speed EnemyScaling `json:"speed"`
+	Gold  EnemyScaling `json:"gold"`
+}
+
+// EnemyDef 敌人定义
+type EnemyDef struct {
+	Type         int          `json:"type"`
+	Name         string       `json:"name"`
+	VisualID     int          `json:"visual_id"` // 客户端模型/特效ID
+	HP           int          `json:"hp"`
+	Speed        float32      `json:"speed"`
+	Damage       int          `json:"damage"`       // 到达终点扣除的生命
+	Flying       bool         `json:"flying"`       // 飞行单位：从出生点直线飞向终点，只能被防空塔攻击
+	Armor        int          `json:"armor"`        // 护甲（减免物理伤害）
+	MagicResist  float64      `json:"magic_resist"` // 魔法抗性 0~1（按比例减免魔法伤害）
+	HPScaling    EnemyScaling `json:"hp_scaling"`
+	SpeedScaling EnemyScaling `json:"speed_scaling"`
+	Reward       EnemyReward  `json:"reward"`
+}
+
+// HPAt 第 waveNum 波的生命值，再乘以关卡难度倍率
+func (ed *EnemyDef) HPAt(waveNum int, difficulty float64) int {
+	return int(float64(ed.HP) * ed.HPScaling.Multiplier(waveNum) * difficulty)
+}
+
+// SpeedAt 第 waveNum 波的移动速度，再乘以关卡难度倍率
+func (ed *EnemyDef) SpeedAt(waveNum int, difficulty float64) float32 {
+	return float32(float64(ed.Speed) * ed.SpeedScaling.Multiplier(waveNum) * difficulty)
+}
+
+// EnemyConfig 敌人配置
+type EnemyConfig struct {
+	Enemies    []EnemyDef        `json:"enemies"`
+	Difficulty DifficultyScaling `json:"difficulty_scaling"` // 不配置时不随难度变化
+
+	byType map[int]*EnemyDef
+}
+
+var Enemies EnemyConfig
+
+// GetEnemy 获取敌人定义
+func (ec *EnemyConfig) GetEnemy(enemyType int) (*EnemyDef, bool) {
+	enemy, ok := ec.byType[enemyType]
+	return enemy, ok
+}
+
+// defaultEnemyConfig 默认敌人配置（不随波次成长）
+func defaultEnemyConfig() EnemyConfig {
+	cfg := EnemyConfig{
+		Enemies: []EnemyDef{
+			{Type: 1, Name: "小兵", VisualID: 1, HP: 50, Speed: 2.0, Damage: 1, Reward: EnemyReward{BaseGold: 10}},
+			{Type: 2, Name: "中型敌人", VisualID: 2, HP: 100, Speed: 1.5, Damage: 2, Reward: EnemyReward{BaseGold: 20}},
+			{Type: 3, Name: "Boss", VisualID: 3, HP: 500, Speed: 1.0, Damage: 5, Reward: EnemyReward{BaseGold: 100}},
+		},
+	}
+	cfg.buildIndex()
+	return cfg
+}
+
+// LoadEnemyConfig 加载敌人配置，文件不存在时使用默认配置
+func LoadEnemyConfig(path string) error {
+	Enemies = defaultEnemyConfig()
+
+	file, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			utils.Info("未找到敌人配置 %s，使用默认配置", path)
+			return nil
+		}
+		return fmt.Errorf("读取敌人配置失败: %v", err)
+	}
+
+	var cfg EnemyConfig
+	if err := json.Unmarshal(file, &cfg); err != nil {
+		return fmt.Errorf("敌人配置解析失败: %v", err)
+	}
+	if err := cfg.validate(); err != nil {
+		return fmt.Errorf("敌人配置无效: %v", err)
+	}
+	cfg.buildIndex()
+
+	Enemies = cfg
+	utils.Info("敌人配置加载成功: %s, 敌人数量: %d", path, len(cfg.Enemies))
+	return nil
+}
+
+// validate 校验配置
+func (ec *EnemyConfig) validate() error {
+	if len(ec.Enemies) == 0 {
+		return fmt.Errorf("至少需要配置一种敌人")
+	}
+
+	// 指数曲线在波次或难度较高时会溢出（int(+Inf)），必须配置上限
+	checkScaling := func(owner, name string, scaling EnemyScaling) error {
+		switch scaling.Curve {
+		case "", ScalingCurveLinear:
+		case ScalingCurveExponential:
+			if scaling.Max == 0 {
+				return fmt.Errorf("%s: %s 使用指数成长曲线时必须配置 max", owner, name)
+			}
+		default:
+			return fmt.Errorf("%s: %s 未知的成长曲线 %s", owner, name, scaling.Curve)
+		}
+		if scaling.Rate < 0 {
+			return fmt.Errorf("%s: %s 的 rate 不能为负数", owner, name)
+		}
+		if scaling.Max != 0 && scaling.Max < 1 {
+			return fmt.Errorf("%s: %s 的 max 必须不小于 1", owner, name)
+		}
+		return nil
+	}
+
+	for name, scaling := range map[string]EnemyScaling{
+		"hp": ec.Difficulty.HP, "speed": ec.Difficulty.Speed, "gold": ec.Difficulty.Gold,
+	} {
+		if err := checkScaling("difficulty_scaling", name, scaling); err != nil {
+			return err
+		}
+	}
+
+	seen := make(map[int]bool)
+	for _, enemy := range ec.Enemies {
+		if enemy.Type <= 0 {
+			return fmt.Errorf("敌人类型必须大于 0: %s", enemy.Name)
+		}
+		if seen[enemy.Type] {
+			return fmt.Errorf("敌人类型重复: %d", enemy.Type)
+		}
+		seen[enemy.Type] = true
+
+		if enemy.Name == "" {
+			return fmt.Errorf("敌人 %d: 名称不能为空", enemy.Type)
+		}
+		if enemy.HP <= 0 || enemy.Speed <= 0 {
+			return fmt.Errorf("敌人 %d: hp 和 speed 必须大于 0", enemy.Type)
+		}
+		if enemy.Damage < 0 || enemy.Armor < 0 {
+			return fmt.Errorf("敌人 %d: damage 和 armor 不能为负数", enemy.Type)
+		}
+		if enemy.MagicResist < 0 || enemy.MagicResist > 1 {
+			return fmt.Errorf("敌人 %d: magic_resist 必须在 0~1 之间", enemy.Type)
+		}
+		if enemy.Reward.BaseGold < 0 || enemy.Reward.GoldPerWave < 0 {
+			return fmt.Errorf("敌人 %d: 奖励不能为负数", enemy.Type)
+		}
+		owner := fmt.Sprintf("敌人 %d", enemy.Type)
+		if err := checkScaling(owner, "hp_scaling", enemy.HPScaling); err != nil {
+			return err
+		}
+		if err := checkScaling(owner, "speed_scaling", enemy.SpeedScaling); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildIndex 建立敌人类型索引
+func (ec *EnemyConfig) buildIndex() {
+	ec.byType = make(map[int]*EnemyDef, len(ec.Enemies))
+	for i := range ec.Enemies {
+		ec.byType[ec.Enemies[i].Type] = &ec.Enemies[i]
+	}
+}
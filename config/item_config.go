@@ -0,0 +1,148 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"towerdefense/utils"
+)
+
+// 道具分类
+const (
+	ItemCategoryConsumable = "consumable" // 消耗品（可使用）
+	ItemCategoryMaterial   = "material"   // 材料
+)
+
+// 道具效果类型
+const (
+	ItemEffectStartGold = "start_gold" // 下一场战斗额外初始金币
+)
+
+// ItemEffect 道具使用效果
+type ItemEffect struct {
+	Type  string `json:"type"`
+	Value int    `json:"value"`
+}
+
+// ItemDef 道具定义
+type ItemDef struct {
+	ID            int         `json:"id"`
+	Name          string      `json:"name"`
+	Category      string      `json:"category"`
+	Stackable     bool        `json:"stackable"`
+	MaxStack      int         `json:"max_stack"`      // 单格堆叠上限（不可堆叠时为 1）
+	ExpireSeconds int64       `json:"expire_seconds"` // 获得后多久过期，0 表示永久
+	Effect        *ItemEffect `json:"effect"`         // 使用效果，为空表示不可使用
+}
+
+// ItemConfig 道具配置
+type ItemConfig struct {
+	Items []ItemDef `json:"items"`
+
+	byID map[int]*ItemDef
+}
+
+var Items ItemConfig
+
+// GetItem 获取道具定义
+func (ic *ItemConfig) GetItem(itemID int) (*ItemDef, bool) {
+	item, ok := ic.byID[itemID]
+	return item, ok
+}
+
+// defaultItemConfig 默认道具配置
+func defaultItemConfig() ItemConfig {
+	cfg := ItemConfig{
+		Items: []ItemDef{
+			{ID: 1001, Name: "加速卷轴", Category: ItemCategoryMaterial, Stackable: true, MaxStack: 99},
+			{ID: 1002, Name: "金币加成卡", Category: ItemCategoryConsumable, Stackable: true, MaxStack: 20,
+				ExpireSeconds: 7 * 24 * 3600, Effect: &ItemEffect{Type: ItemEffectStartGold, Value: 200}},
+		},
+	}
+	cfg.buildIndex()
+	return cfg
+}
+
+// LoadItemConfig 加载道具配置，文件不存在时使用默认配置
+// 会校验奖励配置的掉落表是否引用了未定义的道具，需在 LoadRewardConfig 之后调用
+func LoadItemConfig(path string) error {
+	var cfg ItemConfig
+	file, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		utils.Info("未找到道具配置 %s，使用默认配置", path)
+		cfg = defaultItemConfig()
+	case err != nil:
+		return fmt.Errorf("读取道具配置失败: %v", err)
+	default:
+		if err := json.Unmarshal(file, &cfg); err != nil {
+			return fmt.Errorf("道具配置解析失败: %v", err)
+		}
+		if err := cfg.validate(); err != nil {
+			return fmt.Errorf("道具配置无效: %v", err)
+		}
+		cfg.buildIndex()
+		utils.Info("道具配置加载成功: %s, 道具数量: %d", path, len(cfg.Items))
+	}
+
+	for name, entries := range Reward.DropTables {
+		for _, entry := range entries {
+			if _, ok := cfg.GetItem(entry.ItemID); !ok {
+				return fmt.Errorf("掉落表 %s 引用了未定义的道具 %d", name, entry.ItemID)
+			}
+		}
+	}
+
+	Items = cfg
+	return nil
+}
+
+// validate 校验配置
+func (ic *ItemConfig) validate() error {
+	seen := make(map[int]bool)
+	for _, item := range ic.Items {
+		if item.ID <= 0 {
+			return fmt.Errorf("道具ID必须大于 0: %s", item.Name)
+		}
+		if seen[item.ID] {
+			return fmt.Errorf("道具ID重复: %d", item.ID)
+		}
+		seen[item.ID] = true
+
+		switch item.Category {
+		case ItemCategoryConsumable, ItemCategoryMaterial:
+		default:
+			return fmt.Errorf("道具 %d: 未知的分类 %s", item.ID, item.Category)
+		}
+		if item.Stackable && item.MaxStack <= 0 {
+			return fmt.Errorf("道具 %d: 可堆叠道具的 max_stack 必须大于 0", item.ID)
+		}
+		if item.ExpireSeconds < 0 {
+			return fmt.Errorf("道具 %d: expire_seconds 不能为负数", item.ID)
+		}
+		if item.Effect != nil {
+			if item.Category != ItemCategoryConsumable {
+				return fmt.Errorf("道具 %d: 只有消耗品可以配置使用效果", item.ID)
+			}
+			if item.Effect.Type != ItemEffectStartGold {
+				return fmt.Errorf("道具 %d: 未知的效果类型 %s", item.ID, item.Effect.Type)
+			}
+			if item.Effect.Value <= 0 {
+				return fmt.Errorf("道具 %d: 效果数值必须大于 0", item.ID)
+			}
+		}
+	}
+	return nil
+}
+
+// buildIndex 建立道具ID索引（不可堆叠道具的堆叠上限固定为 1）
+func (ic *ItemConfig) buildIndex() {
+	ic.byID = make(map[int]*ItemDef, len(ic.Items))
+	for i := range ic.Items {
+		item := &ic.Items[i]
+		if !item.Stackable {
+			item.MaxStack = 1
+		}
+		ic.byID[item.ID] = item
+	}
+}
@@ -0,0 +1,408 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"towerdefense/utils"
+)
+
+// Point 路径点坐标
+type Point struct {
+	X float32 `json:"x"`
+	Y float32 `json:"y"`
+	Z float32 `json:"z"`
+}
+
+// PathDef 敌人路径（第一个点为出生点，最后一个点为终点）
+// 多条路径可以有不同的出生点和终点；起点相同的路径即为分支
+type PathDef struct {
+	ID     string  `json:"id"`
+	Points []Point `json:"points"`
+}
+
+// PathWeight 敌人组选择路径的权重
+type PathWeight struct {
+	Path   string `json:"path"`
+	Weight int    `json:"weight"`
+}
+
+// EnemyGroup 波次中的一组敌人（同一类型按固定间隔依次生成）
+type EnemyGroup struct {
+	EnemyType     int          `json:"enemy_type"`
+	Count         int          `json:"count"`
+	SpawnInterval float32      `json:"spawn_interval"` // 生成间隔（秒）
+	Delay         float32      `json:"delay"`          // 波次开始后多久开始生成（秒）
+	Paths         []PathWeight `json:"paths"`          // 每个敌人按权重随机选择路径，为空时在关卡全部路径中等概率选择
+}
+
+// WaveDef 波次定义
+type WaveDef struct {
+	Delay  float32      `json:"delay"`  // 上一波结束（第一波为战斗开始）到本波开始的准备时间（秒）
+	Reward int          `json:"reward"` // 本波完成后每名玩家获得的金币
+	Groups []EnemyGroup `json:"groups"`
+}
+
+// TotalEnemies 本波敌人总数
+func (wd *WaveDef) TotalEnemies() int {
+	total := 0
+	for _, group := range wd.Groups {
+		total += group.Count
+	}
+	return total
+}
+
+// 网格地块类型（grid.rows 中的字符）
+const (
+	CellBuildable = '.' // 可建造
+	CellPath      = 'P' // 敌人路径（不可建造）
+	CellBlocked   = '#' // 障碍（不可建造、不可通行）
+	// '1'~'9' 为对应座位玩家的专属建造区
+)
+
+// GridDef 关卡地图网格（x 方向为列，z 方向为行）
+type GridDef struct {
+	CellSize float32  `json:"cell_size"`
+	Origin   Point    `json:"origin"` // 第 0 行第 0 列格子的角点（x、z 最小的一侧）
+	Rows     []string `json:"rows"`   // rows[i][j] 为第 i 行第 j 列的地块类型
+	Maze     bool     `json:"maze"`   // 迷宫模式：可以在路径上建塔，地面敌人在每条路径的起点和终点之间绕塔行进，但不能完全堵死任何一条
+}
+
+// Width 列数
+func (gd *GridDef) Width() int {
+	if len(gd.Rows) == 0 {
+		return 0
+	}
+	return len(gd.Rows[0])
+}
+
+// Height 行数
+func (gd *GridDef) Height() int {
+	return len(gd.Rows)
+}
+
+// CellOf 坐标所在的格子，超出网格时返回 false
+func (gd *GridDef) CellOf(x, z float32) (int, int, bool) {
+	col := int(math.Floor(float64((x - gd.Origin.X) / gd.CellSize)))
+	row := int(math.Floor(float64((z - gd.Origin.Z) / gd.CellSize)))
+	if col < 0 || row < 0 || col >= gd.Width() || row >= gd.Height() {
+		return 0, 0, false
+	}
+	return col, row, true
+}
+
+// CellCenter 格子中心坐标
+func (gd *GridDef) CellCenter(col, row int) Point {
+	return Point{
+		X: gd.Origin.X + (float32(col)+0.5)*gd.CellSize,
+		Y: gd.Origin.Y,
+		Z: gd.Origin.Z + (float32(row)+0.5)*gd.CellSize,
+	}
+}
+
+// CellType 格子的地块类型
+func (gd *GridDef) CellType(col, row int) byte {
+	return gd.Rows[row][col]
+}
+
+// validate 校验网格，路径点必须落在网格内的可通行格子上
+func (gd *GridDef) validate(path PathDef) error {
+	if gd.CellSize <= 0 {
+		return fmt.Errorf("grid.cell_size 必须大于 0")
+	}
+	if gd.Height() == 0 || gd.Width() == 0 {
+		return fmt.Errorf("grid.rows 不能为空")
+	}
+	for i, row := range gd.Rows {
+		if len(row) != gd.Width() {
+			return fmt.Errorf("grid 第 %d 行长度 %d 与第 0 行 %d 不一致", i, len(row), gd.Width())
+		}
+		for j := 0; j < len(row); j++ {
+			switch c := row[j]; {
+			case c == CellBuildable, c == CellPath, c == CellBlocked, c >= '1' && c <= '9':
+			default:
+				return fmt.Errorf("grid 第 %d 行第 %d 列: 未知的地块类型 %q", i, j, c)
+			}
+		}
+	}
+	for i, p := range path.Points {
+		col, row, ok := gd.CellOf(p.X, p.Z)
+		if !ok {
+			return fmt.Errorf("路径 %s 的第 %d 个点 (%.1f, %.1f) 超出网格", path.ID, i, p.X, p.Z)
+		}
+		if gd.CellType(col, row) == CellBlocked {
+			return fmt.Errorf("路径 %s 的第 %d 个点 (%.1f, %.1f) 位于障碍格子上", path.ID, i, p.X, p.Z)
+		}
+	}
+	if gd.Maze {
+		first, last := path.Points[0], path.Points[len(path.Points)-1]
+		startCol, startRow, _ := gd.CellOf(first.X, first.Z)
+		goalCol, goalRow, _ := gd.CellOf(last.X, last.Z)
+		if !gd.reachable(startCol, startRow, goalCol, goalRow) {
+			return fmt.Errorf("迷宫路径 %s 的起点被障碍隔断，无法到达终点", path.ID)
+		}
+	}
+	return nil
+}
+
+// reachable 不经过障碍格子能否按四方向从起点走到终点
+func (gd *GridDef) reachable(startCol, startRow, goalCol, goalRow int) bool {
+	type cell struct{ col, row int }
+	start, goal := cell{startCol, startRow}, cell{goalCol, goalRow}
+	seen := map[cell]bool{start: true}
+	queue := []cell{start}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if cur == goal {
+			return true
+		}
+		for _, d := range []cell{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+			next := cell{cur.col + d.col, cur.row + d.row}
+			if next.col < 0 || next.row < 0 || next.col >= gd.Width() || next.row >= gd.Height() {
+				continue
+			}
+			if seen[next] || gd.CellType(next.col, next.row) == CellBlocked {
+				continue
+			}
+			seen[next] = true
+			queue = append(queue, next)
+		}
+	}
+	return false
+}
+
+// LevelDef 关卡定义
+type LevelDef struct {
+	ID         int       `json:"id"`
+	Name       string    `json:"name"`
+	MapData    string    `json:"map_data"`   // 客户端地图资源
+	Difficulty int       `json:"difficulty"` // 难度等级，按敌人配置的 difficulty_scaling 提高敌人属性和击杀奖励
+	StartGold  int       `json:"start_gold"` // 初始金币，0 表示使用 game.initial_gold
+	StartLife  int       `json:"start_life"` // 初始生命，0 表示使用 game.initial_life
+	Paths      []PathDef `json:"paths"`      // 敌人路径，至少一条
+	Grid       GridDef   `json:"grid"`       // 建造网格
+	Waves      []WaveDef `json:"waves"`      // 全部波次完成即胜利
+}
+
+// TotalWaves 总波数
+func (ld *LevelDef) TotalWaves() int {
+	return len(ld.Waves)
+}
+
+// GetWave 获取第 waveNum 波（从 1 开始）
+func (ld *LevelDef) GetWave(waveNum int) (*WaveDef, bool) {
+	if waveNum < 1 || waveNum > len(ld.Waves) {
+		return nil, false
+	}
+	return &ld.Waves[waveNum-1], true
+}
+
+// GetPath 获取路径
+func (ld *LevelDef) GetPath(pathID string) (*PathDef, bool) {
+	for i := range ld.Paths {
+		if ld.Paths[i].ID == pathID {
+			return &ld.Paths[i], true
+		}
+	}
+	return nil, false
+}
+
+// InitialGold 初始金币
+func (ld *LevelDef) InitialGold() int {
+	if ld.StartGold > 0 {
+		return ld.StartGold
+	}
+	return Game.InitialGold
+}
+
+// InitialLife 初始生命
+func (ld *LevelDef) InitialLife() int {
+	if ld.StartLife > 0 {
+		return ld.StartLife
+	}
+	return Game.InitialLife
+}
+
+// LevelConfig 关卡配置（每个关卡一个文件）
+type LevelConfig struct {
+	byID map[int]*LevelDef
+}
+
+var Levels LevelConfig
+
+// GetLevel 获取关卡定义
+func (lc *LevelConfig) GetLevel(levelID int) (*LevelDef, bool) {
+	level, ok := lc.byID[levelID]
+	return level, ok
+}
+
+// LevelIDs 已配置的关卡ID（升序）
+func (lc *LevelConfig) LevelIDs() []int {
+	ids := make([]int, 0, len(lc.byID))
+	for id := range lc.byID {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// defaultLevel 默认关卡（目录不存在时使用）：五个路径点、十波，第十波为 Boss 波
+func defaultLevel() *LevelDef {
+	level := &LevelDef{
+		ID:         1,
+		Name:       "默认关卡",
+		Difficulty: 1,
+		Paths: []PathDef{{
+			ID: "main",
+			Points: []Point{
+				{X: 0, Y: 0, Z: 0},
+				{X: 10, Y: 0, Z: 0},
+				{X: 10, Y: 0, Z: 10},
+				{X: 20, Y: 0, Z: 10},
+				{X: 20, Y: 0, Z: 20},
+			},
+		}},
+		Grid: GridDef{
+			CellSize: 2,
+			Origin:   Point{X: -3, Y: 0, Z: -3},
+			Rows:     make([]string, 14),
+		},
+	}
+	for i := range level.Grid.Rows {
+		level.Grid.Rows[i] = strings.Repeat(string(CellBuildable), 14)
+	}
+
+	group := func(enemyType, count int) EnemyGroup {
+		return EnemyGroup{EnemyType: enemyType, Count: count, SpawnInterval: 1.0}
+	}
+	for waveNum := 1; waveNum <= 10; waveNum++ {
+		wave := WaveDef{Delay: 3}
+		switch {
+		case waveNum <= 3:
+			wave.Groups = []EnemyGroup{group(1, 5+waveNum*2)}
+			wave.Reward = 50 + waveNum*10
+		case waveNum <= 6:
+			wave.Groups = []EnemyGroup{group(1, 10), group(2, waveNum-2)}
+			wave.Reward = 100 + waveNum*20
+		case waveNum <= 9:
+			wave.Groups = []EnemyGroup{group(1, 15), group(2, waveNum)}
+			wave.Reward = 200 + waveNum*30
+		default:
+			wave.Groups = []EnemyGroup{group(1, 20), group(2, 10), group(3, 1)}
+			wave.Reward = 500
+		}
+		level.Waves = append(level.Waves, wave)
+	}
+	level.Waves[0].Delay = 0
+	return level
+}
+
+// LoadLevelConfig 加载关卡目录下的所有关卡文件（*.json），目录不存在时使用默认关卡
+// 会校验波次引用的敌人类型，需在 LoadEnemyConfig 之后调用
+func LoadLevelConfig(dir string) error {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		level := defaultLevel()
+		if err := level.validate(); err != nil {
+			return fmt.Errorf("默认关卡无效: %v", err)
+		}
+		Levels = LevelConfig{byID: map[int]*LevelDef{level.ID: level}}
+		utils.Info("未找到关卡目录 %s，使用默认关卡", dir)
+		return nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return fmt.Errorf("读取关卡目录失败: %v", err)
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("关卡目录 %s 中没有关卡文件", dir)
+	}
+
+	cfg := LevelConfig{byID: make(map[int]*LevelDef, len(files))}
+	for _, path := range files {
+		file, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("读取关卡配置 %s 失败: %v", path, err)
+		}
+		var level LevelDef
+		if err := json.Unmarshal(file, &level); err != nil {
+			return fmt.Errorf("关卡配置 %s 解析失败: %v", path, err)
+		}
+		if err := level.validate(); err != nil {
+			return fmt.Errorf("关卡配置 %s 无效: %v", path, err)
+		}
+		if _, ok := cfg.byID[level.ID]; ok {
+			return fmt.Errorf("关卡ID重复: %d (%s)", level.ID, path)
+		}
+		cfg.byID[level.ID] = &level
+	}
+
+	Levels = cfg
+	utils.Info("关卡配置加载成功: %s, 关卡: %v", dir, cfg.LevelIDs())
+	return nil
+}
+
+// validate 校验关卡
+func (ld *LevelDef) validate() error {
+	if ld.ID <= 0 {
+		return fmt.Errorf("关卡ID必须大于 0")
+	}
+	if ld.StartGold < 0 || ld.StartLife < 0 {
+		return fmt.Errorf("start_gold 和 start_life 不能为负数")
+	}
+	if len(ld.Paths) == 0 {
+		return fmt.Errorf("至少需要配置 1 条路径")
+	}
+	for i, path := range ld.Paths {
+		if path.ID == "" {
+			return fmt.Errorf("路径 %d: id 不能为空", i)
+		}
+		if found, _ := ld.GetPath(path.ID); found != &ld.Paths[i] {
+			return fmt.Errorf("路径ID重复: %s", path.ID)
+		}
+		if len(path.Points) < 2 {
+			return fmt.Errorf("路径 %s: 至少需要 2 个点", path.ID)
+		}
+		if err := ld.Grid.validate(path); err != nil {
+			return err
+		}
+	}
+	if len(ld.Waves) == 0 {
+		return fmt.Errorf("至少需要配置 1 波")
+	}
+
+	for i, wave := range ld.Waves {
+		waveNum := i + 1
+		if wave.Delay < 0 || wave.Reward < 0 {
+			return fmt.Errorf("第 %d 波: delay 和 reward 不能为负数", waveNum)
+		}
+		if len(wave.Groups) == 0 {
+			return fmt.Errorf("第 %d 波: 至少需要 1 组敌人", waveNum)
+		}
+		for _, group := range wave.Groups {
+			if _, ok := Enemies.GetEnemy(group.EnemyType); !ok {
+				return fmt.Errorf("第 %d 波: 未定义的敌人类型 %d", waveNum, group.EnemyType)
+			}
+			if group.Count <= 0 {
+				return fmt.Errorf("第 %d 波: 敌人 %d 的 count 必须大于 0", waveNum, group.EnemyType)
+			}
+			if group.SpawnInterval < 0 || group.Delay < 0 {
+				return fmt.Errorf("第 %d 波: 敌人 %d 的 spawn_interval 和 delay 不能为负数", waveNum, group.EnemyType)
+			}
+			for _, pw := range group.Paths {
+				if _, ok := ld.GetPath(pw.Path); !ok {
+					return fmt.Errorf("第 %d 波: 敌人 %d 引用了未定义的路径 %s", waveNum, group.EnemyType, pw.Path)
+				}
+				if pw.Weight <= 0 {
+					return fmt.Errorf("第 %d 波: 敌人 %d 的路径 %s 权重必须大于 0", waveNum, group.EnemyType, pw.Path)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"towerdefense/utils"
+)
+
+// 解锁内容类型
+const (
+	UnlockTypeTower = "tower" // 防御塔类型
+	UnlockTypeIcon  = "icon"  // 头像
+	UnlockTypeFrame = "frame" // 头像框
+	UnlockTypeLevel = "level" // 关卡
+)
+
+// PlayerUnlock 等级解锁内容
+type PlayerUnlock struct {
+	Type string `json:"type"` // tower, icon, frame, level
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// PlayerLevelEntry 单个等级的配置
+type PlayerLevelEntry struct {
+	Level   int            `json:"level"`
+	Exp     int            `json:"exp"`     // 升到下一级所需经验（满级忽略）
+	Unlocks []PlayerUnlock `json:"unlocks"` // 达到该等级时解锁的内容
+}
+
+// PlayerLevelConfig 玩家等级配置（经验表和等级解锁）
+type PlayerLevelConfig struct {
+	MaxLevel int                `json:"max_level"`
+	Levels   []PlayerLevelEntry `json:"levels"` // 按等级从 1 开始连续配置
+
+	unlockLevels map[string]map[int]int // 类型 -> ID -> 解锁等级
+}
+
+var PlayerLevel PlayerLevelConfig
+
+// ExpToNextLevel 升到下一级所需经验，满级返回 0
+func (pc *PlayerLevelConfig) ExpToNextLevel(level int) int {
+	if level < 1 || level >= pc.MaxLevel || level > len(pc.Levels) {
+		return 0
+	}
+	return pc.Levels[level-1].Exp
+}
+
+// GetUnlocks 获取达到某等级时解锁的内容
+func (pc *PlayerLevelConfig) GetUnlocks(level int) []PlayerUnlock {
+	if level < 1 || level > len(pc.Levels) {
+		return nil
+	}
+	return pc.Levels[level-1].Unlocks
+}
+
+// UnlockLevel 获取内容的解锁等级，未配置解锁条件的返回 false
+func (pc *PlayerLevelConfig) UnlockLevel(unlockType string, id int) (int, bool) {
+	level, ok := pc.unlockLevels[unlockType][id]
+	return level, ok
+}
+
+// defaultPlayerLevelConfig 默认等级配置（每级所需经验为 等级*100）
+func defaultPlayerLevelConfig() PlayerLevelConfig {
+	cfg := PlayerLevelConfig{
+		MaxLevel: 60,
+		Levels:   make([]PlayerLevelEntry, 0, 60),
+	}
+	for level := 1; level <= cfg.MaxLevel; level++ {
+		cfg.Levels = append(cfg.Levels, PlayerLevelEntry{Level: level, Exp: level * 100})
+	}
+	cfg.Levels[2].Unlocks = []PlayerUnlock{{Type: UnlockTypeTower, ID: 2, Name: "炮塔"}}
+	cfg.Levels[4].Unlocks = []PlayerUnlock{{Type: UnlockTypeIcon, ID: 2, Name: "骑士头像"}}
+	cfg.buildIndex()
+	return cfg
+}
+
+// LoadPlayerLevelConfig 加载玩家等级配置，文件不存在时使用默认配置
+func LoadPlayerLevelConfig(path string) error {
+	PlayerLevel = defaultPlayerLevelConfig()
+
+	file, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			utils.Info("未找到等级配置 %s，使用默认配置", path)
+			return nil
+		}
+		return fmt.Errorf("读取等级配置失败: %v", err)
+	}
+
+	var cfg PlayerLevelConfig
+	if err := json.Unmarshal(file, &cfg); err != nil {
+		return fmt.Errorf("等级配置解析失败: %v", err)
+	}
+	if err := cfg.validate(); err != nil {
+		return fmt.Errorf("等级配置无效: %v", err)
+	}
+	cfg.buildIndex()
+
+	PlayerLevel = cfg
+	utils.Info("等级配置加载成功: %s, 等级上限: %d", path, cfg.MaxLevel)
+	return nil
+}
+
+// validate 校验配置
+func (pc *PlayerLevelConfig) validate() error {
+	if pc.MaxLevel < 1 {
+		return fmt.Errorf("max_level 必须大于 0")
+	}
+	if len(pc.Levels) != pc.MaxLevel {
+		return fmt.Errorf("等级数量 %d 与 max_level %d 不一致", len(pc.Levels), pc.MaxLevel)
+	}
+
+	seen := make(map[string]bool)
+	for i, entry := range pc.Levels {
+		if entry.Level != i+1 {
+			return fmt.Errorf("第 %d 项的等级应为 %d", i+1, i+1)
+		}
+		if entry.Level < pc.MaxLevel && entry.Exp <= 0 {
+			return fmt.Errorf("等级 %d: exp 必须大于 0", entry.Level)
+		}
+		for _, unlock := range entry.Unlocks {
+			switch unlock.Type {
+			case UnlockTypeTower, UnlockTypeIcon, UnlockTypeFrame, UnlockTypeLevel:
+			default:
+				return fmt.Errorf("等级 %d: 未知的解锁类型 %s", entry.Level, unlock.Type)
+			}
+			key := fmt.Sprintf("%s_%d", unlock.Type, unlock.ID)
+			if seen[key] {
+				return fmt.Errorf("等级 %d: %s %d 重复配置解锁", entry.Level, unlock.Type, unlock.ID)
+			}
+			seen[key] = true
+		}
+	}
+	return nil
+}
+
+// buildIndex 建立解锁内容到解锁等级的索引
+func (pc *PlayerLevelConfig) buildIndex() {
+	pc.unlockLevels = make(map[string]map[int]int)
+	for _, entry := range pc.Levels {
+		for _, unlock := range entry.Unlocks {
+			if pc.unlockLevels[unlock.Type] == nil {
+				pc.unlockLevels[unlock.Type] = make(map[int]int)
+			}
+			pc.unlockLevels[unlock.Type][unlock.ID] = entry.Level
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"towerdefense/utils"
+)
+
+// LevelReward 关卡结算奖励参数
+type LevelReward struct {
+	BaseCoin      int     `json:"base_coin"`       // 参与奖励
+	BaseExp       int     `json:"base_exp"`
+	VictoryCoin   int     `json:"victory_coin"`    // 胜利额外奖励
+	VictoryExp    int     `json:"victory_exp"`
+	CoinPerWave   int     `json:"coin_per_wave"`   // 每通过一波
+	ExpPerWave    int     `json:"exp_per_wave"`
+	CoinPerKill   int     `json:"coin_per_kill"`   // 每个击杀
+	ScoreCoinRate float64 `json:"score_coin_rate"` // 个人得分折算金币比例
+	DefeatRate    float64 `json:"defeat_rate"`     // 失败时奖励倍率
+	DropTable     string  `json:"drop_table"`      // 掉落表名称
+}
+
+// DropEntry 掉落表条目（每个条目独立判定）
+type DropEntry struct {
+	ItemID      int     `json:"item_id"`
+	ItemName    string  `json:"item_name"`
+	Chance      float64 `json:"chance"` // 掉落概率 0~1
+	MinCount    int     `json:"min_count"`
+	MaxCount    int     `json:"max_count"`
+	VictoryOnly bool    `json:"victory_only"` // 仅胜利时掉落
+}
+
+// RewardConfig 战斗奖励配置
+type RewardConfig struct {
+	Default    LevelReward            `json:"default"` // 未单独配置的关卡使用
+	Levels     map[string]LevelReward `json:"levels"`  // 关卡ID -> 奖励参数
+	DropTables map[string][]DropEntry `json:"drop_tables"`
+}
+
+var Reward RewardConfig
+
+// GetLevelReward 获取关卡奖励参数
+func (rc *RewardConfig) GetLevelReward(levelID int) LevelReward {
+	if reward, ok := rc.Levels[strconv.Itoa(levelID)]; ok {
+		return reward
+	}
+	return rc.Default
+}
+
+// defaultRewardConfig 默认奖励配置
+func defaultRewardConfig() RewardConfig {
+	return RewardConfig{
+		Default: LevelReward{
+			BaseCoin:      20,
+			BaseExp:       10,
+			VictoryCoin:   100,
+			VictoryExp:    50,
+			CoinPerWave:   10,
+			ExpPerWave:    5,
+			CoinPerKill:   1,
+			ScoreCoinRate: 0.1,
+			DefeatRate:    0.5,
+			DropTable:     "normal",
+		},
+		Levels: map[string]LevelReward{},
+		DropTables: map[string][]DropEntry{
+			"normal": {
+				{ItemID: 1001, ItemName: "加速卷轴", Chance: 0.3, MinCount: 1, MaxCount: 1},
+				{ItemID: 1002, ItemName: "金币加成卡", Chance: 0.1, MinCount: 1, MaxCount: 1, VictoryOnly: true},
+			},
+		},
+	}
+}
+
+// LoadRewardConfig 加载战斗奖励配置，文件不存在时使用默认配置
+func LoadRewardConfig(path string) error {
+	Reward = defaultRewardConfig()
+
+	file, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			utils.Info("未找到奖励配置 %s，使用默认配置", path)
+			return nil
+		}
+		return fmt.Errorf("读取奖励配置失败: %v", err)
+	}
+
+	var cfg RewardConfig
+	if err := json.Unmarshal(file, &cfg); err != nil {
+		return fmt.Errorf("奖励配置解析失败: %v", err)
+	}
+	if err := cfg.validate(); err != nil {
+		return fmt.Errorf("奖励配置无效: %v", err)
+	}
+
+	Reward = cfg
+	utils.Info("奖励配置加载成功: %s", path)
+	return nil
+}
+
+// validate 校验配置
+func (rc *RewardConfig) validate() error {
+	check := func(name string, reward LevelReward) error {
+		if reward.DefeatRate < 0 || reward.DefeatRate > 1 {
+			return fmt.Errorf("%s: defeat_rate 必须在 0~1 之间", name)
+		}
+		if reward.DropTable != "" {
+			if _, ok := rc.DropTables[reward.DropTable]; !ok {
+				return fmt.Errorf("%s: 掉落表 %s 不存在", name, reward.DropTable)
+			}
+		}
+		return nil
+	}
+
+	if err := check("default", rc.Default); err != nil {
+		return err
+	}
+	for levelID, reward := range rc.Levels {
+		if _, err := strconv.Atoi(levelID); err != nil {
+			return fmt.Errorf("关卡ID必须为数字: %s", levelID)
+		}
+		if err := check("关卡 "+levelID, reward); err != nil {
+			return err
+		}
+	}
+	for name, entries := range rc.DropTables {
+		for _, entry := range entries {
+			if entry.Chance < 0 || entry.Chance > 1 {
+				return fmt.Errorf("掉落表 %s: 物品 %d 的 chance 必须在 0~1 之间", name, entry.ItemID)
+			}
+			if entry.MinCount <= 0 || entry.MaxCount < entry.MinCount {
+				return fmt.Errorf("掉落表 %s: 物品 %d 的数量范围无效", name, entry.ItemID)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"towerdefense/utils"
+)
+
+// SkillDef 玩家技能定义：消耗战斗金币，对指定位置半径内的敌人（含飞行单位）造成伤害和/或施加 Buff
+type SkillDef struct {
+	ID         int     `json:"id"`
+	Name       string  `json:"name"`
+	Cost       int     `json:"cost"`        // 释放消耗的战斗金币
+	Cooldown   float32 `json:"cooldown"`    // 冷却时间（秒，按游戏时间计算，暂停期间不冷却）
+	Radius     float32 `json:"radius"`      // 作用半径
+	Damage     int     `json:"damage"`      // 对范围内每个敌人的伤害，0 表示不造成伤害
+	DamageType string  `json:"damage_type"` // physical, magic, true，为空表示物理伤害
+	Buffs      []int   `json:"buffs"`       // 对范围内敌人施加的 Buff
+}
+
+// SkillConfig 技能配置
+type SkillConfig struct {
+	Skills []SkillDef `json:"skills"`
+
+	byID map[int]*SkillDef
+}
+
+var Skills SkillConfig
+
+// GetSkill 获取技能定义
+func (sc *SkillConfig) GetSkill(id int) (*SkillDef, bool) {
+	skill, ok := sc.byID[id]
+	return skill, ok
+}
+
+// defaultSkillConfig 默认技能配置
+func defaultSkillConfig() SkillConfig {
+	cfg := SkillConfig{
+		Skills: []SkillDef{
+			{ID: 1, Name: "火球术", Cost: 60, Cooldown: 20, Radius: 2, Damage: 120, DamageType: DamageTypeMagic},
+			{ID: 2, Name: "冰霜新星", Cost: 40, Cooldown: 30, Radius: 3, Buffs: []int{1, 3}},
+		},
+	}
+	cfg.buildIndex()
+	return cfg
+}
+
+// LoadSkillConfig 加载技能配置，文件不存在时使用默认配置
+// 技能会引用 Buff，需在 LoadBuffConfig 之后调用
+func LoadSkillConfig(path string) error {
+	var cfg SkillConfig
+	file, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		utils.Info("未找到技能配置 %s，使用默认配置", path)
+		cfg = defaultSkillConfig()
+	case err != nil:
+		return fmt.Errorf("读取技能配置失败: %v", err)
+	default:
+		if err := json.Unmarshal(file, &cfg); err != nil {
+			return fmt.Errorf("技能配置解析失败: %v", err)
+		}
+		cfg.buildIndex()
+		utils.Info("技能配置加载成功: %s, 技能数量: %d", path, len(cfg.Skills))
+	}
+
+	if err := cfg.validate(); err != nil {
+		return fmt.Errorf("技能配置无效: %v", err)
+	}
+	Skills = cfg
+	return nil
+}
+
+// validate 校验配置
+func (sc *SkillConfig) validate() error {
+	seen := make(map[int]bool)
+	for _, skill := range sc.Skills {
+		if skill.ID <= 0 {
+			return fmt.Errorf("技能 ID 必须大于 0: %s", skill.Name)
+		}
+		if seen[skill.ID] {
+			return fmt.Errorf("技能 ID 重复: %d", skill.ID)
+		}
+		seen[skill.ID] = true
+
+		if skill.Name == "" {
+			return fmt.Errorf("技能 %d: 名称不能为空", skill.ID)
+		}
+		if skill.Cost < 0 || skill.Cooldown < 0 {
+			return fmt.Errorf("技能 %d: cost 和 cooldown 不能为负数", skill.ID)
+		}
+		if skill.Radius <= 0 {
+			return fmt.Errorf("技能 %d: radius 必须大于 0", skill.ID)
+		}
+		if skill.Damage < 0 {
+			return fmt.Errorf("技能 %d: damage 不能为负数", skill.ID)
+		}
+		if skill.Damage == 0 && len(skill.Buffs) == 0 {
+			return fmt.Errorf("技能 %d: 至少需要配置 damage 或 buffs", skill.ID)
+		}
+		switch skill.DamageType {
+		case "", DamageTypePhysical, DamageTypeMagic, DamageTypeTrue:
+		default:
+			return fmt.Errorf("技能 %d: 未知的伤害类型 %s", skill.ID, skill.DamageType)
+		}
+		for _, id := range skill.Buffs {
+			buff, ok := Buffs.GetBuff(id)
+			if !ok || buff.TargetsTower() {
+				return fmt.Errorf("技能 %d: buffs 中的 %d 不是已配置的敌人 Buff", skill.ID, id)
+			}
+		}
+	}
+	return nil
+}
+
+// buildIndex 建立技能 ID 索引
+func (sc *SkillConfig) buildIndex() {
+	sc.byID = make(map[int]*SkillDef, len(sc.Skills))
+	for i := range sc.Skills {
+		sc.byID[sc.Skills[i].ID] = &sc.Skills[i]
+	}
+}
@@ -0,0 +1,236 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"towerdefense/utils"
+)
+
+// 伤害类型
+const (
+	DamageTypePhysical = "physical" // 物理伤害，受护甲减免
+	DamageTypeMagic    = "magic"    // 魔法伤害，受魔法抗性减免
+	DamageTypeTrue     = "true"     // 真实伤害，不受减免
+	DamageTypeSplash   = "splash"   // 溅射伤害，对目标周围 splash_radius 内的敌人造成物理伤害
+)
+
+// TowerLevelStats 防御塔某一等级的属性
+type TowerLevelStats struct {
+	Damage      int     `json:"damage"`
+	AttackSpeed float32 `json:"attack_speed"` // 攻击间隔（秒）
+	Range       float32 `json:"range"`
+	Cost        int     `json:"cost"` // 1 级为建造花费，其余为从上一级升到该级的花费
+}
+
+// TowerDef 防御塔定义
+type TowerDef struct {
+	Type           int               `json:"type"`
+	Name           string            `json:"name"`
+	UnlockLevel    int               `json:"unlock_level"`    // 玩家达到该等级后才能建造，0 或 1 表示默认解锁
+	SellRatio      float64           `json:"sell_ratio"`      // 出售时按累计投入金币返还的比例
+	AntiAir        bool              `json:"anti_air"`        // 能否攻击飞行单位
+	DamageType     string            `json:"damage_type"`     // physical, magic, true, splash，为空表示物理伤害
+	SplashRadius   float32           `json:"splash_radius"`   // 溅射半径（仅 splash）
+	CritChance     float64           `json:"crit_chance"`     // 暴击率 0~1
+	CritMultiplier float64           `json:"crit_multiplier"` // 暴击伤害倍率
+	OnHitBuffs     []int             `json:"on_hit_buffs"`    // 命中时施加给敌人的 Buff
+	AuraBuff       int               `json:"aura_buff"`       // 持续施加给光环半径内其他防御塔的 Buff，0 表示没有光环
+	AuraRadius     float32           `json:"aura_radius"`     // 光环半径
+	Levels         []TowerLevelStats `json:"levels"`          // 按等级从 1 开始配置，数量即为最高等级
+}
+
+// MaxLevel 最高等级
+func (td *TowerDef) MaxLevel() int {
+	return len(td.Levels)
+}
+
+// GetLevel 获取某一等级的属性
+func (td *TowerDef) GetLevel(level int) (TowerLevelStats, bool) {
+	if level < 1 || level > len(td.Levels) {
+		return TowerLevelStats{}, false
+	}
+	return td.Levels[level-1], true
+}
+
+// TowerConfig 防御塔配置
+type TowerConfig struct {
+	Towers []TowerDef `json:"towers"`
+
+	byType map[int]*TowerDef
+}
+
+var Towers TowerConfig
+
+// GetTower 获取防御塔定义
+func (tc *TowerConfig) GetTower(towerType int) (*TowerDef, bool) {
+	tower, ok := tc.byType[towerType]
+	return tower, ok
+}
+
+// defaultTowerConfig 默认防御塔配置（每级伤害 ×1.3、攻击间隔 ×0.9、射程 +0.5）
+func defaultTowerConfig() TowerConfig {
+	cfg := TowerConfig{
+		Towers: []TowerDef{
+			{Type: 1, Name: "箭塔", SellRatio: 0.5, AntiAir: true, DamageType: DamageTypePhysical, CritChance: 0.1, CritMultiplier: 2.0, OnHitBuffs: []int{4}, Levels: scaledTowerLevels(10, 1.0, 5.0, 50, 3)},
+			{Type: 2, Name: "炮塔", UnlockLevel: 3, SellRatio: 0.5, DamageType: DamageTypeSplash, SplashRadius: 1.5, OnHitBuffs: []int{3}, Levels: scaledTowerLevels(30, 2.0, 6.0, 100, 3)},
+			{Type: 3, Name: "魔法塔", SellRatio: 0.5, AntiAir: true, DamageType: DamageTypeMagic, CritChance: 0.05, CritMultiplier: 1.5, OnHitBuffs: []int{1, 2}, AuraBuff: 5, AuraRadius: 3, Levels: scaledTowerLevels(15, 0.8, 7.0, 80, 3)},
+		},
+	}
+	cfg.buildIndex()
+	return cfg
+}
+
+// scaledTowerLevels 按固定倍率生成各等级属性，升级花费为 建造花费*目标等级/2
+func scaledTowerLevels(damage int, attackSpeed, towerRange float32, cost, maxLevel int) []TowerLevelStats {
+	levels := []TowerLevelStats{{Damage: damage, AttackSpeed: attackSpeed, Range: towerRange, Cost: cost}}
+	for level := 2; level <= maxLevel; level++ {
+		prev := levels[len(levels)-1]
+		levels = append(levels, TowerLevelStats{
+			Damage:      int(float32(prev.Damage) * 1.3),
+			AttackSpeed: prev.AttackSpeed * 0.9,
+			Range:       prev.Range + 0.5,
+			Cost:        cost * level / 2,
+		})
+	}
+	return levels
+}
+
+// LoadTowerConfig 加载防御塔配置，文件不存在时使用默认配置
+// 会校验防御塔解锁等级与玩家等级配置是否一致、引用的 Buff 是否存在，需在 LoadPlayerLevelConfig 和 LoadBuffConfig 之后调用
+func LoadTowerConfig(path string) error {
+	var cfg TowerConfig
+	file, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		utils.Info("未找到防御塔配置 %s，使用默认配置", path)
+		cfg = defaultTowerConfig()
+	case err != nil:
+		return fmt.Errorf("读取防御塔配置失败: %v", err)
+	default:
+		if err := json.Unmarshal(file, &cfg); err != nil {
+			return fmt.Errorf("防御塔配置解析失败: %v", err)
+		}
+		if err := cfg.validate(); err != nil {
+			return fmt.Errorf("防御塔配置无效: %v", err)
+		}
+		cfg.buildIndex()
+		utils.Info("防御塔配置加载成功: %s, 防御塔数量: %d", path, len(cfg.Towers))
+	}
+
+	if err := cfg.checkUnlocks(); err != nil {
+		return err
+	}
+
+	Towers = cfg
+	return nil
+}
+
+// validate 校验配置
+func (tc *TowerConfig) validate() error {
+	if len(tc.Towers) == 0 {
+		return fmt.Errorf("至少需要配置一种防御塔")
+	}
+
+	seen := make(map[int]bool)
+	for _, tower := range tc.Towers {
+		if tower.Type <= 0 {
+			return fmt.Errorf("防御塔类型必须大于 0: %s", tower.Name)
+		}
+		if seen[tower.Type] {
+			return fmt.Errorf("防御塔类型重复: %d", tower.Type)
+		}
+		seen[tower.Type] = true
+
+		if tower.Name == "" {
+			return fmt.Errorf("防御塔 %d: 名称不能为空", tower.Type)
+		}
+		if tower.UnlockLevel < 0 {
+			return fmt.Errorf("防御塔 %d: unlock_level 不能为负数", tower.Type)
+		}
+		if tower.SellRatio < 0 || tower.SellRatio > 1 {
+			return fmt.Errorf("防御塔 %d: sell_ratio 必须在 0~1 之间", tower.Type)
+		}
+		switch tower.DamageType {
+		case "", DamageTypePhysical, DamageTypeMagic, DamageTypeTrue:
+		case DamageTypeSplash:
+			if tower.SplashRadius <= 0 {
+				return fmt.Errorf("防御塔 %d: 溅射伤害需要配置大于 0 的 splash_radius", tower.Type)
+			}
+		default:
+			return fmt.Errorf("防御塔 %d: 未知的伤害类型 %s", tower.Type, tower.DamageType)
+		}
+		if tower.CritChance < 0 || tower.CritChance > 1 {
+			return fmt.Errorf("防御塔 %d: crit_chance 必须在 0~1 之间", tower.Type)
+		}
+		if tower.CritChance > 0 && tower.CritMultiplier < 1 {
+			return fmt.Errorf("防御塔 %d: crit_multiplier 不能小于 1", tower.Type)
+		}
+		for _, id := range tower.OnHitBuffs {
+			buff, ok := Buffs.GetBuff(id)
+			if !ok || buff.TargetsTower() {
+				return fmt.Errorf("防御塔 %d: on_hit_buffs 中的 %d 不是已配置的敌人 Buff", tower.Type, id)
+			}
+		}
+		if tower.AuraBuff != 0 {
+			buff, ok := Buffs.GetBuff(tower.AuraBuff)
+			if !ok || !buff.TargetsTower() {
+				return fmt.Errorf("防御塔 %d: aura_buff %d 不是已配置的防御塔 Buff", tower.Type, tower.AuraBuff)
+			}
+			if tower.AuraRadius <= 0 {
+				return fmt.Errorf("防御塔 %d: 光环需要配置大于 0 的 aura_radius", tower.Type)
+			}
+		}
+		if len(tower.Levels) == 0 {
+			return fmt.Errorf("防御塔 %d: 至少需要配置 1 级属性", tower.Type)
+		}
+		for i, stats := range tower.Levels {
+			if stats.Damage < 0 {
+				return fmt.Errorf("防御塔 %d 等级 %d: damage 不能为负数", tower.Type, i+1)
+			}
+			if stats.AttackSpeed <= 0 || stats.Range <= 0 {
+				return fmt.Errorf("防御塔 %d 等级 %d: attack_speed 和 range 必须大于 0", tower.Type, i+1)
+			}
+			if stats.Cost <= 0 {
+				return fmt.Errorf("防御塔 %d 等级 %d: cost 必须大于 0", tower.Type, i+1)
+			}
+		}
+	}
+	return nil
+}
+
+// checkUnlocks 校验解锁等级与玩家等级配置一致，保证升级通知中的防御塔解锁与实际建造限制相同
+func (tc *TowerConfig) checkUnlocks() error {
+	for level := 1; level <= len(PlayerLevel.Levels); level++ {
+		for _, unlock := range PlayerLevel.GetUnlocks(level) {
+			if unlock.Type != UnlockTypeTower {
+				continue
+			}
+			tower, ok := tc.GetTower(unlock.ID)
+			if !ok {
+				return fmt.Errorf("玩家等级 %d 解锁了未定义的防御塔 %d", level, unlock.ID)
+			}
+			if tower.UnlockLevel != level {
+				return fmt.Errorf("防御塔 %d 的解锁等级 %d 与玩家等级配置中的 %d 不一致", tower.Type, tower.UnlockLevel, level)
+			}
+		}
+	}
+
+	for _, tower := range tc.Towers {
+		if tower.UnlockLevel <= 1 {
+			continue
+		}
+		if _, ok := PlayerLevel.UnlockLevel(UnlockTypeTower, tower.Type); !ok {
+			return fmt.Errorf("防御塔 %d 的解锁等级 %d 未在玩家等级配置中配置", tower.Type, tower.UnlockLevel)
+		}
+	}
+	return nil
+}
+
+// buildIndex 建立防御塔类型索引
+func (tc *TowerConfig) buildIndex() {
+	tc.byType = make(map[int]*TowerDef, len(tc.Towers))
+	for i := range tc.Towers {
+		tc.byType[tc.Towers[i].Type] = &tc.Towers[i]
+	}
+}
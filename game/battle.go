@@ -1,8 +1,13 @@
 package game
 
 import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
 	"sync"
 	"time"
+	"towerdefense/config"
 	"towerdefense/utils"
 	
 	"github.com/google/uuid"
@@ -18,11 +23,26 @@ const (
 	BattleStatusFinished  BattleStatus = "finished"
 )
 
+var (
+	ErrBattleFinished  = errors.New("战斗已结束")
+	ErrBattlePaused    = errors.New("战斗已暂停")
+	ErrBattleNotPaused = errors.New("战斗未暂停")
+)
+
+const (
+	defaultTickRate  = 20 // 未配置帧率时使用的逻辑帧率
+	maxCatchUpFrames = 5  // 一次唤醒最多追赶的帧数，超出时丢弃落后的时间，避免越追越慢
+)
+
 // Battle 战斗
+// 启动后战斗状态只在战斗自己的游戏循环协程中修改：玩家操作通过命令队列在帧开始时执行，
+// 发给客户端的消息在帧结束后统一发送。外部只应读取创建后不再变化的字段
 type Battle struct {
 	ID            string
 	RoomID        string
+	RoomName      string
 	LevelID       int
+	Level         *config.LevelDef
 	Status        BattleStatus
 	Players       map[string]*Player
 	Towers        map[string]*Tower
@@ -30,107 +50,250 @@ type Battle struct {
 	CurrentWave   *Wave
 	WaveNum       int
 	TotalWaves    int
-	GameTime      float32
-	LastTickTime  time.Time
-	Path          []Vector3
+	Frame         int64   // 已执行的逻辑帧数
+	TickRate      int     // 逻辑帧率（帧/秒）
+	GameTime      float32 // 游戏时间（秒），由帧数换算，不受服务器负载影响
+	Seed          int64   // 随机种子：相同的种子和操作得到相同的战斗结果
+	Paths         map[string][]Vector3 // 路径ID -> 地面敌人的行进路线
+	Grid          *Grid
 	IsVictory     bool
+	Stats         map[string]*PlayerBattleStats // 玩家ID -> 本场统计
+	StartTime     time.Time
+	EndTime       time.Time
+	rng           *rand.Rand         // 战斗内的随机数（暴击、路径选择等）
+	nextSeq       int64              // 防御塔、敌人和 Buff 的创建序号，用于生成ID和固定每帧的处理顺序
+	buffSync      BuffSyncNotify     // 本帧施加和移除的 Buff，帧末统一通知
+	commands      chan battleCommand // 待执行的玩家操作
+	skillReady    map[skillSlot]float32 // 玩家技能冷却结束的游戏时间
+	onFinished    func(*Battle)      // 结算完成后的回调（回收战斗、重置房间）
+	commandLog    []PlayerCommand    // 已执行的玩家操作（按执行顺序，结算时写入战斗记录）
+	outbox        []outboundEvent    // 本帧待发送的消息
 	ticker        *time.Ticker
 	stopChan      chan bool
-	mu            sync.RWMutex
+	stopOnce      sync.Once
+}
+
+// NewBattle 按关卡配置创建战斗，随机种子取当前时间
+func NewBattle(roomID string, level *config.LevelDef, players map[string]*Player) *Battle {
+	return NewBattleWithSeed(roomID, level, players, time.Now().UnixNano())
 }
 
-// NewBattle 创建战斗
-func NewBattle(roomID string, levelID int, players map[string]*Player) *Battle {
-	// 创建默认路径（实际应该从关卡配置加载）
-	path := []Vector3{
-		{X: 0, Y: 0, Z: 0},
-		{X: 10, Y: 0, Z: 0},
-		{X: 10, Y: 0, Z: 10},
-		{X: 20, Y: 0, Z: 10},
-		{X: 20, Y: 0, Z: 20},
+// NewBattleWithSeed 按关卡配置和指定的随机种子创建战斗（用于回放和复现）
+func NewBattleWithSeed(roomID string, level *config.LevelDef, players map[string]*Player, seed int64) *Battle {
+	paths := make(map[string][]Vector3, len(level.Paths))
+	for _, def := range level.Paths {
+		path := make([]Vector3, 0, len(def.Points))
+		for _, p := range def.Points {
+			path = append(path, Vector3{X: p.X, Y: p.Y, Z: p.Z})
+		}
+		paths[def.ID] = path
+	}
+	
+	tickRate := config.Server.TickRate
+	if tickRate <= 0 {
+		tickRate = defaultTickRate
+	}
+	
+	// 迷宫模式下地面敌人沿网格最短路线行进
+	grid := NewGrid(level)
+	if grid.IsMaze() {
+		for id := range paths {
+			if route := grid.Route(id); route != nil {
+				paths[id] = route
+			}
+		}
 	}
 	
 	return &Battle{
 		ID:           uuid.New().String(),
 		RoomID:       roomID,
-		LevelID:      levelID,
+		LevelID:      level.ID,
+		Level:        level,
 		Status:       BattleStatusPreparing,
 		Players:      players,
 		Towers:       make(map[string]*Tower),
 		Enemies:      make(map[string]*Enemy),
 		WaveNum:      0,
-		TotalWaves:   10,
+		TotalWaves:   level.TotalWaves(),
+		TickRate:     tickRate,
 		GameTime:     0,
-		Path:         path,
+		Seed:         seed,
+		Paths:        paths,
+		Grid:         grid,
+		Stats:        make(map[string]*PlayerBattleStats),
+		rng:          rand.New(rand.NewSource(seed)),
+		commands:     make(chan battleCommand, commandQueueSize),
+		skillReady:   make(map[skillSlot]float32),
 		stopChan:     make(chan bool),
 	}
 }
 
-// Start 开始战斗
+// Start 开始战斗并启动游戏循环
 func (b *Battle) Start() {
-	b.mu.Lock()
 	b.Status = BattleStatusRunning
-	b.LastTickTime = time.Now()
-	b.mu.Unlock()
+	b.StartTime = time.Now()
 	
-	// 开始第一波
+	// 开始第一波（游戏循环尚未启动，在当前协程中发送消息）
 	b.StartNextWave()
+	b.flushEvents()
 	
-	// 启动游戏循环（20帧/秒）
-	b.ticker = time.NewTicker(50 * time.Millisecond)
+	// 启动游戏循环（按配置的逻辑帧率）
+	b.ticker = time.NewTicker(b.frameDuration())
 	go b.GameLoop()
 	
-	utils.Info("战斗 %s 开始", b.ID)
+	utils.Info("战斗 %s 开始，帧率: %d，随机种子: %d", b.ID, b.TickRate, b.Seed)
 }
 
-// Stop 停止战斗
+// Stop 停止战斗（不结算），在下一帧开始时执行，战斗已结束时直接返回
 func (b *Battle) Stop() {
-	b.mu.Lock()
-	defer b.mu.Unlock()
-	
+	b.submit(func() error {
+		if b.Status == BattleStatusFinished {
+			return nil
+		}
+		b.Status = BattleStatusFinished
+		b.halt()
+		utils.Info("战斗 %s 结束", b.ID)
+		return nil
+	})
+}
+
+// SetFinishedCallback 设置结算完成后的回调，在结算协程中调用（只能在 Start 之前设置）
+func (b *Battle) SetFinishedCallback(fn func(*Battle)) {
+	b.onFinished = fn
+}
+
+// halt 停止定时器，通知游戏循环和等待中的操作退出
+func (b *Battle) halt() {
+	b.stopOnce.Do(func() {
+		if b.ticker != nil {
+			b.ticker.Stop()
+		}
+		close(b.stopChan)
+	})
+}
+
+// finish 结束战斗并结算
+// 同一场战斗只会结算一次：状态置为结束后，后续的结束路径直接返回
+func (b *Battle) finish(isVictory bool) {
 	if b.Status == BattleStatusFinished {
 		return
 	}
 	
+	b.IsVictory = isVictory
 	b.Status = BattleStatusFinished
-	if b.ticker != nil {
-		b.ticker.Stop()
-	}
-	close(b.stopChan)
+	b.EndTime = time.Now()
+	b.halt()
+	
+	// 结算（持久化、发放奖励）在独立协程中处理，避免在游戏循环中执行存储IO
+	// 奖励确定后再发送游戏结束通知，最后调用结算完成回调
+	broadcast := b.buildGameOver()
+	result := b.buildResult()
+	go func() {
+		var rewards map[string]*BattleReward
+		if globalResultHandler != nil {
+			rewards = globalResultHandler.OnBattleFinished(result)
+		}
+		b.sendGameOver(broadcast, rewards)
+		if b.onFinished != nil {
+			b.onFinished(b)
+		}
+	}()
 	
-	utils.Info("战斗 %s 结束", b.ID)
+	utils.Info("战斗 %s 结算完成，胜利: %v，波次: %d", b.ID, isVictory, b.WaveNum)
 }
 
-// GameLoop 游戏循环
+// buildResult 生成结算结果
+func (b *Battle) buildResult() *BattleResult {
+	stats, mvpPlayerID := b.collectStats()
+	
+	result := &BattleResult{
+		BattleID:   b.ID,
+		RoomID:     b.RoomID,
+		RoomName:   b.RoomName,
+		LevelID:    b.LevelID,
+		IsVictory:  b.IsVictory,
+		WaveNum:    b.waveReached(),
+		TotalWaves: b.TotalWaves,
+		StartTime:  b.StartTime,
+		EndTime:    b.EndTime,
+		MVPPlayerID: mvpPlayerID,
+		Seed:       b.Seed,
+		Commands:   b.commandLog,
+		Players:    make([]PlayerResult, 0, len(stats)),
+	}
+	for _, s := range stats {
+		result.Players = append(result.Players, PlayerResult{
+			PlayerID: s.PlayerID,
+			Kills:    s.KillCount,
+			Damage:   s.TotalDamage,
+			Score:    s.Score,
+		})
+	}
+	return result
+}
+
+// waveReached 到达的波次（胜利时 WaveNum 会超出总波次 1）
+func (b *Battle) waveReached() int {
+	if b.WaveNum > b.TotalWaves {
+		return b.TotalWaves
+	}
+	return b.WaveNum
+}
+
+// frameDuration 每个逻辑帧的时长
+func (b *Battle) frameDuration() time.Duration {
+	return time.Second / time.Duration(b.TickRate)
+}
+
+// GameLoop 游戏循环：按实际经过的时间执行固定时长的逻辑帧
+// 定时器唤醒延迟时一次补齐落后的帧，落后超过 maxCatchUpFrames 帧时丢弃多出的时间
 func (b *Battle) GameLoop() {
+	step := b.frameDuration()
+	last := time.Now()
+	var pending time.Duration
+	
 	for {
 		select {
-		case <-b.ticker.C:
-			b.Tick()
+		case now := <-b.ticker.C:
+			pending += now.Sub(last)
+			last = now
+			
+			frames := 0
+			for pending >= step && frames < maxCatchUpFrames {
+				b.Tick()
+				pending -= step
+				frames++
+			}
+			if pending >= step {
+				utils.Warn("战斗 %s 逻辑帧落后过多，丢弃 %d 帧", b.ID, int(pending/step))
+				pending = 0
+			}
 		case <-b.stopChan:
 			return
 		}
 	}
 }
 
-// Tick 游戏帧更新
+// Tick 执行一个逻辑帧：先执行排队的玩家操作，再推进模拟，最后发送本帧产生的消息
+// 战斗不在进行中时只执行玩家操作，不推进游戏时间
 func (b *Battle) Tick() {
-	b.mu.Lock()
+	defer b.flushEvents()
+	b.applyCommands()
+	
 	if b.Status != BattleStatusRunning {
-		b.mu.Unlock()
 		return
 	}
 	
-	// 计算deltaTime
-	now := time.Now()
-	deltaTime := float32(now.Sub(b.LastTickTime).Seconds())
-	b.LastTickTime = now
-	b.GameTime += deltaTime
-	b.mu.Unlock()
+	b.Frame++
+	b.GameTime = float32(b.Frame) / float32(b.TickRate)
+	deltaTime := 1 / float32(b.TickRate)
 	
 	// 生成敌人
 	b.SpawnEnemies()
 	
+	// 结算 Buff（光环、持续伤害、到期移除）
+	b.UpdateBuffs(deltaTime)
+	
 	// 移动敌人
 	b.MoveEnemies(deltaTime)
 	
@@ -140,27 +303,32 @@ func (b *Battle) Tick() {
 	// 检查波次完成
 	b.CheckWaveComplete()
 	
+	// 同步本帧 Buff 变化
+	if b.Status == BattleStatusRunning {
+		b.SyncBuffs()
+	}
+	
 	// 定期同步状态
-	if int(b.GameTime*10)%10 == 0 { // 每1秒同步一次
+	if b.Status == BattleStatusRunning && b.Frame%int64(b.TickRate) == 0 { // 每1秒同步一次
 		b.SyncState()
 	}
 }
 
 // StartNextWave 开始下一波
 func (b *Battle) StartNextWave() {
-	b.mu.Lock()
-	defer b.mu.Unlock()
+	if b.Status == BattleStatusFinished {
+		return
+	}
 	
 	b.WaveNum++
 	if b.WaveNum > b.TotalWaves {
 		// 游戏胜利
-		b.IsVictory = true
-		b.Status = BattleStatusFinished
-		b.BroadcastGameOver()
+		b.finish(true)
 		return
 	}
 	
-	b.CurrentWave = NewWave(b.WaveNum)
+	waveDef, _ := b.Level.GetWave(b.WaveNum)
+	b.CurrentWave = NewWave(b.WaveNum, waveDef, b.GameTime)
 	utils.Info("波次 %d 开始", b.WaveNum)
 	
 	// 广播波次开始
@@ -169,40 +337,80 @@ func (b *Battle) StartNextWave() {
 
 // SpawnEnemies 生成敌人
 func (b *Battle) SpawnEnemies() {
-	b.mu.Lock()
-	defer b.mu.Unlock()
-	
 	if b.CurrentWave == nil {
 		return
 	}
 	
-	if b.CurrentWave.CanSpawn(b.GameTime) {
-		if enemyType, ok := b.CurrentWave.GetNextEnemy(b.GameTime); ok {
-			enemy := NewEnemy(uuid.New().String(), enemyType, b.Path)
-			b.Enemies[enemy.ID] = enemy
+	for {
+		group, ok := b.CurrentWave.GetNextEnemy(b.GameTime)
+		if !ok {
+			return
 		}
+		pathID := b.pickPath(group)
+		enemy, err := NewEnemy(fmt.Sprintf("enemy_%d", b.nextSeq+1), group.EnemyType, b.WaveNum, b.Level.Difficulty, b.Paths[pathID])
+		if err != nil {
+			utils.Error("战斗 %s 生成敌人失败: %v", b.ID, err)
+			continue
+		}
+		enemy.PathID = pathID
+		b.nextSeq++
+		enemy.seq = b.nextSeq
+		b.Enemies[enemy.ID] = enemy
 	}
 }
 
-// MoveEnemies 移动敌人
-func (b *Battle) MoveEnemies(deltaTime float32) {
-	b.mu.RLock()
+// pickPath 按敌人组配置的权重随机选择路径，未配置时在关卡全部路径中等概率选择
+func (b *Battle) pickPath(group config.EnemyGroup) string {
+	if len(group.Paths) == 0 {
+		return b.Level.Paths[b.rng.Intn(len(b.Level.Paths))].ID
+	}
+	
+	total := 0
+	for _, pw := range group.Paths {
+		total += pw.Weight
+	}
+	roll := b.rng.Intn(total)
+	for _, pw := range group.Paths {
+		if roll < pw.Weight {
+			return pw.Path
+		}
+		roll -= pw.Weight
+	}
+	return group.Paths[len(group.Paths)-1].Path
+}
+
+// sortedEnemies 按创建顺序返回敌人，保证每帧的处理顺序固定
+func (b *Battle) sortedEnemies() []*Enemy {
 	enemies := make([]*Enemy, 0, len(b.Enemies))
 	for _, e := range b.Enemies {
 		enemies = append(enemies, e)
 	}
-	b.mu.RUnlock()
-	
-	for _, enemy := range enemies {
-		if !enemy.IsEnemyAlive() {
+	sort.Slice(enemies, func(i, j int) bool { return enemies[i].seq < enemies[j].seq })
+	return enemies
+}
+
+// sortedTowers 按建造顺序返回防御塔
+func (b *Battle) sortedTowers() []*Tower {
+	towers := make([]*Tower, 0, len(b.Towers))
+	for _, t := range b.Towers {
+		towers = append(towers, t)
+	}
+	sort.Slice(towers, func(i, j int) bool { return towers[i].seq < towers[j].seq })
+	return towers
+}
+
+// MoveEnemies 移动敌人
+func (b *Battle) MoveEnemies(deltaTime float32) {
+	for _, enemy := range b.sortedEnemies() {
+		if b.Status == BattleStatusFinished {
+			return
+		}
+		if !enemy.IsAlive {
 			continue
 		}
 		
-		// 移动
-		reachedEnd := enemy.Move(deltaTime)
-		
-		// 到达终点
-		if reachedEnd {
+		// 移动，到达终点
+		if enemy.Move(deltaTime) {
 			b.EnemyReachEnd(enemy)
 		}
 	}
@@ -210,178 +418,345 @@ func (b *Battle) MoveEnemies(deltaTime float32) {
 
 // EnemyReachEnd 敌人到达终点
 func (b *Battle) EnemyReachEnd(enemy *Enemy) {
-	b.mu.Lock()
-	defer b.mu.Unlock()
+	// 移除敌人
+	delete(b.Enemies, enemy.ID)
+	enemy.IsAlive = false
+	
+	if b.Status == BattleStatusFinished {
+		return
+	}
 	
 	// 减少所有玩家生命值
+	defeated := false
 	for _, player := range b.Players {
 		player.ReduceLife(enemy.Damage)
 		
 		// 检查游戏失败
 		if player.GetLife() <= 0 {
-			b.IsVictory = false
-			b.Status = BattleStatusFinished
-			b.BroadcastGameOver()
+			defeated = true
 		}
 	}
 	
-	// 移除敌人
-	delete(b.Enemies, enemy.ID)
-	enemy.IsAlive = false
+	// 任一玩家生命归零即失败（多名玩家同时归零也只结算一次）
+	if defeated {
+		b.finish(false)
+	}
 }
 
 // TowerAttack 塔攻击
 func (b *Battle) TowerAttack() {
-	b.mu.RLock()
-	towers := make([]*Tower, 0, len(b.Towers))
-	enemies := make([]*Enemy, 0, len(b.Enemies))
-	for _, t := range b.Towers {
-		towers = append(towers, t)
-	}
-	for _, e := range b.Enemies {
-		enemies = append(enemies, e)
-	}
-	gameTime := b.GameTime
-	b.mu.RUnlock()
+	enemies := b.sortedEnemies()
 	
-	for _, tower := range towers {
+	for _, tower := range b.sortedTowers() {
+		if b.Status == BattleStatusFinished {
+			return
+		}
+		
 		// 寻找目标
 		tower.FindTarget(enemies)
 		
 		// 攻击
-		if tower.CanAttack(gameTime) {
-			damage, isCrit := tower.Attack(gameTime)
+		if !tower.CanAttack(b.GameTime) {
+			continue
+		}
+		damage, isCrit := tower.Attack(b.GameTime, b.rng)
+		for _, target := range tower.Victims(enemies) {
+			dealt, isKill := target.TakeDamage(damage, tower.DamageType)
+			b.recordHit(tower, dealt, isKill)
+			
+			// 广播伤害
+			b.BroadcastDamage(tower.ID, target.ID, dealt, tower.DamageType, isCrit, isKill)
 			
-			if tower.Target != nil {
-				isKill := tower.Target.TakeDamage(damage)
-				
-				// 广播伤害
-				b.BroadcastDamage(tower.ID, tower.Target.ID, damage, isCrit, isKill)
-				
-				// 击杀奖励
-				if isKill {
-					if player := b.GetPlayer(tower.OwnerID); player != nil {
-						player.AddGold(tower.Target.Gold)
-						player.AddKill(1)
-					}
-					
-					// 移除敌人
-					b.mu.Lock()
-					delete(b.Enemies, tower.Target.ID)
-					b.mu.Unlock()
-				}
+			if isKill {
+				b.KillEnemy(target, tower.OwnerID)
+				continue
+			}
+			
+			// 命中附加 Buff
+			for _, def := range tower.OnHitBuffs() {
+				bf, _ := b.applyBuff(&target.Buffs, def, target.ID, tower.ID, tower.OwnerID)
+				b.buffApplied(bf)
 			}
 		}
 	}
 }
 
+// KillEnemy 发放击杀奖励给玩家并移除敌人
+func (b *Battle) KillEnemy(enemy *Enemy, playerID string) {
+	if player := b.Players[playerID]; player != nil {
+		player.AddGold(enemy.Gold)
+		player.AddKill(1)
+		b.playerStats(player.ID).GoldEarned += enemy.Gold
+	}
+	delete(b.Enemies, enemy.ID)
+}
+
 // CheckWaveComplete 检查波次完成
 func (b *Battle) CheckWaveComplete() {
-	b.mu.RLock()
 	currentWave := b.CurrentWave
-	enemyCount := len(b.Enemies)
-	b.mu.RUnlock()
-	
-	if currentWave == nil || currentWave.IsWaveComplete() {
+	if b.Status == BattleStatusFinished || currentWave == nil || currentWave.IsWaveComplete() {
 		return
 	}
+	if spawned, total := currentWave.GetProgress(); spawned < total || len(b.Enemies) > 0 {
+		return
+	}
+	
+	// 波次完成
+	currentWave.Complete()
 	
-	spawned, total := currentWave.GetProgress()
-	if spawned >= total && enemyCount == 0 {
-		// 波次完成
-		currentWave.Complete()
+	// 发放奖励
+	for _, player := range b.Players {
+		player.AddGold(currentWave.Reward)
+		b.playerStats(player.ID).GoldEarned += currentWave.Reward
+	}
+	
+	// 广播波次完成
+	b.BroadcastWaveComplete()
+	
+	// 开始下一波（准备时间由波次配置的 delay 决定）
+	b.StartNextWave()
+}
+
+// PlaceTower 放置塔，返回塔的状态和剩余金币（在下一帧开始时执行）
+func (b *Battle) PlaceTower(playerID string, towerType int, pos Vector3) (TowerState, int, error) {
+	var state TowerState
+	var gold int
+	cmd := &PlayerCommand{PlayerID: playerID, Op: CommandPlaceTower, TowerType: towerType, PosX: pos.X, PosY: pos.Y, PosZ: pos.Z}
+	err := b.submitCommand(cmd, func() error {
+		player := b.Players[playerID]
+		if player == nil {
+			return ErrNotInBattle
+		}
+		if b.Status == BattleStatusFinished {
+			return ErrBattleFinished
+		}
 		
-		// 发放奖励
-		b.mu.Lock()
-		for _, player := range b.Players {
-			player.AddGold(currentWave.Reward)
+		// 对齐到网格并检查能否建造
+		cell, snapped, err := b.Grid.Snap(pos)
+		if err != nil {
+			return err
+		}
+		if err := b.Grid.CanBuild(cell, player.Lane); err != nil {
+			return err
 		}
-		b.mu.Unlock()
 		
-		// 广播波次完成
-		b.BroadcastWaveComplete()
+		// 创建塔（ID 由创建序号生成，相同的种子和操作得到相同的ID，操作日志可以回放）
+		tower, err := NewTower(fmt.Sprintf("tower_%d", b.nextSeq+1), towerType, playerID, snapped)
+		if err != nil {
+			return err
+		}
+		tower.Cell = cell
 		
-		// 延迟开始下一波
-		time.AfterFunc(3*time.Second, func() {
-			b.StartNextWave()
-		})
-	}
+		// 检查金币
+		if !player.SpendGold(tower.Cost) {
+			return ErrNotEnoughGold
+		}
+		
+		b.nextSeq++
+		tower.seq = b.nextSeq
+		b.Towers[tower.ID] = tower
+		b.Grid.Occupy(cell, tower.ID)
+		b.updateRoute()
+		cmd.TowerID = tower.ID
+		
+		stats := b.playerStats(playerID)
+		stats.TowersBuilt++
+		stats.GoldSpent += tower.Cost
+		
+		state, gold = tower.State(), player.GetGold()
+		return nil
+	})
+	return state, gold, err
 }
 
-// PlaceTower 放置塔
-func (b *Battle) PlaceTower(playerID string, towerType int, pos Vector3) (*Tower, bool) {
-	b.mu.Lock()
-	defer b.mu.Unlock()
-	
-	player := b.Players[playerID]
-	if player == nil {
-		return nil, false
+// UpgradeTower 升级塔，返回塔的状态、花费和剩余金币（在下一帧开始时执行）
+func (b *Battle) UpgradeTower(playerID, towerID string) (TowerState, int, int, error) {
+	var state TowerState
+	var cost, gold int
+	cmd := &PlayerCommand{PlayerID: playerID, Op: CommandUpgradeTower, TowerID: towerID}
+	err := b.submitCommand(cmd, func() error {
+		player := b.Players[playerID]
+		if player == nil {
+			return ErrNotInBattle
+		}
+		if b.Status == BattleStatusFinished {
+			return ErrBattleFinished
+		}
+		tower := b.Towers[towerID]
+		if tower == nil || tower.OwnerID != playerID {
+			return ErrTowerNotFound
+		}
+		
+		var err error
+		if cost, err = tower.UpgradeCost(); err != nil {
+			return err
+		}
+		if !player.SpendGold(cost) {
+			return ErrNotEnoughGold
+		}
+		tower.Upgrade()
+		
+		stats := b.playerStats(playerID)
+		stats.Upgrades++
+		stats.GoldSpent += cost
+		
+		state, gold = tower.State(), player.GetGold()
+		return nil
+	})
+	return state, cost, gold, err
+}
+
+// SellTower 出售塔，返回返还的金币和剩余金币（在下一帧开始时执行）
+func (b *Battle) SellTower(playerID, towerID string) (int, int, error) {
+	var refund, gold int
+	cmd := &PlayerCommand{PlayerID: playerID, Op: CommandSellTower, TowerID: towerID}
+	err := b.submitCommand(cmd, func() error {
+		player := b.Players[playerID]
+		if player == nil {
+			return ErrNotInBattle
+		}
+		if b.Status == BattleStatusFinished {
+			return ErrBattleFinished
+		}
+		tower := b.Towers[towerID]
+		if tower == nil || tower.OwnerID != playerID {
+			return ErrTowerNotFound
+		}
+		
+		refund = tower.SellValue
+		delete(b.Towers, towerID)
+		b.Grid.Release(tower.Cell)
+		b.updateRoute()
+		player.AddGold(refund)
+		
+		stats := b.playerStats(playerID)
+		stats.Sells++
+		stats.GoldEarned += refund
+		
+		gold = player.GetGold()
+		return nil
+	})
+	return refund, gold, err
+}
+
+// SetTargetMode 设置玩家防御塔的目标选择模式，返回塔的状态（在下一帧开始时执行）
+func (b *Battle) SetTargetMode(playerID, towerID string, mode TargetMode) (TowerState, error) {
+	var state TowerState
+	cmd := &PlayerCommand{PlayerID: playerID, Op: CommandSetTargetMode, TowerID: towerID, TargetMode: int(mode)}
+	err := b.submitCommand(cmd, func() error {
+		if b.Players[playerID] == nil {
+			return ErrNotInBattle
+		}
+		if b.Status == BattleStatusFinished {
+			return ErrBattleFinished
+		}
+		tower := b.Towers[towerID]
+		if tower == nil || tower.OwnerID != playerID {
+			return ErrTowerNotFound
+		}
+		
+		tower.TargetMode = mode
+		state = tower.State()
+		return nil
+	})
+	return state, err
+}
+
+// SetPaused 暂停或继续战斗（在下一帧开始时执行），任一参战玩家都可以操作
+// 暂停期间游戏时间不推进，敌人、防御塔、Buff 和技能冷却都停止结算，并通知全部玩家
+func (b *Battle) SetPaused(playerID string, paused bool) error {
+	op := CommandResume
+	if paused {
+		op = CommandPause
+	}
+	cmd := &PlayerCommand{PlayerID: playerID, Op: op}
+	return b.submitCommand(cmd, func() error {
+		if b.Players[playerID] == nil {
+			return ErrNotInBattle
+		}
+		switch {
+		case b.Status == BattleStatusFinished:
+			return ErrBattleFinished
+		case paused && b.Status == BattleStatusPaused:
+			return ErrBattlePaused
+		case !paused && b.Status != BattleStatusPaused:
+			return ErrBattleNotPaused
+		}
+		
+		if paused {
+			b.Status = BattleStatusPaused
+		} else {
+			b.Status = BattleStatusRunning
+		}
+		b.emitAll(MsgTypeBattlePauseNtf, BattlePauseBroadcast{PlayerID: playerID, Paused: paused})
+		return nil
+	})
+}
+
+// updateRoute 迷宫模式下建造或出售防御塔后重新计算路线并通知客户端
+// 之后生成的敌人走新路线，场上的地面敌人从所在格子改走到终点的最短路线（被围住时保持原路线）
+func (b *Battle) updateRoute() {
+	if !b.Grid.IsMaze() {
+		return
 	}
-	
-	// 创建塔
-	tower := NewTower(uuid.New().String(), towerType, playerID, pos)
-	
-	// 检查金币
-	if !player.SpendGold(tower.Cost) {
-		return nil, false
+	for id := range b.Paths {
+		if route := b.Grid.Route(id); route != nil {
+			b.Paths[id] = route
+		}
 	}
-	
-	b.Towers[tower.ID] = tower
-	return tower, true
+	for _, enemy := range b.Enemies {
+		if !enemy.IsAlive || enemy.Flying {
+			continue
+		}
+		if route := b.Grid.RouteFrom(enemy.PathID, enemy.Position); route != nil {
+			enemy.setPath(route)
+		}
+	}
+	b.emitAll(MsgTypePathUpdateNtf, PathUpdateBroadcast{Paths: b.routes()})
+}
+
+// routes 各路径当前的行进路线（按关卡配置的路径顺序）
+func (b *Battle) routes() []PathRoute {
+	routes := make([]PathRoute, 0, len(b.Level.Paths))
+	for _, def := range b.Level.Paths {
+		routes = append(routes, PathRoute{PathID: def.ID, Points: b.Paths[def.ID]})
+	}
+	return routes
 }
 
-// GetPlayer 获取玩家
-func (b *Battle) GetPlayer(playerID string) *Player {
-	b.mu.RLock()
-	defer b.mu.RUnlock()
-	return b.Players[playerID]
+// Routes 各路径开局时的行进路线，用于开局数据（只能在 Start 之前调用，此时游戏循环尚未启动）
+func (b *Battle) Routes() []PathRoute {
+	return b.routes()
 }
 
 // SyncState 同步状态
 func (b *Battle) SyncState() {
-	b.mu.RLock()
-	defer b.mu.RUnlock()
-	
-	if globalBroadcaster == nil {
-		return
-	}
-	
 	// 收集敌人状态
 	enemies := make([]EnemyState, 0, len(b.Enemies))
-	for _, e := range b.Enemies {
-		if !e.IsEnemyAlive() {
+	for _, e := range b.sortedEnemies() {
+		if !e.IsAlive {
 			continue
 		}
-		hp, maxHP := e.GetHP()
-		pos := e.GetPosition()
 		enemies = append(enemies, EnemyState{
-			EnemyID: e.ID,
-			Type:    e.Type,
-			HP:      hp,
-			MaxHP:   maxHP,
-			PosX:    pos.X,
-			PosY:    pos.Y,
-			PosZ:    pos.Z,
-			Speed:   e.Speed,
+			EnemyID:  e.ID,
+			Type:     e.Type,
+			HP:       e.HP,
+			MaxHP:    e.MaxHP,
+			PosX:     e.Position.X,
+			PosY:     e.Position.Y,
+			PosZ:     e.Position.Z,
+			Speed:    e.Speed,
+			VisualID: e.VisualID,
+			Flying:   e.Flying,
+			Debuffs:  e.Buffs.ids(),
+			Progress: e.Progress(),
 		})
 	}
 	
 	// 收集塔状态
 	towers := make([]TowerState, 0, len(b.Towers))
-	for _, t := range b.Towers {
-		targetID := ""
-		if t.Target != nil {
-			targetID = t.Target.ID
-		}
-		towers = append(towers, TowerState{
-			TowerID:  t.ID,
-			Type:     t.Type,
-			Level:    t.Level,
-			PosX:     t.Position.X,
-			PosY:     t.Position.Y,
-			PosZ:     t.Position.Z,
-			TargetID: targetID,
-		})
+	for _, t := range b.sortedTowers() {
+		towers = append(towers, t.State())
 	}
 	
 	// 发送给每个玩家
@@ -394,82 +769,77 @@ func (b *Battle) SyncState() {
 			Towers:  towers,
 		}
 		
-		globalBroadcaster.BroadcastToPlayer(player.ID, MsgTypeSyncStateNtf, sync)
+		b.emit(player.ID, MsgTypeSyncStateNtf, sync)
 	}
 }
 
 // BroadcastWaveStart 广播波次开始
 func (b *Battle) BroadcastWaveStart() {
-	if globalBroadcaster == nil {
-		return
-	}
-	
 	broadcast := WaveStartBroadcast{
 		WaveNum: b.WaveNum,
 	}
 	
-	for _, player := range b.Players {
-		globalBroadcaster.BroadcastToPlayer(player.ID, MsgTypeWaveStartRsp, broadcast)
-	}
+	b.emitAll(MsgTypeWaveStartRsp, broadcast)
 }
 
 // BroadcastWaveComplete 广播波次完成
 func (b *Battle) BroadcastWaveComplete() {
-	if globalBroadcaster == nil {
-		return
-	}
-	
 	broadcast := WaveCompleteBroadcast{
 		WaveNum: b.WaveNum,
 		Reward:  b.CurrentWave.Reward,
 	}
 	
-	for _, player := range b.Players {
-		globalBroadcaster.BroadcastToPlayer(player.ID, MsgTypeWaveCompleteNtf, broadcast)
-	}
+	b.emitAll(MsgTypeWaveCompleteNtf, broadcast)
 }
 
 // BroadcastDamage 广播伤害
-func (b *Battle) BroadcastDamage(towerID, enemyID string, damage int, isCrit, isKill bool) {
-	if globalBroadcaster == nil {
-		return
-	}
-	
+func (b *Battle) BroadcastDamage(towerID, enemyID string, damage int, damageType DamageType, isCrit, isKill bool) {
 	broadcast := SyncDamageBroadcast{
 		TowerID: towerID,
 		EnemyID: enemyID,
 		Damage:  damage,
+		DamageType: damageType,
 		IsCrit:  isCrit,
 		IsKill:  isKill,
 	}
 	
-	for _, player := range b.Players {
-		globalBroadcaster.BroadcastToPlayer(player.ID, MsgTypeSyncDamageNtf, broadcast)
-	}
+	b.emitAll(MsgTypeSyncDamageNtf, broadcast)
 }
 
-// BroadcastGameOver 广播游戏结束
-func (b *Battle) BroadcastGameOver() {
-	if globalBroadcaster == nil {
-		return
-	}
+// buildGameOver 生成游戏结束通知
+func (b *Battle) buildGameOver() GameOverBroadcast {
+	stats, mvpPlayerID := b.collectStats()
 	
 	totalKills := 0
 	totalDamage := int64(0)
-	
-	for _, player := range b.Players {
-		totalKills += player.KillCount
+	for _, s := range stats {
+		totalKills += s.KillCount
+		totalDamage += s.TotalDamage
 	}
 	
+	waveNum := b.waveReached()
 	broadcast := GameOverBroadcast{
 		IsVictory:   b.IsVictory,
-		TotalWaves:  b.WaveNum,
+		TotalWaves:  waveNum,
 		KillCount:   totalKills,
 		TotalDamage: totalDamage,
-		Score:       totalKills*10 + b.WaveNum*100,
+		Score:       totalKills*10 + waveNum*100,
+		PlayerStats: stats,
+		MVPPlayerID: mvpPlayerID,
+	}
+	return broadcast
+}
+
+// sendGameOver 发送游戏结束通知，每个玩家收到自己的奖励
+// 在结算协程中调用，只使用已生成的通知数据，不读取战斗状态
+func (b *Battle) sendGameOver(broadcast GameOverBroadcast, rewards map[string]*BattleReward) {
+	if globalBroadcaster == nil {
+		return
 	}
 	
-	for _, player := range b.Players {
-		globalBroadcaster.BroadcastToPlayer(player.ID, MsgTypeGameOverNtf, broadcast)
+	for _, s := range broadcast.PlayerStats {
+		msg := broadcast
+		msg.Reward = rewards[s.PlayerID]
+		globalBroadcaster.BroadcastToPlayer(s.PlayerID, MsgTypeGameOverNtf, msg)
 	}
 }
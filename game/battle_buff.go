@@ -0,0 +1,101 @@
+package game
+
+import (
+	"fmt"
+	"towerdefense/config"
+)
+
+// UpdateBuffs 结算 Buff：刷新防御塔光环，结算敌人的持续伤害，移除到期的 Buff
+// 敌人死亡或到达终点、防御塔出售时身上的 Buff 随之消失，不单独通知
+func (b *Battle) UpdateBuffs(deltaTime float32) {
+	towers := b.sortedTowers()
+	b.applyAuras(towers)
+
+	for _, enemy := range b.sortedEnemies() {
+		if b.Status == BattleStatusFinished {
+			return
+		}
+		if !enemy.IsAlive {
+			continue
+		}
+		expired := enemy.Buffs.update(deltaTime, func(bf *Buff) {
+			b.buffTick(enemy, bf)
+		})
+		b.buffsExpired(expired)
+	}
+
+	for _, tower := range towers {
+		b.buffsExpired(tower.Buffs.update(deltaTime, nil))
+	}
+}
+
+// applyAuras 光环塔每帧给半径内的其他防御塔施加（刷新）光环 Buff，只在新施加时通知
+// 光环塔出售后，附近防御塔的光环 Buff 在持续时间结束后自然到期
+func (b *Battle) applyAuras(towers []*Tower) {
+	for _, source := range towers {
+		def, radius := source.Aura()
+		if def == nil {
+			continue
+		}
+		for _, tower := range towers {
+			if tower == source || source.Position.Distance(tower.Position) > radius {
+				continue
+			}
+			if bf, isNew := b.applyBuff(&tower.Buffs, def, tower.ID, source.ID, source.OwnerID); isNew {
+				b.buffApplied(bf)
+			}
+		}
+	}
+}
+
+// applyBuff 按叠加规则施加 Buff，返回受影响的实例以及是否为新实例
+// 新实例的ID由创建序号生成，相同的种子和操作得到相同的ID
+func (b *Battle) applyBuff(set *buffSet, def *config.BuffDef, targetID, sourceID, ownerID string) (*Buff, bool) {
+	bf, isNew := set.apply(def, fmt.Sprintf("buff_%d", b.nextSeq+1), targetID, sourceID, ownerID)
+	if isNew {
+		b.nextSeq++
+	}
+	return bf, isNew
+}
+
+// buffTick 结算一次持续伤害（真实伤害），击杀奖励归施加者所属玩家
+func (b *Battle) buffTick(enemy *Enemy, bf *Buff) {
+	if !enemy.IsAlive {
+		return
+	}
+
+	dealt, isKill := enemy.TakeDamage(int(bf.def.Value*float64(bf.Stacks)), DamageTypeTrue)
+	if tower := b.Towers[bf.SourceID]; tower != nil {
+		b.recordHit(tower, dealt, isKill)
+	} else if b.Players[bf.OwnerID] != nil {
+		// 施加的防御塔已出售或由技能施加，只计入玩家统计
+		b.recordPlayerHit(bf.OwnerID, dealt, isKill)
+	}
+	b.BroadcastDamage(bf.SourceID, enemy.ID, dealt, DamageTypeTrue, false, isKill)
+
+	if isKill {
+		b.KillEnemy(enemy, bf.OwnerID)
+	}
+}
+
+// buffApplied 记录本帧施加或刷新的 Buff
+func (b *Battle) buffApplied(bf *Buff) {
+	b.buffSync.Buffs = append(b.buffSync.Buffs, bf.State())
+}
+
+// buffsExpired 记录本帧到期移除的 Buff
+func (b *Battle) buffsExpired(expired []*Buff) {
+	for _, bf := range expired {
+		b.buffSync.RemovedBuffIDs = append(b.buffSync.RemovedBuffIDs, bf.ID)
+	}
+}
+
+// SyncBuffs 通知本帧的 Buff 变化，没有变化时不发送
+func (b *Battle) SyncBuffs() {
+	if len(b.buffSync.Buffs) == 0 && len(b.buffSync.RemovedBuffIDs) == 0 {
+		return
+	}
+
+	b.emitAll(MsgTypeSyncBuffNtf, b.buffSync)
+	b.buffSync = BuffSyncNotify{}
+}
@@ -0,0 +1,117 @@
+package game
+
+// commandQueueSize 每场战斗排队等待执行的玩家操作上限，队列满时提交方阻塞等待
+const commandQueueSize = 256
+
+// battleCommand 玩家操作，由游戏循环在帧开始时执行
+type battleCommand struct {
+	apply func() error
+	done  chan error
+}
+
+// 玩家操作类型
+const (
+	CommandPlaceTower    = "place_tower"
+	CommandUpgradeTower  = "upgrade_tower"
+	CommandSellTower     = "sell_tower"
+	CommandSetTargetMode = "set_target_mode"
+	CommandCastSkill     = "cast_skill"
+	CommandPause         = "pause"
+	CommandResume        = "resume"
+)
+
+// PlayerCommand 已执行的玩家操作，与随机种子一起保存在战斗记录中，用于回放和复现
+type PlayerCommand struct {
+	Frame      int64   `json:"frame"` // 执行时已完成的逻辑帧数（操作在下一帧模拟之前生效）
+	PlayerID   string  `json:"player_id"`
+	Op         string  `json:"op"`
+	TowerID    string  `json:"tower_id,omitempty"`
+	TowerType  int     `json:"tower_type,omitempty"`
+	PosX       float32 `json:"pos_x,omitempty"`
+	PosY       float32 `json:"pos_y,omitempty"`
+	PosZ       float32 `json:"pos_z,omitempty"`
+	TargetMode int     `json:"target_mode,omitempty"`
+	SkillID    int     `json:"skill_id,omitempty"`
+}
+
+// outboundEvent 帧内产生、帧结束后发送给玩家的消息
+type outboundEvent struct {
+	playerID string
+	msgType  int
+	data     interface{}
+}
+
+// submit 把操作放入命令队列并等待游戏循环执行，返回操作的结果
+// 战斗已结束（或在操作执行前结束）时返回 ErrBattleFinished
+func (b *Battle) submit(apply func() error) error {
+	cmd := battleCommand{apply: apply, done: make(chan error, 1)}
+
+	select {
+	case b.commands <- cmd:
+	case <-b.stopChan:
+		return ErrBattleFinished
+	}
+
+	select {
+	case err := <-cmd.done:
+		return err
+	case <-b.stopChan:
+		// 结束前的最后一帧可能已经执行了该操作
+		select {
+		case err := <-cmd.done:
+			return err
+		default:
+			return ErrBattleFinished
+		}
+	}
+}
+
+// submitCommand 提交玩家操作，执行成功时连同当前帧号记入操作日志
+// apply 可以补充记录中执行后才确定的字段（如新建防御塔的ID）
+func (b *Battle) submitCommand(cmd *PlayerCommand, apply func() error) error {
+	return b.submit(func() error {
+		if err := apply(); err != nil {
+			return err
+		}
+		cmd.Frame = b.Frame
+		b.commandLog = append(b.commandLog, *cmd)
+		return nil
+	})
+}
+
+// applyCommands 执行队列中已有的全部操作（只在游戏循环中调用）
+func (b *Battle) applyCommands() {
+	for {
+		select {
+		case cmd := <-b.commands:
+			cmd.done <- cmd.apply()
+		default:
+			return
+		}
+	}
+}
+
+// emit 记录发给玩家的消息，帧结束后发送
+func (b *Battle) emit(playerID string, msgType int, data interface{}) {
+	b.outbox = append(b.outbox, outboundEvent{playerID: playerID, msgType: msgType, data: data})
+}
+
+// emitAll 记录发给战斗内全部玩家的消息
+func (b *Battle) emitAll(msgType int, data interface{}) {
+	for _, player := range b.Players {
+		b.emit(player.ID, msgType, data)
+	}
+}
+
+// flushEvents 发送本帧产生的消息
+func (b *Battle) flushEvents() {
+	events := b.outbox
+	b.outbox = nil
+	if globalBroadcaster == nil {
+		return
+	}
+
+	for _, e := range events {
+		globalBroadcaster.BroadcastToPlayer(e.playerID, e.msgType, e.data)
+	}
+}
@@ -0,0 +1,76 @@
+package game
+
+import (
+	"time"
+)
+
+// BattleResult 战斗结算结果（战斗结束时生成一次）
+type BattleResult struct {
+	BattleID    string
+	RoomID      string
+	RoomName    string
+	LevelID     int
+	IsVictory   bool
+	WaveNum     int // 到达的波次
+	TotalWaves  int
+	StartTime   time.Time
+	EndTime     time.Time
+	MVPPlayerID string
+	Players     []PlayerResult  // 按个人得分降序
+	Seed        int64           // 随机种子
+	Commands    []PlayerCommand // 已执行的玩家操作，与随机种子一起用于回放
+}
+
+// PlayerResult 单个玩家的战斗结果
+type PlayerResult struct {
+	PlayerID string
+	Kills    int
+	Damage   int64
+	Score    int
+}
+
+// TotalKills 全部玩家击杀数
+func (r *BattleResult) TotalKills() int {
+	total := 0
+	for _, p := range r.Players {
+		total += p.Kills
+	}
+	return total
+}
+
+// Duration 战斗时长（秒）
+func (r *BattleResult) Duration() int {
+	return int(r.EndTime.Sub(r.StartTime).Seconds())
+}
+
+// BattleReward 玩家战斗奖励（随游戏结束通知下发）
+type BattleReward struct {
+	Coin  int          `json:"coin"`
+	Exp   int          `json:"exp"`
+	Items []ItemReward `json:"items"`
+}
+
+// ItemReward 道具奖励
+type ItemReward struct {
+	ItemID    int    `json:"item_id"`
+	ItemCount int    `json:"item_count"`
+	ItemName  string `json:"item_name"`
+}
+
+// BattleResultHandler 战斗结算处理器接口（由逻辑层实现，负责持久化和发放奖励）
+// 返回各玩家的奖励（玩家ID -> 奖励），游戏结束通知在结算完成后发送
+type BattleResultHandler interface {
+	OnBattleFinished(result *BattleResult) map[string]*BattleReward
+}
+
+var globalResultHandler BattleResultHandler
+
+// SetBattleResultHandler 设置战斗结算处理器
+func SetBattleResultHandler(handler BattleResultHandler) {
+	globalResultHandler = handler
+}
+
+// GetBattleResultHandler 获取战斗结算处理器
+func GetBattleResultHandler() BattleResultHandler {
+	return globalResultHandler
+}
@@ -0,0 +1,102 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"towerdefense/config"
+)
+
+var (
+	ErrUnknownSkill  = errors.New("技能不存在")
+	ErrSkillCooldown = errors.New("技能冷却中")
+	ErrSkillTarget   = errors.New("技能目标不存在")
+)
+
+// skillSlot 技能冷却的键：每个玩家的每个技能单独冷却
+type skillSlot struct {
+	playerID string
+	skillID  int
+}
+
+// CastSkill 释放技能，返回剩余金币（在下一帧开始时执行）
+// targetID 不为空时以该敌人执行时的位置为中心，否则以 pos 为中心，操作日志记录实际的中心位置
+// 技能对半径内存活的敌人（含飞行单位）造成伤害并施加 Buff，伤害和击杀计入释放者的统计
+// 暂停期间不能释放技能
+func (b *Battle) CastSkill(playerID string, skillID int, targetID string, pos Vector3) (int, error) {
+	var gold int
+	cmd := &PlayerCommand{PlayerID: playerID, Op: CommandCastSkill, SkillID: skillID}
+	err := b.submitCommand(cmd, func() error {
+		player := b.Players[playerID]
+		if player == nil {
+			return ErrNotInBattle
+		}
+		switch b.Status {
+		case BattleStatusFinished:
+			return ErrBattleFinished
+		case BattleStatusPaused:
+			return ErrBattlePaused
+		}
+		def, ok := config.Skills.GetSkill(skillID)
+		if !ok {
+			return ErrUnknownSkill
+		}
+		slot := skillSlot{playerID: playerID, skillID: skillID}
+		if b.GameTime < b.skillReady[slot] {
+			return ErrSkillCooldown
+		}
+		if targetID != "" {
+			target := b.Enemies[targetID]
+			if target == nil || !target.IsAlive {
+				return ErrSkillTarget
+			}
+			pos = target.Position
+		}
+		if !player.SpendGold(def.Cost) {
+			return ErrNotEnoughGold
+		}
+		cmd.PosX, cmd.PosY, cmd.PosZ = pos.X, pos.Y, pos.Z
+
+		b.skillReady[slot] = b.GameTime + def.Cooldown
+		b.playerStats(playerID).GoldSpent += def.Cost
+		b.emitAll(MsgTypeSkillCastNtf, SkillCastBroadcast{PlayerID: playerID, SkillID: skillID, Position: pos})
+		b.applySkill(def, playerID, pos)
+
+		gold = player.GetGold()
+		return nil
+	})
+	return gold, err
+}
+
+// applySkill 结算技能效果，伤害通知和 Buff 的来源为 skill_<技能ID>
+func (b *Battle) applySkill(def *config.SkillDef, playerID string, pos Vector3) {
+	sourceID := fmt.Sprintf("skill_%d", def.ID)
+	damageType := parseDamageType(def.DamageType)
+
+	for _, target := range b.sortedEnemies() {
+		if b.Status == BattleStatusFinished {
+			return
+		}
+		if !target.IsAlive || target.Position.Distance(pos) > def.Radius {
+			continue
+		}
+
+		if def.Damage > 0 {
+			dealt, isKill := target.TakeDamage(def.Damage, damageType)
+			b.recordPlayerHit(playerID, dealt, isKill)
+			b.BroadcastDamage(sourceID, target.ID, dealt, damageType, false, isKill)
+			if isKill {
+				b.KillEnemy(target, playerID)
+				continue
+			}
+		}
+
+		for _, buffID := range def.Buffs {
+			buffDef, ok := config.Buffs.GetBuff(buffID)
+			if !ok {
+				continue
+			}
+			bf, _ := b.applyBuff(&target.Buffs, buffDef, target.ID, sourceID, playerID)
+			b.buffApplied(bf)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package game
+
+// BattleStartHandler 战斗开始处理器接口（由逻辑层实现，负责结算道具等带入战斗的加成）
+// 返回各玩家额外获得的初始金币（玩家ID -> 金币）
+type BattleStartHandler interface {
+	OnBattleStart(battleID string, playerIDs []string) map[string]int
+}
+
+var globalStartHandler BattleStartHandler
+
+// SetBattleStartHandler 设置战斗开始处理器
+func SetBattleStartHandler(handler BattleStartHandler) {
+	globalStartHandler = handler
+}
+
+// GetBattleStartHandler 获取战斗开始处理器
+func GetBattleStartHandler() BattleStartHandler {
+	return globalStartHandler
+}
@@ -0,0 +1,87 @@
+package game
+
+import (
+	"sort"
+)
+
+// PlayerBattleStats 玩家单场战斗统计
+type PlayerBattleStats struct {
+	PlayerID    string
+	Kills       int
+	Damage      int64
+	TowersBuilt int
+	Upgrades    int
+	Sells       int
+	GoldEarned  int // 击杀、波次奖励、出售返还
+	GoldSpent   int // 建造、升级
+}
+
+// Score 个人得分：击杀每个 10 分，每 100 点伤害 1 分，每建造一座塔 5 分
+func (s *PlayerBattleStats) Score() int {
+	return s.Kills*10 + int(s.Damage/100) + s.TowersBuilt*5
+}
+
+// playerStats 获取玩家统计
+func (b *Battle) playerStats(playerID string) *PlayerBattleStats {
+	stats, ok := b.Stats[playerID]
+	if !ok {
+		stats = &PlayerBattleStats{PlayerID: playerID}
+		b.Stats[playerID] = stats
+	}
+	return stats
+}
+
+// recordHit 记录塔造成的伤害与击杀
+func (b *Battle) recordHit(tower *Tower, damage int, isKill bool) {
+	tower.RecordHit(damage, isKill)
+	b.recordPlayerHit(tower.OwnerID, damage, isKill)
+}
+
+// recordPlayerHit 只计入玩家统计的伤害与击杀（技能、已出售防御塔留下的持续伤害）
+func (b *Battle) recordPlayerHit(playerID string, damage int, isKill bool) {
+	stats := b.playerStats(playerID)
+	stats.Damage += int64(damage)
+	if isKill {
+		stats.Kills++
+	}
+}
+
+// collectStats 汇总各玩家统计并评选 MVP
+// MVP 为个人得分最高者，得分相同时比较伤害，再相同按玩家ID排序
+func (b *Battle) collectStats() ([]PlayerGameStats, string) {
+	result := make([]PlayerGameStats, 0, len(b.Players))
+	for _, player := range b.Players {
+		stats := b.Stats[player.ID]
+		if stats == nil {
+			stats = &PlayerBattleStats{PlayerID: player.ID}
+		}
+		result = append(result, PlayerGameStats{
+			PlayerID:     player.ID,
+			PlayerName:   player.Name,
+			KillCount:    stats.Kills,
+			TotalDamage:  stats.Damage,
+			TowerCount:   stats.TowersBuilt,
+			UpgradeCount: stats.Upgrades,
+			SellCount:    stats.Sells,
+			GoldEarned:   stats.GoldEarned,
+			GoldSpent:    stats.GoldSpent,
+			Score:        stats.Score(),
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Score != result[j].Score {
+			return result[i].Score > result[j].Score
+		}
+		if result[i].TotalDamage != result[j].TotalDamage {
+			return result[i].TotalDamage > result[j].TotalDamage
+		}
+		return result[i].PlayerID < result[j].PlayerID
+	})
+
+	mvpPlayerID := ""
+	if len(result) > 0 {
+		mvpPlayerID = result[0].PlayerID
+	}
+	return result, mvpPlayerID
+}
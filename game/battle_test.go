@@ -0,0 +1,259 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"testing"
+	"towerdefense/config"
+)
+
+// maxTestFrames 测试战斗最多执行的逻辑帧数（默认帧率下约 10 分钟）
+const maxTestFrames = 12000
+
+// battleOutcome 一场战斗中与随机种子和玩家操作相关的全部结果
+type battleOutcome struct {
+	Frame     int64
+	Status    BattleStatus
+	IsVictory bool
+	WaveNum   int
+	Gold      int
+	Life      int
+	Players   []PlayerResult
+	Commands  []PlayerCommand
+	Errors    []string
+}
+
+func loadBattleTestConfig(t *testing.T) {
+	t.Helper()
+	loaders := []func() error{
+		func() error { return config.LoadPlayerLevelConfig("../player_level_config.json") },
+		func() error { return config.LoadBuffConfig("../buff_config.json") },
+		func() error { return config.LoadSkillConfig("../skill_config.json") },
+		func() error { return config.LoadTowerConfig("../tower_config.json") },
+		func() error { return config.LoadEnemyConfig("../enemy_config.json") },
+		func() error { return config.LoadLevelConfig("../level_config") },
+	}
+	for _, load := range loaders {
+		if err := load(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// newScriptedBattle 创建不启动游戏循环的战斗，由测试逐帧调用 Tick
+func newScriptedBattle(t *testing.T, seed int64) *Battle {
+	t.Helper()
+	level, ok := config.Levels.GetLevel(1)
+	if !ok {
+		t.Fatal("缺少关卡 1")
+	}
+	player := NewPlayer("p1", "玩家1", "s1")
+	player.Lane = 1
+	player.InitGameData(level.InitialGold(), level.InitialLife())
+
+	b := NewBattleWithSeed("room", level, map[string]*Player{player.ID: player}, seed)
+	b.Status = BattleStatusRunning
+	b.StartNextWave()
+	return b
+}
+
+// tickWithCommands 按顺序提交玩家操作并执行一帧，操作在帧开始时执行（与游戏循环中的时机相同）
+func tickWithCommands(b *Battle, ops []func() error) []error {
+	done := make([]chan error, len(ops))
+	for i, op := range ops {
+		done[i] = make(chan error, 1)
+		go func(op func() error, done chan error) { done <- op() }(op, done[i])
+		for len(b.commands) <= i {
+			runtime.Gosched()
+		}
+	}
+	b.Tick()
+
+	errs := make([]error, len(ops))
+	for i := range done {
+		errs[i] = <-done[i]
+	}
+	return errs
+}
+
+// runScriptedBattle 按固定的帧号执行同一组玩家操作，直到战斗结束
+// 防御塔ID由创建序号生成，脚本和回放操作日志一样直接引用
+func runScriptedBattle(t *testing.T, seed int64) battleOutcome {
+	b := newScriptedBattle(t, seed)
+	place := func(towerType int, x, z float32) func() error {
+		return func() error {
+			_, _, err := b.PlaceTower("p1", towerType, Vector3{X: x, Z: z})
+			return err
+		}
+	}
+	script := []struct {
+		frame int64
+		op    func() error
+	}{
+		{0, place(1, 8, 5)},
+		{0, place(1, 12, 7)},
+		{200, func() error {
+			_, err := b.SetTargetMode("p1", "tower_1", TargetWeakest)
+			return err
+		}},
+		{600, func() error {
+			_, _, _, err := b.UpgradeTower("p1", "tower_2")
+			return err
+		}},
+		{700, func() error {
+			_, err := b.CastSkill("p1", 2, "", Vector3{X: 10, Z: 6})
+			return err
+		}},
+		{900, place(3, 8, 11)},
+		{1500, func() error {
+			_, _, err := b.SellTower("p1", "tower_1")
+			return err
+		}},
+		{1500, place(1, 18, 7)},
+	}
+
+	var outcome battleOutcome
+	next := 0
+	for b.Status == BattleStatusRunning && b.Frame < maxTestFrames {
+		var ops []func() error
+		for ; next < len(script) && script[next].frame == b.Frame; next++ {
+			ops = append(ops, script[next].op)
+		}
+		if len(ops) == 0 {
+			b.Tick()
+			continue
+		}
+		for _, err := range tickWithCommands(b, ops) {
+			if err != nil {
+				outcome.Errors = append(outcome.Errors, err.Error())
+			}
+		}
+	}
+
+	result := b.buildResult()
+	player := b.Players["p1"]
+	outcome.Frame = b.Frame
+	outcome.Status = b.Status
+	outcome.IsVictory = result.IsVictory
+	outcome.WaveNum = result.WaveNum
+	outcome.Gold = player.GetGold()
+	outcome.Life = player.GetLife()
+	outcome.Players = result.Players
+	outcome.Commands = result.Commands
+	return outcome
+}
+
+// 相同的随机种子和相同帧执行的相同操作应得到完全相同的战斗结果
+func TestBattleIsDeterministicForSameSeedAndCommands(t *testing.T) {
+	loadBattleTestConfig(t)
+
+	first := runScriptedBattle(t, 42)
+	second := runScriptedBattle(t, 42)
+
+	if first.Status != BattleStatusFinished {
+		t.Fatalf("战斗应在 %d 帧内结束, 状态: %s", maxTestFrames, first.Status)
+	}
+	if len(first.Players) == 0 || first.Players[0].Kills == 0 {
+		t.Fatalf("脚本中的防御塔应击杀敌人: %+v", first)
+	}
+	if len(first.Commands) == 0 || first.Commands[0].Frame != 0 {
+		t.Fatalf("操作日志应记录执行时的帧号: %+v", first.Commands)
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Fatalf("相同种子和操作的两场战斗结果不同:\n%+v\n%+v", first, second)
+	}
+}
+
+// 暂停期间不推进游戏时间、不能释放技能；技能扣除金币、伤害计入释放者并进入冷却
+func TestPauseAndCastSkill(t *testing.T) {
+	loadBattleTestConfig(t)
+	b := newScriptedBattle(t, 7)
+	for len(b.Enemies) == 0 && b.Frame < maxTestFrames {
+		b.Tick()
+	}
+	target := b.sortedEnemies()[0]
+
+	pause := func(paused bool) func() error {
+		return func() error { return b.SetPaused("p1", paused) }
+	}
+	cast := func() error {
+		_, err := b.CastSkill("p1", 1, target.ID, Vector3{})
+		return err
+	}
+
+	if errs := tickWithCommands(b, []func() error{pause(true), pause(true)}); errs[0] != nil || !errors.Is(errs[1], ErrBattlePaused) {
+		t.Fatalf("暂停结果错误: %v", errs)
+	}
+	frame := b.Frame
+	if errs := tickWithCommands(b, []func() error{cast}); !errors.Is(errs[0], ErrBattlePaused) {
+		t.Fatalf("暂停期间释放技能应失败: %v", errs)
+	}
+	if b.Status != BattleStatusPaused || b.Frame != frame {
+		t.Fatalf("暂停期间不应推进: 状态 %s, 帧 %d -> %d", b.Status, frame, b.Frame)
+	}
+
+	errs := tickWithCommands(b, []func() error{pause(false), pause(false), cast, cast})
+	if errs[0] != nil || !errors.Is(errs[1], ErrBattleNotPaused) || errs[2] != nil || !errors.Is(errs[3], ErrSkillCooldown) {
+		t.Fatalf("继续和释放技能结果错误: %v", errs)
+	}
+	if b.Status != BattleStatusRunning || b.Frame != frame+1 {
+		t.Fatalf("继续后应推进一帧: 状态 %s, 帧 %d", b.Status, b.Frame)
+	}
+	skill, _ := config.Skills.GetSkill(1)
+	if stats := b.Stats["p1"]; stats == nil || stats.GoldSpent != skill.Cost || stats.Damage == 0 {
+		t.Fatalf("技能应扣除 %d 金币并把伤害计入玩家统计: %+v", skill.Cost, stats)
+	}
+
+	var ops []string
+	for _, c := range b.commandLog {
+		ops = append(ops, c.Op)
+	}
+	if want := []string{CommandPause, CommandResume, CommandCastSkill}; !reflect.DeepEqual(ops, want) {
+		t.Fatalf("操作日志应只记录成功的操作: %v", ops)
+	}
+}
+
+// Buff 实例ID由创建序号生成；叠层 Buff 不超过最大层数，到期后移除并通知；
+// 施加的防御塔已出售时，持续伤害的击杀仍计入施加者所属玩家
+func TestBuffStackExpiryAndDotKillCredit(t *testing.T) {
+	loadBattleTestConfig(t)
+	b := newScriptedBattle(t, 7)
+	for len(b.Enemies) == 0 && b.Frame < maxTestFrames {
+		b.Tick()
+	}
+	enemy := b.sortedEnemies()[0]
+	b.buffSync = BuffSyncNotify{}
+
+	shred, _ := config.Buffs.GetBuff(4)
+	wantID := fmt.Sprintf("buff_%d", b.nextSeq+1)
+	for i := 0; i < shred.MaxStacks+2; i++ {
+		bf, isNew := b.applyBuff(&enemy.Buffs, shred, enemy.ID, "tower_1", "p1")
+		if bf.ID != wantID || isNew != (i == 0) {
+			t.Fatalf("第 %d 次施加: ID %s 新实例 %v, 期望 ID %s", i+1, bf.ID, isNew, wantID)
+		}
+	}
+	if len(enemy.Buffs) != 1 || enemy.Buffs[0].Stacks != shred.MaxStacks {
+		t.Fatalf("叠层 Buff 应只有一个实例且为 %d 层: %+v", shred.MaxStacks, enemy.Buffs)
+	}
+
+	b.UpdateBuffs(shred.Duration)
+	if len(enemy.Buffs) != 0 || !reflect.DeepEqual(b.buffSync.RemovedBuffIDs, []string{wantID}) {
+		t.Fatalf("Buff 到期后应移除并通知: %+v, 通知 %v", enemy.Buffs, b.buffSync.RemovedBuffIDs)
+	}
+
+	poison, _ := config.Buffs.GetBuff(2)
+	player := b.Players["p1"]
+	gold, kills := player.GetGold(), b.playerStats("p1").Kills
+	enemy.HP = 1
+	b.applyBuff(&enemy.Buffs, poison, enemy.ID, "tower_sold", "p1")
+	b.UpdateBuffs(poison.TickInterval)
+
+	if b.Enemies[enemy.ID] != nil || enemy.IsAlive {
+		t.Fatal("持续伤害应击杀敌人")
+	}
+	if player.GetGold() != gold+enemy.Gold || b.playerStats("p1").Kills != kills+1 {
+		t.Fatalf("持续伤害的击杀应计入施加者: 金币 %d -> %d, 击杀 %d -> %d", gold, player.GetGold(), kills, b.playerStats("p1").Kills)
+	}
+}
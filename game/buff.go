@@ -0,0 +1,128 @@
+package game
+
+import (
+	"towerdefense/config"
+)
+
+const (
+	maxSlow        = 0.8  // 减速上限，避免敌人完全停下（眩晕除外）
+	maxAttackHaste = 0.5  // 攻速光环最多缩短的攻击间隔比例
+	buffEpsilon    = 1e-4 // 浮点累计误差，保证持续时间末尾的结算不会丢失
+)
+
+// Buff 敌人或防御塔身上的一个 Buff 实例
+type Buff struct {
+	ID        string // 实例ID（由战斗的创建序号生成）
+	BuffID    int    // 配置ID
+	TargetID  string
+	SourceID  string  // 施加者（防御塔ID）
+	OwnerID   string  // 施加者所属玩家，持续伤害的统计和击杀奖励归属该玩家
+	Stacks    int     // 叠加层数
+	Remaining float32 // 剩余时间（秒）
+	nextTick  float32 // 距下次结算的时间（秒）
+	def       *config.BuffDef
+}
+
+// State Buff 状态（用于同步）
+func (bf *Buff) State() BuffState {
+	return BuffState{
+		InstanceID: bf.ID,
+		BuffID:     bf.BuffID,
+		TargetID:   bf.TargetID,
+		SourceID:   bf.SourceID,
+		Duration:   int(bf.Remaining * 1000),
+		StackCount: bf.Stacks,
+	}
+}
+
+// buffSet 一个敌人或防御塔身上的全部 Buff，按施加顺序排列
+type buffSet []*Buff
+
+// apply 按叠加规则施加 Buff，返回受影响的实例以及是否为新实例
+// id 只在创建新实例时使用
+func (s *buffSet) apply(def *config.BuffDef, id, targetID, sourceID, ownerID string) (*Buff, bool) {
+	for _, bf := range *s {
+		if bf.BuffID != def.ID {
+			continue
+		}
+		if def.StackRule == config.BuffStackIndependent && bf.SourceID != sourceID {
+			continue
+		}
+
+		if def.StackRule == config.BuffStackStack && bf.Stacks < def.MaxStacks {
+			bf.Stacks++
+		}
+		bf.Remaining = def.Duration
+		bf.SourceID = sourceID
+		bf.OwnerID = ownerID
+		return bf, false
+	}
+
+	bf := &Buff{
+		ID:        id,
+		BuffID:    def.ID,
+		TargetID:  targetID,
+		SourceID:  sourceID,
+		OwnerID:   ownerID,
+		Stacks:    1,
+		Remaining: def.Duration,
+		nextTick:  def.TickInterval,
+		def:       def,
+	}
+	*s = append(*s, bf)
+	return bf, true
+}
+
+// update 推进 Buff 时间：每到结算间隔调用一次 onTick，返回本帧到期移除的 Buff
+func (s *buffSet) update(deltaTime float32, onTick func(bf *Buff)) []*Buff {
+	var expired []*Buff
+	kept := (*s)[:0]
+	for _, bf := range *s {
+		if bf.def.TickInterval > 0 && onTick != nil {
+			bf.nextTick -= deltaTime
+			for bf.nextTick <= buffEpsilon {
+				onTick(bf)
+				bf.nextTick += bf.def.TickInterval
+			}
+		}
+
+		bf.Remaining -= deltaTime
+		if bf.Remaining <= buffEpsilon {
+			expired = append(expired, bf)
+			continue
+		}
+		kept = append(kept, bf)
+	}
+	*s = kept
+	return expired
+}
+
+// total 某种效果的总数值（每层数值 × 层数，多个 Buff 累加）
+func (s buffSet) total(effect string) float64 {
+	sum := 0.0
+	for _, bf := range s {
+		if bf.def.Effect == effect {
+			sum += bf.def.Value * float64(bf.Stacks)
+		}
+	}
+	return sum
+}
+
+// has 是否有某种效果的 Buff
+func (s buffSet) has(effect string) bool {
+	for _, bf := range s {
+		if bf.def.Effect == effect {
+			return true
+		}
+	}
+	return false
+}
+
+// ids Buff 配置ID列表（用于状态同步）
+func (s buffSet) ids() []int {
+	ids := make([]int, 0, len(s))
+	for _, bf := range s {
+		ids = append(ids, bf.BuffID)
+	}
+	return ids
+}
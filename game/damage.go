@@ -0,0 +1,57 @@
+package game
+
+import "towerdefense/config"
+
+// armorConstant 护甲减免常数：物理伤害 × armorConstant/(armorConstant+护甲)
+// 护甲等于该值时减免一半，护甲越高收益越低
+const armorConstant = 20
+
+// DamageType 伤害类型（取值与 proto SyncDamageBroadcast.damage_type 一致）
+type DamageType int
+
+const (
+	DamageTypePhysical DamageType = 1 // 物理伤害，受护甲减免
+	DamageTypeMagic    DamageType = 2 // 魔法伤害，受魔法抗性减免
+	DamageTypeTrue     DamageType = 3 // 真实伤害，不受减免
+	DamageTypeSplash   DamageType = 4 // 溅射伤害，对目标周围的敌人造成物理伤害
+)
+
+// parseDamageType 转换防御塔配置中的伤害类型，为空时为物理伤害
+func parseDamageType(name string) DamageType {
+	switch name {
+	case config.DamageTypeMagic:
+		return DamageTypeMagic
+	case config.DamageTypeTrue:
+		return DamageTypeTrue
+	case config.DamageTypeSplash:
+		return DamageTypeSplash
+	default:
+		return DamageTypePhysical
+	}
+}
+
+// mitigate 按敌人的护甲和魔法抗性计算减免后的伤害，有伤害时至少为 1
+// 破甲降低护甲（最低为 0），易伤在减免后按比例提高伤害
+func (e *Enemy) mitigate(damage int, damageType DamageType) int {
+	if damage <= 0 {
+		return 0
+	}
+
+	result := float64(damage)
+	switch damageType {
+	case DamageTypePhysical, DamageTypeSplash:
+		armor := float64(e.Armor) - e.Buffs.total(config.BuffEffectArmorShred)
+		if armor < 0 {
+			armor = 0
+		}
+		result = result * armorConstant / (armorConstant + armor)
+	case DamageTypeMagic:
+		result = result * (1 - e.MagicResist)
+	}
+	result *= 1 + e.Buffs.total(config.BuffEffectDamageAmp)
+
+	if result < 1 {
+		return 1
+	}
+	return int(result)
+}
@@ -1,93 +1,115 @@
 package game
 
 import (
+	"errors"
+	"fmt"
 	"math"
-	"sync"
+	"towerdefense/config"
 )
 
+// ErrUnknownEnemyType 敌人类型未配置
+var ErrUnknownEnemyType = errors.New("未知的敌人类型")
+
 // Enemy 敌人
 type Enemy struct {
 	ID            string
 	Type          int
+	Name          string
+	VisualID      int  // 客户端模型/特效ID
 	HP            int
 	MaxHP         int
 	Speed         float32
 	Position      Vector3
 	PathIndex     int
 	Path          []Vector3
+	PathID        string  // 所走路径的ID（迷宫模式下重新规划路线时使用）
 	IsAlive       bool
 	Gold          int  // 击杀奖励
 	Damage        int  // 到达终点的伤害
-	mu            sync.RWMutex
+	Armor         int  // 护甲
+	MagicResist   float64 // 魔法抗性 0~1
+	Flying        bool    // 飞行单位
+	remaining     []float32 // remaining[i] 为路径点 i 到终点的路程
+	Buffs         buffSet   // 身上的 Buff（减速、中毒、眩晕、破甲、易伤）
+	seq           int64   // 生成序号（战斗内处理顺序）
 }
 
-// NewEnemy 创建敌人
-func NewEnemy(id string, enemyType int, path []Vector3) *Enemy {
+// NewEnemy 创建敌人（属性从敌人配置读取，并按波次和关卡难度成长），类型未配置时返回 ErrUnknownEnemyType
+// 飞行单位忽略路径的中间点，从起点直线飞向终点
+func NewEnemy(id string, enemyType, waveNum, difficulty int, path []Vector3) (*Enemy, error) {
+	def, ok := config.Enemies.GetEnemy(enemyType)
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownEnemyType, enemyType)
+	}
+	if def.Flying && len(path) > 2 {
+		path = []Vector3{path[0], path[len(path)-1]}
+	}
+	scaling := config.Enemies.Difficulty
+	
 	enemy := &Enemy{
-		ID:        id,
-		Type:      enemyType,
-		Path:      path,
-		PathIndex: 0,
-		IsAlive:   true,
+		ID:          id,
+		Type:        enemyType,
+		Name:        def.Name,
+		VisualID:    def.VisualID,
+		MaxHP:       def.HPAt(waveNum, scaling.HP.Multiplier(difficulty)),
+		Speed:       def.SpeedAt(waveNum, scaling.Speed.Multiplier(difficulty)),
+		Path:        path,
+		PathIndex:   0,
+		IsAlive:     true,
+		Gold:        def.Reward.Gold(waveNum, scaling.Gold.Multiplier(difficulty)),
+		Damage:      def.Damage,
+		Armor:       def.Armor,
+		MagicResist: def.MagicResist,
+		Flying:      def.Flying,
 	}
+	enemy.HP = enemy.MaxHP
 	
 	if len(path) > 0 {
 		enemy.Position = path[0]
+		enemy.setPath(path)
 	}
 	
-	// 根据类型设置属性
-	switch enemyType {
-	case 1: // 小兵
-		enemy.MaxHP = 50
-		enemy.HP = 50
-		enemy.Speed = 2.0
-		enemy.Gold = 10
-		enemy.Damage = 1
-	case 2: // 中型敌人
-		enemy.MaxHP = 100
-		enemy.HP = 100
-		enemy.Speed = 1.5
-		enemy.Gold = 20
-		enemy.Damage = 2
-	case 3: // Boss
-		enemy.MaxHP = 500
-		enemy.HP = 500
-		enemy.Speed = 1.0
-		enemy.Gold = 100
-		enemy.Damage = 5
-	default:
-		enemy.MaxHP = 50
-		enemy.HP = 50
-		enemy.Speed = 2.0
-		enemy.Gold = 10
-		enemy.Damage = 1
+	return enemy, nil
+}
+
+// setPath 设置行进路线，从路线的第一个点开始走，并计算各路径点到终点的路程
+func (e *Enemy) setPath(path []Vector3) {
+	e.Path = path
+	e.PathIndex = 0
+	e.remaining = make([]float32, len(path))
+	for i := len(path) - 2; i >= 0; i-- {
+		e.remaining[i] = e.remaining[i+1] + path[i].Distance(path[i+1])
 	}
-	
-	return enemy
 }
 
-// TakeDamage 受到伤害
-func (e *Enemy) TakeDamage(damage int) bool {
-	e.mu.Lock()
-	defer e.mu.Unlock()
+// TakeDamage 受到伤害（先按伤害类型计算护甲和魔抗减免），返回实际造成的伤害和是否被击杀
+// 已死亡的敌人不再受伤害，保证同一敌人只被击杀一次
+func (e *Enemy) TakeDamage(damage int, damageType DamageType) (int, bool) {
+	if !e.IsAlive {
+		return 0, false
+	}
 	
+	damage = e.mitigate(damage, damageType)
+	if damage > e.HP {
+		damage = e.HP
+	}
 	e.HP -= damage
 	if e.HP <= 0 {
 		e.HP = 0
 		e.IsAlive = false
-		return true // 死亡
+		return damage, true // 死亡
 	}
-	return false
+	return damage, false
 }
 
-// Move 移动
+// Move 移动（眩晕时不移动）
 func (e *Enemy) Move(deltaTime float32) bool {
-	e.mu.Lock()
-	defer e.mu.Unlock()
-	
 	if !e.IsAlive || e.PathIndex >= len(e.Path) {
 		return false
 	}
+	if e.Buffs.has(config.BuffEffectStun) {
+		return false
+	}
 	
 	// 目标位置
 	target := e.Path[e.PathIndex]
@@ -108,7 +130,7 @@ func (e *Enemy) Move(deltaTime float32) bool {
 	}
 	
 	// 移动
-	moveDistance := e.Speed * deltaTime
+	moveDistance := e.CurrentSpeed() * deltaTime
 	if moveDistance > dist {
 		moveDistance = dist
 	}
@@ -120,23 +142,42 @@ func (e *Enemy) Move(deltaTime float32) bool {
 	return false
 }
 
+// CurrentSpeed 计算减速后的移动速度
+func (e *Enemy) CurrentSpeed() float32 {
+	slow := e.Buffs.total(config.BuffEffectSlow)
+	if slow > maxSlow {
+		slow = maxSlow
+	}
+	return e.Speed * float32(1-slow)
+}
+
 // GetPosition 获取位置
 func (e *Enemy) GetPosition() Vector3 {
-	e.mu.RLock()
-	defer e.mu.RUnlock()
 	return e.Position
 }
 
 // GetHP 获取血量
 func (e *Enemy) GetHP() (int, int) {
-	e.mu.RLock()
-	defer e.mu.RUnlock()
 	return e.HP, e.MaxHP
 }
 
+// RemainingDistance 沿路径到终点的剩余路程
+func (e *Enemy) RemainingDistance() float32 {
+	if e.PathIndex >= len(e.Path) {
+		return 0
+	}
+	return e.Position.Distance(e.Path[e.PathIndex]) + e.remaining[e.PathIndex]
+}
+
+// Progress 沿路径的行进进度（0 为出生点，1 为终点）
+func (e *Enemy) Progress() float32 {
+	if len(e.remaining) == 0 || e.remaining[0] == 0 {
+		return 1
+	}
+	return 1 - e.RemainingDistance()/e.remaining[0]
+}
+
 // IsEnemyAlive 是否存活
 func (e *Enemy) IsEnemyAlive() bool {
-	e.mu.RLock()
-	defer e.mu.RUnlock()
 	return e.IsAlive
 }
@@ -0,0 +1,212 @@
+package game
+
+import (
+	"fmt"
+	"towerdefense/config"
+)
+
+// GridCell 网格坐标
+type GridCell struct {
+	Col int
+	Row int
+}
+
+// Grid 战斗中的建造网格：地块类型和防御塔占用情况（只在战斗的游戏循环协程中访问）
+type Grid struct {
+	def      *config.GridDef
+	cells    [][]byte               // 地块类型（普通模式下路径经过的格子标记为路径）
+	occupied map[GridCell]string    // 格子 -> 防御塔ID
+	ends     map[string][2]GridCell // 迷宫模式下每条路径的起点和终点
+	pathIDs  []string
+}
+
+// NewGrid 由关卡配置创建网格
+func NewGrid(level *config.LevelDef) *Grid {
+	def := &level.Grid
+	grid := &Grid{
+		def:      def,
+		cells:    make([][]byte, def.Height()),
+		occupied: make(map[GridCell]string),
+		ends:     make(map[string][2]GridCell, len(level.Paths)),
+	}
+	for row := range grid.cells {
+		grid.cells[row] = []byte(def.Rows[row])
+	}
+
+	for _, path := range level.Paths {
+		if def.Maze {
+			first, last := path.Points[0], path.Points[len(path.Points)-1]
+			start, _ := grid.cellOf(first.X, first.Z)
+			goal, _ := grid.cellOf(last.X, last.Z)
+			grid.ends[path.ID] = [2]GridCell{start, goal}
+			grid.pathIDs = append(grid.pathIDs, path.ID)
+		} else {
+			grid.markPath(path.Points)
+		}
+	}
+	return grid
+}
+
+// markPath 把路径经过的格子标记为路径，避免配置遗漏导致在路径上建塔
+func (g *Grid) markPath(path []config.Point) {
+	step := g.def.CellSize / 4
+	for i := 1; i < len(path); i++ {
+		from, to := path[i-1], path[i]
+		dist := Vector3{X: from.X, Z: from.Z}.Distance(Vector3{X: to.X, Z: to.Z})
+		steps := int(dist/step) + 1
+		for s := 0; s <= steps; s++ {
+			t := float32(s) / float32(steps)
+			if cell, ok := g.cellOf(from.X+(to.X-from.X)*t, from.Z+(to.Z-from.Z)*t); ok {
+				if g.cells[cell.Row][cell.Col] != config.CellBlocked {
+					g.cells[cell.Row][cell.Col] = config.CellPath
+				}
+			}
+		}
+	}
+}
+
+// cellOf 坐标所在的格子
+func (g *Grid) cellOf(x, z float32) (GridCell, bool) {
+	col, row, ok := g.def.CellOf(x, z)
+	return GridCell{Col: col, Row: row}, ok
+}
+
+// Center 格子中心坐标
+func (g *Grid) Center(cell GridCell) Vector3 {
+	p := g.def.CellCenter(cell.Col, cell.Row)
+	return Vector3{X: p.X, Y: p.Y, Z: p.Z}
+}
+
+// Snap 把坐标对齐到所在格子的中心，超出网格返回 ErrInvalidPosition
+func (g *Grid) Snap(pos Vector3) (GridCell, Vector3, error) {
+	cell, ok := g.cellOf(pos.X, pos.Z)
+	if !ok {
+		return GridCell{}, Vector3{}, fmt.Errorf("%w: 超出地图范围", ErrInvalidPosition)
+	}
+	return cell, g.Center(cell), nil
+}
+
+// CanBuild 检查玩家（座位号 lane）能否在格子上建塔，不能时返回 ErrInvalidPosition
+func (g *Grid) CanBuild(cell GridCell, lane int) error {
+	if _, ok := g.occupied[cell]; ok {
+		return fmt.Errorf("%w: 该位置已有防御塔", ErrInvalidPosition)
+	}
+
+	switch c := g.cells[cell.Row][cell.Col]; {
+	case c == config.CellBlocked:
+		return fmt.Errorf("%w: 该位置是障碍", ErrInvalidPosition)
+	case c == config.CellPath && !g.def.Maze:
+		return fmt.Errorf("%w: 不能建在敌人路径上", ErrInvalidPosition)
+	case c >= '1' && c <= '9' && int(c-'0') != lane:
+		return fmt.Errorf("%w: 该位置是 %c 号玩家的专属区域", ErrInvalidPosition, c)
+	}
+
+	if g.def.Maze {
+		for _, ends := range g.ends {
+			if cell == ends[0] || cell == ends[1] {
+				return fmt.Errorf("%w: 不能建在敌人的起点或终点", ErrInvalidPosition)
+			}
+		}
+		g.occupied[cell] = ""
+		defer delete(g.occupied, cell)
+		for _, pathID := range g.pathIDs {
+			if ends := g.ends[pathID]; g.findRoute(ends[0], ends[1]) == nil {
+				return fmt.Errorf("%w: 不能完全堵死敌人路径 %s", ErrInvalidPosition, pathID)
+			}
+		}
+	}
+	return nil
+}
+
+// Occupy 占用格子
+func (g *Grid) Occupy(cell GridCell, towerID string) {
+	g.occupied[cell] = towerID
+}
+
+// Release 释放格子（出售防御塔时）
+func (g *Grid) Release(cell GridCell) {
+	delete(g.occupied, cell)
+}
+
+// IsMaze 是否为迷宫模式
+func (g *Grid) IsMaze() bool {
+	return g.def.Maze
+}
+
+// Route 迷宫模式下地面敌人沿路径 pathID 从起点到终点的最短路线（格子中心点，已合并同方向的连续格子）
+// 路径不存在或路线被堵死时返回 nil（放置时已校验，正常不会发生）
+func (g *Grid) Route(pathID string) []Vector3 {
+	ends, ok := g.ends[pathID]
+	if !ok {
+		return nil
+	}
+	return g.simplify(g.findRoute(ends[0], ends[1]))
+}
+
+// RouteFrom 迷宫模式下场上的地面敌人从 pos 所在格子到路径 pathID 终点的最短路线
+// 敌人被防御塔围住、无法到达终点时返回 nil
+func (g *Grid) RouteFrom(pathID string, pos Vector3) []Vector3 {
+	ends, ok := g.ends[pathID]
+	if !ok {
+		return nil
+	}
+	start, ok := g.cellOf(pos.X, pos.Z)
+	if !ok {
+		return nil
+	}
+	return g.simplify(g.findRoute(start, ends[1]))
+}
+
+// simplify 把格子序列转换为格子中心点路线，合并同方向的连续格子
+func (g *Grid) simplify(cells []GridCell) []Vector3 {
+	if cells == nil {
+		return nil
+	}
+
+	route := []Vector3{g.Center(cells[0])}
+	for i := 1; i < len(cells)-1; i++ {
+		prev, cur, next := cells[i-1], cells[i], cells[i+1]
+		if cur.Col-prev.Col != next.Col-cur.Col || cur.Row-prev.Row != next.Row-cur.Row {
+			route = append(route, g.Center(cur))
+		}
+	}
+	if len(cells) > 1 {
+		route = append(route, g.Center(cells[len(cells)-1]))
+	}
+	return route
+}
+
+// findRoute 按四方向广度优先搜索起点到终点的格子序列，不可达时返回 nil
+func (g *Grid) findRoute(start, goal GridCell) []GridCell {
+	prev := map[GridCell]GridCell{start: start}
+	queue := []GridCell{start}
+	dirs := []GridCell{{Col: 1}, {Col: -1}, {Row: 1}, {Row: -1}}
+
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if cur == goal {
+			cells := []GridCell{cur}
+			for cur != start {
+				cur = prev[cur]
+				cells = append([]GridCell{cur}, cells...)
+			}
+			return cells
+		}
+		for _, d := range dirs {
+			next := GridCell{Col: cur.Col + d.Col, Row: cur.Row + d.Row}
+			if next.Col < 0 || next.Row < 0 || next.Col >= g.def.Width() || next.Row >= g.def.Height() {
+				continue
+			}
+			if _, seen := prev[next]; seen {
+				continue
+			}
+			if _, ok := g.occupied[next]; ok || g.cells[next.Row][next.Col] == config.CellBlocked {
+				continue
+			}
+			prev[next] = cur
+			queue = append(queue, next)
+		}
+	}
+	return nil
+}
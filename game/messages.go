@@ -2,6 +2,9 @@ package game
 
 // 消息类型常量 - 与 proto Cmd 枚举保持一致
 const (
+	// 玩家相关 NTF
+	MsgTypeLevelUpNtf     = 1106
+	
 	// 房间相关 RSP/NTF
 	MsgTypeRoomInfoRsp    = 2007
 	
@@ -15,6 +18,10 @@ const (
 	MsgTypeSyncEnemyNtf   = 4001
 	MsgTypeSyncTowerNtf   = 4002
 	MsgTypeSyncDamageNtf  = 4003
+	MsgTypeSyncBuffNtf    = 4004
+	MsgTypePathUpdateNtf  = 4005
+	MsgTypeSkillCastNtf   = 4006
+	MsgTypeBattlePauseNtf = 4007
 )
 
 // 状态同步广播结构
@@ -28,14 +35,18 @@ type SyncStateBroadcast struct {
 
 // 敌人状态
 type EnemyState struct {
-	EnemyID string  `json:"enemy_id"`
-	Type    int     `json:"type"`
-	HP      int     `json:"hp"`
-	MaxHP   int     `json:"max_hp"`
-	PosX    float32 `json:"pos_x"`
-	PosY    float32 `json:"pos_y"`
-	PosZ    float32 `json:"pos_z"`
-	Speed   float32 `json:"speed"`
+	EnemyID  string  `json:"enemy_id"`
+	Type     int     `json:"type"`
+	HP       int     `json:"hp"`
+	MaxHP    int     `json:"max_hp"`
+	PosX     float32 `json:"pos_x"`
+	PosY     float32 `json:"pos_y"`
+	PosZ     float32 `json:"pos_z"`
+	Speed    float32 `json:"speed"`
+	VisualID int     `json:"visual_id"`
+	Flying   bool    `json:"flying"`
+	Debuffs  []int   `json:"debuffs"`       // 身上的 Buff 配置ID
+	Progress float32 `json:"path_progress"` // 沿路径的行进进度 0-1
 }
 
 // 防御塔状态
@@ -47,6 +58,8 @@ type TowerState struct {
 	PosY     float32 `json:"pos_y"`
 	PosZ     float32 `json:"pos_z"`
 	TargetID string  `json:"target_id"`
+	TargetMode TargetMode `json:"target_mode"`
+	Buffs    []int   `json:"buffs"` // 身上的 Buff 配置ID
 }
 
 // 波次开始广播
@@ -65,17 +78,75 @@ type SyncDamageBroadcast struct {
 	TowerID string `json:"tower_id"`
 	EnemyID string `json:"enemy_id"`
 	Damage  int    `json:"damage"`
+	DamageType DamageType `json:"damage_type"`
 	IsCrit  bool   `json:"is_crit"`
 	IsKill  bool   `json:"is_kill"`
 }
 
+// Buff 状态
+type BuffState struct {
+	InstanceID string `json:"instance_id"`
+	BuffID     int    `json:"buff_id"`
+	TargetID   string `json:"target_id"`
+	SourceID   string `json:"source_id"`
+	Duration   int    `json:"duration"` // 剩余时间（毫秒）
+	StackCount int    `json:"stack_count"`
+}
+
+// Buff 同步通知（本帧施加/刷新的 Buff 和到期移除的 Buff 实例ID）
+type BuffSyncNotify struct {
+	Buffs          []BuffState `json:"buffs"`
+	RemovedBuffIDs []string    `json:"removed_buff_ids"`
+}
+
+// 一条路径的行进路线
+type PathRoute struct {
+	PathID string    `json:"path_id"`
+	Points []Vector3 `json:"points"`
+}
+
+// 路线变化通知（迷宫模式下建造或出售防御塔后发送全部路径的当前路线）
+type PathUpdateBroadcast struct {
+	Paths []PathRoute `json:"paths"`
+}
+
+// 玩家释放技能广播（伤害和 Buff 另见伤害与 Buff 同步通知）
+type SkillCastBroadcast struct {
+	PlayerID string  `json:"player_id"`
+	SkillID  int     `json:"skill_id"`
+	Position Vector3 `json:"position"`
+}
+
+// 战斗暂停/继续广播
+type BattlePauseBroadcast struct {
+	PlayerID string `json:"player_id"`
+	Paused   bool   `json:"paused"`
+}
+
 // 游戏结束广播
 type GameOverBroadcast struct {
-	IsVictory   bool   `json:"is_victory"`
-	TotalWaves  int    `json:"total_waves"`
-	KillCount   int    `json:"kill_count"`
-	TotalDamage int64  `json:"total_damage"`
-	Score       int    `json:"score"`
+	IsVictory   bool              `json:"is_victory"`
+	TotalWaves  int               `json:"total_waves"`
+	KillCount   int               `json:"kill_count"`
+	TotalDamage int64             `json:"total_damage"`
+	Score       int               `json:"score"`
+	PlayerStats []PlayerGameStats `json:"player_stats"` // 按个人得分降序
+	MVPPlayerID string            `json:"mvp_player_id"`
+	Reward      *BattleReward     `json:"reward"`       // 接收者本人的奖励
+}
+
+// 玩家战斗统计
+type PlayerGameStats struct {
+	PlayerID     string `json:"player_id"`
+	PlayerName   string `json:"player_name"`
+	KillCount    int    `json:"kill_count"`
+	TotalDamage  int64  `json:"total_damage"`
+	TowerCount   int    `json:"tower_count"`   // 建造塔数量
+	UpgradeCount int    `json:"upgrade_count"` // 升级次数
+	SellCount    int    `json:"sell_count"`    // 出售次数
+	GoldEarned   int    `json:"gold_earned"`
+	GoldSpent    int    `json:"gold_spent"`
+	Score        int    `json:"score"`
 }
 
 // 玩家信息
@@ -92,3 +163,19 @@ type RoomInfoBroadcast struct {
 	Players []PlayerInfo `json:"players"`
 	Status  string       `json:"status"` // waiting, playing, finished
 }
+
+// 玩家升级通知
+type LevelUpNotify struct {
+	OldLevel     int          `json:"old_level"`
+	NewLevel     int          `json:"new_level"`
+	Exp          int          `json:"exp"`            // 当前等级经验
+	NextLevelExp int          `json:"next_level_exp"` // 升到下一级所需经验，满级为 0
+	Unlocks      []UnlockInfo `json:"unlocks"`        // 本次升级解锁的内容
+}
+
+// 解锁内容
+type UnlockInfo struct {
+	Type string `json:"type"` // tower, icon, frame, level
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
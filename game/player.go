@@ -11,6 +11,7 @@ type Player struct {
 	SessionID  string
 	IsReady    bool
 	IsHost     bool
+	Lane       int // 座位号（从 1 开始），对应地图上的专属建造区
 	Gold       int
 	Life       int
 	Score      int
@@ -1,7 +1,10 @@
 package game
 
 import (
+	"errors"
+	"fmt"
 	"sync"
+	"towerdefense/config"
 	"towerdefense/utils"
 	
 	"github.com/google/uuid"
@@ -16,6 +19,11 @@ const (
 	RoomStatusFinished RoomStatus = "finished"
 )
 
+var (
+	ErrUnknownLevel   = errors.New("关卡不存在")
+	ErrRoomNotWaiting = errors.New("房间不在等待状态")
+)
+
 // Room 房间
 type Room struct {
 	ID        string
@@ -57,11 +65,25 @@ func (r *Room) AddPlayer(player *Player) bool {
 		r.HostID = player.ID
 	}
 	
+	player.Lane = r.freeLaneLocked()
 	r.Players[player.ID] = player
-	utils.Info("玩家 %s 加入房间 %s", player.Name, r.ID)
+	utils.Info("玩家 %s 加入房间 %s，座位 %d", player.Name, r.ID, player.Lane)
 	return true
 }
 
+// freeLaneLocked 最小的空闲座位号（调用时已持有锁）
+func (r *Room) freeLaneLocked() int {
+	used := make(map[int]bool, len(r.Players))
+	for _, p := range r.Players {
+		used[p.Lane] = true
+	}
+	lane := 1
+	for used[lane] {
+		lane++
+	}
+	return lane
+}
+
 // RemovePlayer 移除玩家
 func (r *Room) RemovePlayer(playerID string) {
 	r.mu.Lock()
@@ -100,6 +122,20 @@ func (r *Room) GetPlayers() []*Player {
 	return players
 }
 
+// GetHostID 获取房主ID
+func (r *Room) GetHostID() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.HostID
+}
+
+// GetBattle 获取当前战斗，未开始游戏时返回 nil
+func (r *Room) GetBattle() *Battle {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.Battle
+}
+
 // GetPlayerCount 获取玩家数量
 func (r *Room) GetPlayerCount() int {
 	r.mu.RLock()
@@ -124,23 +160,90 @@ func (r *Room) AllReady() bool {
 	return true
 }
 
-// StartGame 开始游戏
-func (r *Room) StartGame(initialGold, initialLife int) {
+// StartGame 按关卡配置创建战斗并发放带入战斗的加成，返回尚未启动的战斗
+// 调用方发送开始游戏响应后再调用 Battle.Start，保证客户端先收到响应，再收到波次和同步消息
+func (r *Room) StartGame() (*Battle, error) {
+	level, players, err := r.beginGame()
+	if err != nil {
+		return nil, err
+	}
+	
+	// 初始化玩家游戏数据
+	for _, p := range players {
+		p.InitGameData(level.InitialGold(), level.InitialLife())
+	}
+	
+	// 创建战斗实例，道具加成需要读写存储，在房间锁外发放
+	battle := NewBattle(r.ID, level, players)
+	battle.RoomName = r.Name
+	applyStartBonus(battle)
+	
+	r.mu.Lock()
+	r.Battle = battle
+	r.mu.Unlock()
+	
+	utils.Info("房间 %s 游戏开始，关卡 %d", r.ID, r.LevelID)
+	return battle, nil
+}
+
+// beginGame 检查房间状态并标记为游戏中，返回关卡配置和参战玩家
+func (r *Room) beginGame() (*config.LevelDef, map[string]*Player, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	
+	if r.Status != RoomStatusWaiting {
+		return nil, nil, ErrRoomNotWaiting
+	}
+	level, ok := config.Levels.GetLevel(r.LevelID)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %d", ErrUnknownLevel, r.LevelID)
+	}
+	
 	r.Status = RoomStatusPlaying
+	players := make(map[string]*Player, len(r.Players))
+	for id, p := range r.Players {
+		players[id] = p
+	}
+	return level, players, nil
+}
+
+// applyStartBonus 发放带入战斗的初始金币加成（战斗启动前调用）
+func applyStartBonus(battle *Battle) {
+	if globalStartHandler == nil {
+		return
+	}
 	
-	// 初始化玩家游戏数据
+	playerIDs := make([]string, 0, len(battle.Players))
+	for id := range battle.Players {
+		playerIDs = append(playerIDs, id)
+	}
+	for id, gold := range globalStartHandler.OnBattleStart(battle.ID, playerIDs) {
+		if p, ok := battle.Players[id]; ok && gold > 0 {
+			p.AddGold(gold)
+			utils.Info("玩家 %s 带入战斗额外初始金币: %d", id, gold)
+		}
+	}
+}
+
+// EndGame 战斗结算完成后把房间恢复为等待状态，房主可以再次开始游戏（其他玩家需重新准备）
+// 房间已结束或已开始了另一场战斗时不做修改
+func (r *Room) EndGame(battle *Battle) {
+	r.mu.Lock()
+	if r.Status != RoomStatusPlaying || r.Battle != battle {
+		r.mu.Unlock()
+		return
+	}
+	r.Status = RoomStatusWaiting
+	r.Battle = nil
 	for _, p := range r.Players {
-		p.InitGameData(initialGold, initialLife)
+		if !p.IsHost {
+			p.SetReady(false)
+		}
 	}
+	r.mu.Unlock()
 	
-	// 创建战斗实例
-	r.Battle = NewBattle(r.ID, r.LevelID, r.Players)
-	r.Battle.Start()
-	
-	utils.Info("房间 %s 游戏开始", r.ID)
+	utils.Info("房间 %s 战斗结束，等待再次开始", r.ID)
+	r.BroadcastRoomInfo()
 }
 
 // FinishGame 结束游戏
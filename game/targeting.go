@@ -0,0 +1,58 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidTargetMode 未知的目标选择模式
+var ErrInvalidTargetMode = errors.New("未知的目标选择模式")
+
+// TargetMode 防御塔目标选择模式（取值与 proto TargetMode 枚举一致）
+type TargetMode int
+
+const (
+	TargetClosest   TargetMode = 0 // 最近的敌人（默认）
+	TargetFirst     TargetMode = 1 // 离终点最近（沿路径走得最远）的敌人
+	TargetLast      TargetMode = 2 // 离终点最远的敌人
+	TargetStrongest TargetMode = 3 // 当前血量最高的敌人
+	TargetWeakest   TargetMode = 4 // 当前血量最低的敌人
+	TargetFastest   TargetMode = 5 // 移动速度最快的敌人
+	TargetKeep      TargetMode = 6 // 保持当前目标直到死亡或离开射程，再选最近的敌人
+)
+
+// ParseTargetMode 校验客户端传入的目标选择模式
+func ParseTargetMode(mode int) (TargetMode, error) {
+	if mode < int(TargetClosest) || mode > int(TargetKeep) {
+		return 0, fmt.Errorf("%w: %d", ErrInvalidTargetMode, mode)
+	}
+	return TargetMode(mode), nil
+}
+
+// better 按模式比较两个候选目标，a 优于 b 时返回 true（dist 为与防御塔的距离）
+// 主条件相同时选更近的敌人，保证结果只由战斗状态决定
+func (m TargetMode) better(a *Enemy, distA float32, b *Enemy, distB float32) bool {
+	switch m {
+	case TargetFirst:
+		if ra, rb := a.RemainingDistance(), b.RemainingDistance(); ra != rb {
+			return ra < rb
+		}
+	case TargetLast:
+		if ra, rb := a.RemainingDistance(), b.RemainingDistance(); ra != rb {
+			return ra > rb
+		}
+	case TargetStrongest:
+		if a.HP != b.HP {
+			return a.HP > b.HP
+		}
+	case TargetWeakest:
+		if a.HP != b.HP {
+			return a.HP < b.HP
+		}
+	case TargetFastest:
+		if a.Speed != b.Speed {
+			return a.Speed > b.Speed
+		}
+	}
+	return distA < distB
+}
@@ -1,8 +1,20 @@
 package game
 
 import (
+	"errors"
+	"fmt"
 	"math"
-	"sync"
+	"math/rand"
+	"towerdefense/config"
+)
+
+var (
+	ErrUnknownTowerType = errors.New("未知的防御塔类型")
+	ErrTowerMaxLevel    = errors.New("防御塔已达到最高等级")
+	ErrTowerNotFound    = errors.New("防御塔不存在")
+	ErrNotEnoughGold    = errors.New("金币不足")
+	ErrNotInBattle      = errors.New("玩家不在战斗中")
+	ErrInvalidPosition  = errors.New("无法在该位置建造")
 )
 
 // Vector3 三维向量
@@ -24,95 +36,97 @@ func (v Vector3) Distance(other Vector3) float32 {
 type Tower struct {
 	ID         string
 	Type       int
+	Name       string
 	Level      int
 	OwnerID    string
 	Position   Vector3
+	Cell       GridCell // 占用的网格
 	Damage     int
+	DamageType DamageType
+	SplashRadius float32 // 溅射半径（仅溅射伤害）
 	AttackSpeed float32
 	Range      float32
 	Target     *Enemy
+	TargetMode TargetMode // 目标选择模式
+	Buffs      buffSet    // 身上的 Buff（攻速光环）
 	LastAttack float32
-	Cost       int
+	Cost       int // 建造花费
+	TotalCost  int // 累计投入（建造 + 升级）
 	SellValue  int
-	mu         sync.RWMutex
+	TotalDamage int64 // 累计造成伤害
+	KillCount  int    // 累计击杀
+	def        *config.TowerDef
+	seq        int64 // 建造序号（战斗内处理顺序）
 }
 
-// NewTower 创建防御塔
-func NewTower(id string, towerType int, ownerID string, pos Vector3) *Tower {
-	// 根据类型设置属性（这里简化处理，实际应从配置表读取）
+// NewTower 创建防御塔（属性从防御塔配置读取），类型未配置时返回 ErrUnknownTowerType
+func NewTower(id string, towerType int, ownerID string, pos Vector3) (*Tower, error) {
+	def, ok := config.Towers.GetTower(towerType)
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownTowerType, towerType)
+	}
+	
 	tower := &Tower{
 		ID:       id,
 		Type:     towerType,
-		Level:    1,
+		Name:     def.Name,
 		OwnerID:  ownerID,
 		Position: pos,
+		DamageType: parseDamageType(def.DamageType),
+		SplashRadius: def.SplashRadius,
+		def:      def,
 	}
+	tower.applyLevel(1)
+	tower.Cost = def.Levels[0].Cost
+	tower.TotalCost = tower.Cost
+	tower.SellValue = tower.sellValue()
 	
-	// 不同类型塔的属性
-	switch towerType {
-	case 1: // 箭塔
-		tower.Damage = 10
-		tower.AttackSpeed = 1.0
-		tower.Range = 5.0
-		tower.Cost = 50
-		tower.SellValue = 25
-	case 2: // 炮塔
-		tower.Damage = 30
-		tower.AttackSpeed = 2.0
-		tower.Range = 6.0
-		tower.Cost = 100
-		tower.SellValue = 50
-	case 3: // 魔法塔
-		tower.Damage = 15
-		tower.AttackSpeed = 0.8
-		tower.Range = 7.0
-		tower.Cost = 80
-		tower.SellValue = 40
-	default:
-		tower.Damage = 10
-		tower.AttackSpeed = 1.0
-		tower.Range = 5.0
-		tower.Cost = 50
-		tower.SellValue = 25
-	}
-	
-	return tower
+	return tower, nil
 }
 
-// FindTarget 寻找目标
+// FindTarget 按目标选择模式在射程内寻找目标（只有防空塔能攻击飞行单位）
 func (t *Tower) FindTarget(enemies []*Enemy) *Enemy {
-	t.mu.Lock()
-	defer t.mu.Unlock()
+	if t.TargetMode == TargetKeep && t.canTarget(t.Target) {
+		return t.Target
+	}
 	
-	var closest *Enemy
-	minDist := float32(math.MaxFloat32)
+	var best *Enemy
+	bestDist := float32(math.MaxFloat32)
 	
 	for _, enemy := range enemies {
-		if !enemy.IsAlive {
+		if !t.canTarget(enemy) {
 			continue
 		}
 		
 		dist := t.Position.Distance(enemy.Position)
-		if dist <= t.Range && dist < minDist {
-			closest = enemy
-			minDist = dist
+		if best == nil || t.TargetMode.better(enemy, dist, best, bestDist) {
+			best = enemy
+			bestDist = dist
 		}
 	}
 	
-	t.Target = closest
-	return closest
+	t.Target = best
+	return best
+}
+
+// canTarget 敌人是否存活、在射程内且能被该防御塔攻击
+func (t *Tower) canTarget(enemy *Enemy) bool {
+	if enemy == nil || !enemy.IsAlive {
+		return false
+	}
+	if enemy.Flying && !t.def.AntiAir {
+		return false
+	}
+	return t.Position.Distance(enemy.Position) <= t.Range
 }
 
 // CanAttack 是否可以攻击
 func (t *Tower) CanAttack(currentTime float32) bool {
-	t.mu.RLock()
-	defer t.mu.RUnlock()
-	
 	if t.Target == nil || !t.Target.IsAlive {
 		return false
 	}
 	
-	if currentTime-t.LastAttack < t.AttackSpeed {
+	if currentTime-t.LastAttack < t.AttackInterval() {
 		return false
 	}
 	
@@ -120,55 +134,158 @@ func (t *Tower) CanAttack(currentTime float32) bool {
 	return dist <= t.Range
 }
 
-// Attack 攻击
-func (t *Tower) Attack(currentTime float32) (int, bool) {
-	t.mu.Lock()
-	defer t.mu.Unlock()
-	
+// AttackInterval 计算攻速光环加成后的攻击间隔
+func (t *Tower) AttackInterval() float32 {
+	haste := t.Buffs.total(config.BuffEffectAttackSpeed)
+	if haste > maxAttackHaste {
+		haste = maxAttackHaste
+	}
+	return t.AttackSpeed * float32(1-haste)
+}
+
+// OnHitBuffs 命中时施加给敌人的 Buff
+func (t *Tower) OnHitBuffs() []*config.BuffDef {
+	buffs := make([]*config.BuffDef, 0, len(t.def.OnHitBuffs))
+	for _, id := range t.def.OnHitBuffs {
+		if def, ok := config.Buffs.GetBuff(id); ok {
+			buffs = append(buffs, def)
+		}
+	}
+	return buffs
+}
+
+// Aura 光环 Buff 和半径，没有光环时返回 nil
+func (t *Tower) Aura() (*config.BuffDef, float32) {
+	if t.def.AuraBuff == 0 {
+		return nil, 0
+	}
+	def, ok := config.Buffs.GetBuff(t.def.AuraBuff)
+	if !ok {
+		return nil, 0
+	}
+	return def, t.def.AuraRadius
+}
+
+// Attack 攻击，按配置的暴击率和倍率计算暴击，暴击由战斗的随机数决定
+// 返回减免前的伤害，由 Enemy.TakeDamage 按伤害类型减免
+func (t *Tower) Attack(currentTime float32, rng *rand.Rand) (int, bool) {
 	t.LastAttack = currentTime
 	
-	// 简单的暴击计算
 	isCrit := false
 	damage := t.Damage
-	if math.Mod(float64(currentTime*100), 10) < 1 { // 10%暴击率
+	if t.def.CritChance > 0 && rng.Float64() < t.def.CritChance {
 		isCrit = true
-		damage = int(float32(damage) * 2.0)
+		damage = int(float64(damage) * t.def.CritMultiplier)
 	}
 	
 	return damage, isCrit
 }
 
-// Upgrade 升级
-func (t *Tower) Upgrade() int {
-	t.mu.Lock()
-	defer t.mu.Unlock()
-	
-	t.Level++
-	
-	// 升级属性提升
-	t.Damage = int(float32(t.Damage) * 1.3)
-	t.AttackSpeed *= 0.9
-	t.Range += 0.5
+// Victims 本次攻击命中的敌人：溅射伤害命中目标及其周围溅射半径内能被攻击的敌人，其余只命中目标
+func (t *Tower) Victims(enemies []*Enemy) []*Enemy {
+	if t.DamageType != DamageTypeSplash {
+		return []*Enemy{t.Target}
+	}
 	
-	cost := t.Cost * t.Level / 2
-	t.SellValue = cost / 2
+	victims := []*Enemy{t.Target}
+	for _, enemy := range enemies {
+		if enemy == t.Target || !enemy.IsAlive {
+			continue
+		}
+		if enemy.Flying && !t.def.AntiAir {
+			continue
+		}
+		if t.Target.Position.Distance(enemy.Position) <= t.SplashRadius {
+			victims = append(victims, enemy)
+		}
+	}
+	return victims
+}
+
+// UpgradeCost 升级到下一级的花费，已满级时返回 ErrTowerMaxLevel
+func (t *Tower) UpgradeCost() (int, error) {
+	next, ok := t.def.GetLevel(t.Level + 1)
+	if !ok {
+		return 0, ErrTowerMaxLevel
+	}
+	return next.Cost, nil
+}
+
+// Upgrade 升级到下一级，返回花费（需先通过 UpgradeCost 确认未满级）
+func (t *Tower) Upgrade() int {
+	next, ok := t.def.GetLevel(t.Level + 1)
+	if !ok {
+		return 0
+	}
+	t.applyLevel(t.Level + 1)
+	t.TotalCost += next.Cost
+	t.SellValue = t.sellValue()
 	
-	return cost
+	return next.Cost
+}
+
+// applyLevel 应用某一等级的属性
+func (t *Tower) applyLevel(level int) {
+	stats, _ := t.def.GetLevel(level)
+	t.Level = level
+	t.Damage = stats.Damage
+	t.AttackSpeed = stats.AttackSpeed
+	t.Range = stats.Range
+}
+
+// sellValue 出售返还金币（累计投入 × 出售比例）
+func (t *Tower) sellValue() int {
+	return int(float64(t.TotalCost) * t.def.SellRatio)
+}
+
+// GetLevel 获取当前等级
+func (t *Tower) GetLevel() int {
+	return t.Level
+}
+
+// RecordHit 记录造成的伤害
+func (t *Tower) RecordHit(damage int, isKill bool) {
+	t.TotalDamage += int64(damage)
+	if isKill {
+		t.KillCount++
+	}
+}
+
+// State 防御塔状态（用于同步和操作返回）
+func (t *Tower) State() TowerState {
+	targetID := ""
+	if t.Target != nil {
+		targetID = t.Target.ID
+	}
+	return TowerState{
+		TowerID:  t.ID,
+		Type:     t.Type,
+		Level:    t.Level,
+		PosX:     t.Position.X,
+		PosY:     t.Position.Y,
+		PosZ:     t.Position.Z,
+		TargetID: targetID,
+		TargetMode: t.TargetMode,
+		Buffs:    t.Buffs.ids(),
+	}
 }
 
 // GetInfo 获取信息
 func (t *Tower) GetInfo() map[string]interface{} {
-	t.mu.RLock()
-	defer t.mu.RUnlock()
-	
 	return map[string]interface{}{
 		"id":           t.ID,
 		"type":         t.Type,
+		"name":         t.Name,
 		"level":        t.Level,
+		"max_level":    t.def.MaxLevel(),
 		"damage":       t.Damage,
+		"damage_type":  t.DamageType,
 		"attack_speed": t.AttackSpeed,
 		"range":        t.Range,
+		"target_mode":  t.TargetMode,
 		"cost":         t.Cost,
 		"sell_value":   t.SellValue,
+		"total_damage": t.TotalDamage,
+		"kill_count":   t.KillCount,
 	}
 }
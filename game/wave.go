@@ -1,7 +1,7 @@
 package game
 
 import (
-	"sync"
+	"towerdefense/config"
 )
 
 // Wave 波次
@@ -9,116 +9,73 @@ type Wave struct {
 	WaveNum        int
 	EnemyTypes     []int
 	EnemyCounts    []int
-	SpawnInterval  float32
 	Reward         int
 	TotalEnemies   int
 	SpawnedEnemies int
-	LastSpawnTime  float32
+	StartTime      float32 // 开始生成敌人的游戏时间（已包含准备时间）
 	IsComplete     bool
-	mu             sync.RWMutex
+	groups         []*waveGroup
 }
 
-// NewWave 创建波次
-func NewWave(waveNum int) *Wave {
+// waveGroup 波次中一组敌人的生成进度
+type waveGroup struct {
+	config.EnemyGroup
+	spawned int
+}
+
+// NewWave 由关卡配置创建波次，gameTime 为当前游戏时间
+func NewWave(waveNum int, def *config.WaveDef, gameTime float32) *Wave {
 	wave := &Wave{
-		WaveNum:       waveNum,
-		SpawnInterval: 1.0,
-		IsComplete:    false,
-	}
-	
-	// 根据波次生成敌人配置
-	switch {
-	case waveNum <= 3:
-		// 前3波：只有小兵
-		wave.EnemyTypes = []int{1}
-		wave.EnemyCounts = []int{5 + waveNum*2}
-		wave.Reward = 50 + waveNum*10
-	case waveNum <= 6:
-		// 4-6波：小兵+中型敌人
-		wave.EnemyTypes = []int{1, 2}
-		wave.EnemyCounts = []int{10, waveNum - 2}
-		wave.Reward = 100 + waveNum*20
-	case waveNum <= 9:
-		// 7-9波：更多敌人
-		wave.EnemyTypes = []int{1, 2}
-		wave.EnemyCounts = []int{15, waveNum}
-		wave.Reward = 200 + waveNum*30
-	case waveNum == 10:
-		// 第10波：Boss波
-		wave.EnemyTypes = []int{1, 2, 3}
-		wave.EnemyCounts = []int{20, 10, 1}
-		wave.Reward = 500
-	default:
-		// 后续波次：难度递增
-		wave.EnemyTypes = []int{1, 2, 3}
-		wave.EnemyCounts = []int{
-			10 + waveNum*2,
-			5 + waveNum,
-			(waveNum - 9) / 3,
-		}
-		wave.Reward = 300 + waveNum*50
+		WaveNum:      waveNum,
+		Reward:       def.Reward,
+		TotalEnemies: def.TotalEnemies(),
+		StartTime:    gameTime + def.Delay,
+		IsComplete:   false,
 	}
 	
-	// 计算总敌人数
-	for _, count := range wave.EnemyCounts {
-		wave.TotalEnemies += count
+	for _, group := range def.Groups {
+		wave.EnemyTypes = append(wave.EnemyTypes, group.EnemyType)
+		wave.EnemyCounts = append(wave.EnemyCounts, group.Count)
+		wave.groups = append(wave.groups, &waveGroup{EnemyGroup: group})
 	}
 	
 	return wave
 }
 
-// CanSpawn 是否可以生成敌人
-func (w *Wave) CanSpawn(currentTime float32) bool {
-	w.mu.RLock()
-	defer w.mu.RUnlock()
-	
+// GetNextEnemy 获取下一个到达生成时间的敌人所在的组（敌人类型和路径权重），没有时返回 false
+// 一帧内可能有多个敌人到达生成时间，调用方应循环调用直到返回 false
+func (w *Wave) GetNextEnemy(currentTime float32) (config.EnemyGroup, bool) {
 	if w.IsComplete || w.SpawnedEnemies >= w.TotalEnemies {
-		return false
+		return config.EnemyGroup{}, false
 	}
 	
-	return currentTime-w.LastSpawnTime >= w.SpawnInterval
-}
-
-// GetNextEnemy 获取下一个要生成的敌人类型
-func (w *Wave) GetNextEnemy(currentTime float32) (int, bool) {
-	w.mu.Lock()
-	defer w.mu.Unlock()
-	
-	if w.SpawnedEnemies >= w.TotalEnemies {
-		return 0, false
-	}
-	
-	// 按照配置顺序生成敌人
-	spawned := 0
-	for i, count := range w.EnemyCounts {
-		if spawned+count > w.SpawnedEnemies {
+	// 按照配置顺序检查每组的下一个敌人是否到达生成时间
+	for _, group := range w.groups {
+		if group.spawned >= group.Count {
+			continue
+		}
+		spawnTime := w.StartTime + group.Delay + float32(group.spawned)*group.SpawnInterval
+		if currentTime >= spawnTime {
+			group.spawned++
 			w.SpawnedEnemies++
-			w.LastSpawnTime = currentTime
-			return w.EnemyTypes[i], true
+			return group.EnemyGroup, true
 		}
-		spawned += count
 	}
 	
-	return 0, false
+	return config.EnemyGroup{}, false
 }
 
 // Complete 完成波次
 func (w *Wave) Complete() {
-	w.mu.Lock()
-	defer w.mu.Unlock()
 	w.IsComplete = true
 }
 
 // IsWaveComplete 是否完成
 func (w *Wave) IsWaveComplete() bool {
-	w.mu.RLock()
-	defer w.mu.RUnlock()
 	return w.IsComplete
 }
 
 // GetProgress 获取进度
 func (w *Wave) GetProgress() (int, int) {
-	w.mu.RLock()
-	defer w.mu.RUnlock()
 	return w.SpawnedEnemies, w.TotalEnemies
 }
@@ -0,0 +1,172 @@
+package logic
+
+import (
+	"time"
+	"towerdefense/game"
+	"towerdefense/repository"
+	"towerdefense/utils"
+)
+
+// BattleRecorder 战斗结算持久化（实现 game.BattleResultHandler）
+// 写入战斗记录，更新每个参战玩家的统计并发放奖励，以战斗ID保证每场战斗只结算一次
+type BattleRecorder struct {
+	recordRepo *repository.GameRecordRepository
+	rewardRepo *repository.RewardRepository
+	calculator *RewardCalculator
+}
+
+// NewBattleRecorder 创建战斗结算持久化（必须在存储层初始化之后调用）
+func NewBattleRecorder() *BattleRecorder {
+	return &BattleRecorder{
+		recordRepo: repository.NewGameRecordRepository(),
+		rewardRepo: repository.NewRewardRepository(),
+		calculator: NewRewardCalculator(),
+	}
+}
+
+// InitBattleRecorder 创建战斗结算持久化并注册到战斗模块
+func InitBattleRecorder() *BattleRecorder {
+	recorder := NewBattleRecorder()
+	game.SetBattleResultHandler(recorder)
+	utils.Info("战斗结算持久化初始化完成")
+	return recorder
+}
+
+// OnBattleFinished 战斗结束回调，返回各玩家的奖励
+func (br *BattleRecorder) OnBattleFinished(result *game.BattleResult) map[string]*game.BattleReward {
+	if err := br.saveRecord(result); err != nil {
+		utils.Error("保存战斗记录失败: %s, %v", result.BattleID, err)
+	}
+
+	rewards := make(map[string]*game.BattleReward, len(result.Players))
+	for _, p := range result.Players {
+		reward, err := br.settlePlayer(result, p)
+		if err != nil {
+			utils.Error("玩家战斗结算失败: %s, 战斗: %s, %v", p.PlayerID, result.BattleID, err)
+			continue
+		}
+		rewards[p.PlayerID] = reward
+	}
+	return rewards
+}
+
+// settlePlayer 结算单个玩家：更新战斗统计、发放金币、经验（含升级通知）和掉落道具，写入奖励记录
+// 已结算过的玩家直接返回已发放的奖励
+func (br *BattleRecorder) settlePlayer(result *game.BattleResult, p game.PlayerResult) (*game.BattleReward, error) {
+	if existing, err := br.rewardRepo.Get(result.BattleID, p.PlayerID); err == nil {
+		utils.Warn("战斗 %s 已结算过玩家 %s，跳过", result.BattleID, p.PlayerID)
+		return toBattleReward(existing), nil
+	}
+
+	reward := br.calculator.Calculate(result, p)
+	record := &repository.BattleRewardData{
+		BattleID:   result.BattleID,
+		PlayerID:   p.PlayerID,
+		LevelID:    result.LevelID,
+		IsWin:      result.IsVictory,
+		Coin:       reward.Coin,
+		Exp:        reward.Exp,
+		Items:      make([]repository.RewardItemData, 0, len(reward.Items)),
+		CreateTime: time.Now(),
+	}
+	for _, item := range reward.Items {
+		record.Items = append(record.Items, repository.RewardItemData{
+			ItemID:    item.ItemID,
+			ItemCount: item.ItemCount,
+			ItemName:  item.ItemName,
+		})
+	}
+
+	var levelUp *LevelUpResult
+	applied, err := br.rewardRepo.SettleBattle(record, func(player *repository.PlayerData, inventory *repository.InventoryData) error {
+		if err := addItems(inventory, reward.Items); err != nil {
+			return err
+		}
+		player.ApplyBattle(result.IsVictory, p.Kills, result.WaveNum)
+		levelUp = ApplyExp(player, reward.Exp)
+		record.LevelBefore = levelUp.OldLevel
+		record.LevelAfter = levelUp.NewLevel
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !applied {
+		utils.Warn("战斗 %s 已结算过玩家 %s，跳过", result.BattleID, p.PlayerID)
+		if existing, err := br.rewardRepo.Get(result.BattleID, p.PlayerID); err == nil {
+			return toBattleReward(existing), nil
+		}
+		return nil, nil
+	}
+
+	for _, item := range reward.Items {
+		logItemChange(p.PlayerID, item.ItemID, item.ItemCount, ItemReasonBattleReward)
+	}
+	OnLevelUp(p.PlayerID, levelUp)
+	return reward, nil
+}
+
+// toBattleReward 奖励记录转换为通知中的奖励
+func toBattleReward(record *repository.BattleRewardData) *game.BattleReward {
+	reward := &game.BattleReward{
+		Coin:  record.Coin,
+		Exp:   record.Exp,
+		Items: make([]game.ItemReward, 0, len(record.Items)),
+	}
+	for _, item := range record.Items {
+		reward.Items = append(reward.Items, game.ItemReward{
+			ItemID:    item.ItemID,
+			ItemCount: item.ItemCount,
+			ItemName:  item.ItemName,
+		})
+	}
+	return reward
+}
+
+// saveRecord 写入战斗记录（记录ID即战斗ID，重复结算时不覆盖已有记录）
+func (br *BattleRecorder) saveRecord(result *game.BattleResult) error {
+	exists, err := br.recordRepo.Exists(result.BattleID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	playerIDs := make([]string, 0, len(result.Players))
+	for _, p := range result.Players {
+		playerIDs = append(playerIDs, p.PlayerID)
+	}
+
+	commands := make([]repository.BattleCommandRecord, 0, len(result.Commands))
+	for _, c := range result.Commands {
+		commands = append(commands, repository.BattleCommandRecord{
+			Frame:      c.Frame,
+			PlayerID:   c.PlayerID,
+			Op:         c.Op,
+			TowerID:    c.TowerID,
+			TowerType:  c.TowerType,
+			PosX:       c.PosX,
+			PosY:       c.PosY,
+			PosZ:       c.PosZ,
+			TargetMode: c.TargetMode,
+			SkillID:    c.SkillID,
+		})
+	}
+
+	return br.recordRepo.Save(&repository.GameRecordData{
+		RecordID:   result.BattleID,
+		RoomID:     result.RoomID,
+		RoomName:   result.RoomName,
+		LevelID:    result.LevelID,
+		PlayerIDs:  playerIDs,
+		IsWin:      result.IsVictory,
+		WaveCount:  result.WaveNum,
+		Duration:   result.Duration(),
+		TotalKills: result.TotalKills(),
+		StartTime:  result.StartTime,
+		EndTime:    result.EndTime,
+		Seed:       result.Seed,
+		Commands:   commands,
+	})
+}
@@ -0,0 +1,117 @@
+package logic
+
+import (
+	"errors"
+	"strconv"
+	"time"
+	"towerdefense/config"
+	"towerdefense/repository"
+	"towerdefense/utils"
+)
+
+// ErrCosmeticLocked 外观未解锁
+var ErrCosmeticLocked = errors.New("外观未解锁")
+
+// CosmeticService 外观服务：记录头像、头像框的解锁并校验使用权
+type CosmeticService struct {
+	repo       *repository.CosmeticRepository
+	playerRepo *repository.PlayerRepository
+}
+
+var cosmeticService *CosmeticService
+
+// InitCosmeticService 创建外观服务并注册等级解锁回调（必须在存储层初始化之后调用）
+func InitCosmeticService() *CosmeticService {
+	cosmeticService = &CosmeticService{
+		repo:       repository.NewCosmeticRepository(),
+		playerRepo: repository.NewPlayerRepository(),
+	}
+	for _, cosmeticType := range []string{config.UnlockTypeIcon, config.UnlockTypeFrame} {
+		RegisterUnlockHook(cosmeticType, cosmeticService.onLevelUnlock)
+	}
+	utils.Info("外观服务初始化完成")
+	return cosmeticService
+}
+
+// GetCosmeticService 获取外观服务
+func GetCosmeticService() *CosmeticService {
+	return cosmeticService
+}
+
+// Unlock 给玩家解锁外观（来源：repository.CosmeticSource*），已解锁时不做修改
+func (cs *CosmeticService) Unlock(playerID, cosmeticType string, id int, source, sourceRef string) error {
+	added, err := cs.repo.Unlock(playerID, repository.CosmeticUnlock{
+		Type:       cosmeticType,
+		ID:         id,
+		Source:     source,
+		SourceRef:  sourceRef,
+		UnlockTime: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	if added {
+		utils.Info("玩家 %s 解锁外观: %s %d, 来源 %s", playerID, cosmeticType, id, source)
+	}
+	return nil
+}
+
+// Owns 玩家是否拥有外观
+// 等级已达到但没有解锁记录的（如功能上线前已升级、或调整了等级配置）视为已拥有并补写记录
+func (cs *CosmeticService) Owns(playerID, cosmeticType string, id int) (bool, error) {
+	cosmetics, err := cs.repo.Get(playerID)
+	if err != nil {
+		return false, err
+	}
+	if cosmetics.Has(cosmeticType, id) {
+		return true, nil
+	}
+
+	required, ok := config.PlayerLevel.UnlockLevel(cosmeticType, id)
+	if !ok {
+		return false, nil
+	}
+	player, err := cs.playerRepo.Get(playerID)
+	if err != nil {
+		return false, err
+	}
+	if player.Level < required {
+		return false, nil
+	}
+	if err := cs.Unlock(playerID, cosmeticType, id, repository.CosmeticSourceLevel, strconv.Itoa(required)); err != nil {
+		utils.Warn("补写外观解锁记录失败: %s, %s %d, %v", playerID, cosmeticType, id, err)
+	}
+	return true, nil
+}
+
+// SetIcon 修改头像（未解锁时返回 ErrCosmeticLocked）
+func (cs *CosmeticService) SetIcon(playerID string, iconID int) error {
+	owned, err := cs.Owns(playerID, repository.CosmeticTypeIcon, iconID)
+	if err != nil {
+		return err
+	}
+	if !owned {
+		return ErrCosmeticLocked
+	}
+	return cs.playerRepo.UpdatePlayerIcon(playerID, iconID)
+}
+
+// SetFrame 修改头像框（未解锁时返回 ErrCosmeticLocked）
+func (cs *CosmeticService) SetFrame(playerID string, frameID int) error {
+	owned, err := cs.Owns(playerID, repository.CosmeticTypeFrame, frameID)
+	if err != nil {
+		return err
+	}
+	if !owned {
+		return ErrCosmeticLocked
+	}
+	return cs.playerRepo.UpdatePlayerFrame(playerID, frameID)
+}
+
+// onLevelUnlock 等级解锁回调：记录等级解锁的头像和头像框
+func (cs *CosmeticService) onLevelUnlock(playerID string, unlock config.PlayerUnlock) {
+	level, _ := config.PlayerLevel.UnlockLevel(unlock.Type, unlock.ID)
+	if err := cs.Unlock(playerID, unlock.Type, unlock.ID, repository.CosmeticSourceLevel, strconv.Itoa(level)); err != nil {
+		utils.Error("记录等级解锁外观失败: %s, %s %d, %v", playerID, unlock.Type, unlock.ID, err)
+	}
+}
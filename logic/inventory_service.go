@@ -0,0 +1,189 @@
+package logic
+
+import (
+	"errors"
+	"time"
+	"towerdefense/config"
+	"towerdefense/game"
+	"towerdefense/repository"
+	"towerdefense/utils"
+)
+
+// 道具变更原因
+const (
+	ItemReasonBattleReward = "battle_reward" // 战斗掉落
+	ItemReasonUse          = "use_item"      // 玩家使用
+	ItemReasonGM           = "gm"            // GM 操作
+)
+
+var (
+	ErrItemUndefined = errors.New("道具不存在")
+	ErrItemNotEnough = errors.New("道具数量不足")
+	ErrItemNotUsable = errors.New("道具不可使用")
+)
+
+// InventoryService 背包服务（实现 game.BattleStartHandler，战斗开始时发放已使用道具的加成）
+type InventoryService struct {
+	repo *repository.InventoryRepository
+}
+
+var inventoryService *InventoryService
+
+// InitInventoryService 创建背包服务并注册到战斗模块（必须在存储层初始化之后调用）
+func InitInventoryService() *InventoryService {
+	inventoryService = &InventoryService{
+		repo: repository.NewInventoryRepository(),
+	}
+	game.SetBattleStartHandler(inventoryService)
+	utils.Info("背包服务初始化完成")
+	return inventoryService
+}
+
+// GetInventoryService 获取背包服务
+func GetInventoryService() *InventoryService {
+	return inventoryService
+}
+
+// GetInventory 获取玩家背包（不含已过期道具）
+func (is *InventoryService) GetInventory(playerID string) (*repository.InventoryData, error) {
+	inventory, err := is.repo.Get(playerID)
+	if err != nil {
+		return nil, err
+	}
+	inventory.RemoveExpired(time.Now())
+	return inventory, nil
+}
+
+// AddItem 给玩家发放道具
+func (is *InventoryService) AddItem(playerID string, itemID, count int, reason string) error {
+	if count <= 0 {
+		return nil
+	}
+	err := is.repo.Update(playerID, func(inventory *repository.InventoryData) error {
+		return addItems(inventory, []game.ItemReward{{ItemID: itemID, ItemCount: count}})
+	})
+	if err != nil {
+		return err
+	}
+	logItemChange(playerID, itemID, count, reason)
+	return nil
+}
+
+// ConsumeItem 扣除玩家道具，返回剩余数量
+func (is *InventoryService) ConsumeItem(playerID string, itemID, count int, reason string) (int, error) {
+	if _, ok := config.Items.GetItem(itemID); !ok {
+		return 0, ErrItemUndefined
+	}
+
+	remain := 0
+	err := is.repo.Update(playerID, func(inventory *repository.InventoryData) error {
+		now := time.Now()
+		removeExpired(inventory, now)
+		if !inventory.RemoveItem(itemID, count, now) {
+			return ErrItemNotEnough
+		}
+		remain = inventory.CountItem(itemID, now)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	logItemChange(playerID, itemID, -count, reason)
+	return remain, nil
+}
+
+// UseItem 使用消耗品：扣除道具并登记效果（效果在之后的战斗中生效），返回剩余数量
+func (is *InventoryService) UseItem(playerID string, itemID, count int) (int, error) {
+	def, ok := config.Items.GetItem(itemID)
+	if !ok {
+		return 0, ErrItemUndefined
+	}
+	if def.Effect == nil {
+		return 0, ErrItemNotUsable
+	}
+	if count <= 0 {
+		count = 1
+	}
+
+	remain := 0
+	err := is.repo.Update(playerID, func(inventory *repository.InventoryData) error {
+		now := time.Now()
+		removeExpired(inventory, now)
+		if !inventory.RemoveItem(itemID, count, now) {
+			return ErrItemNotEnough
+		}
+		inventory.AddEffect(repository.ActiveEffect{
+			Type:         def.Effect.Type,
+			Value:        def.Effect.Value * count,
+			ItemID:       itemID,
+			ActivateTime: now,
+		})
+		remain = inventory.CountItem(itemID, now)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	logItemChange(playerID, itemID, -count, ItemReasonUse)
+	return remain, nil
+}
+
+// OnBattleStart 战斗开始回调：取出各玩家待生效的初始金币加成
+func (is *InventoryService) OnBattleStart(battleID string, playerIDs []string) map[string]int {
+	bonuses := make(map[string]int)
+	for _, playerID := range playerIDs {
+		inventory, err := is.repo.Get(playerID)
+		if err != nil {
+			utils.Error("读取背包失败: %s, %v", playerID, err)
+			continue
+		}
+		if inventory.EffectValue(config.ItemEffectStartGold) == 0 {
+			continue
+		}
+
+		gold := 0
+		err = is.repo.Update(playerID, func(inventory *repository.InventoryData) error {
+			gold = inventory.TakeEffects(config.ItemEffectStartGold)
+			return nil
+		})
+		if err != nil {
+			utils.Error("发放初始金币加成失败: %s, 战斗: %s, %v", playerID, battleID, err)
+			continue
+		}
+		bonuses[playerID] = gold
+	}
+	return bonuses
+}
+
+// addItems 按道具配置放入背包（道具未定义时不做修改并返回错误）
+func addItems(inventory *repository.InventoryData, items []game.ItemReward) error {
+	for _, item := range items {
+		if _, ok := config.Items.GetItem(item.ItemID); !ok {
+			return ErrItemUndefined
+		}
+	}
+
+	now := time.Now()
+	removeExpired(inventory, now)
+	for _, item := range items {
+		def, _ := config.Items.GetItem(item.ItemID)
+		var expireTime time.Time
+		if def.ExpireSeconds > 0 {
+			expireTime = now.Add(time.Duration(def.ExpireSeconds) * time.Second)
+		}
+		inventory.AddItem(item.ItemID, item.ItemCount, def.MaxStack, expireTime, now)
+	}
+	return nil
+}
+
+// removeExpired 清理过期道具并记录日志
+func removeExpired(inventory *repository.InventoryData, now time.Time) {
+	for _, item := range inventory.RemoveExpired(now) {
+		utils.Info("道具过期: 玩家 %s, 道具 %d, 数量 %d", inventory.PlayerID, item.ItemID, item.Count)
+	}
+}
+
+// logItemChange 记录道具变更日志
+func logItemChange(playerID string, itemID, delta int, reason string) {
+	utils.Info("道具变更: 玩家 %s, 道具 %d, 数量 %+d, 原因 %s", playerID, itemID, delta, reason)
+}
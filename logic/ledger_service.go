@@ -0,0 +1,51 @@
+package logic
+
+import (
+	"fmt"
+	"towerdefense/repository"
+	"towerdefense/utils"
+)
+
+// LedgerService 货币服务：金币、钻石的所有变更都经过这里并写入流水
+type LedgerService struct {
+	repo *repository.LedgerRepository
+}
+
+var ledgerService *LedgerService
+
+// InitLedgerService 创建货币服务，并为流水索引上线前写入的流水补建索引（必须在存储层初始化之后调用）
+func InitLedgerService() (*LedgerService, error) {
+	ledgerService = &LedgerService{
+		repo: repository.NewLedgerRepository(),
+	}
+	indexed, err := ledgerService.repo.IndexExisting()
+	if err != nil {
+		return nil, fmt.Errorf("补建货币流水索引失败: %v", err)
+	}
+	if indexed > 0 {
+		utils.Info("已为 %d 个玩家补建货币流水索引", indexed)
+	}
+	utils.Info("货币服务初始化完成")
+	return ledgerService, nil
+}
+
+// GetLedgerService 获取货币服务
+func GetLedgerService() *LedgerService {
+	return ledgerService
+}
+
+// Change 修改玩家货币，返回变更后的余额（余额不足时返回 repository.ErrInsufficientBalance）
+func (ls *LedgerService) Change(playerID, currency string, delta int, reason, sourceRef string) (int, error) {
+	entry, err := ls.repo.ChangeCurrency(playerID, currency, delta, reason, sourceRef)
+	if err != nil {
+		return 0, err
+	}
+	utils.Info("货币变更: 玩家 %s, %s %+d, 余额 %d, 原因 %s, 来源 %s",
+		playerID, currency, delta, entry.BalanceAfter, reason, sourceRef)
+	return entry.BalanceAfter, nil
+}
+
+// History 查询玩家的货币流水（按时间倒序）
+func (ls *LedgerService) History(playerID, currency string, limit int) ([]*repository.LedgerEntry, error) {
+	return ls.repo.GetHistory(playerID, currency, limit)
+}
@@ -0,0 +1,138 @@
+package logic
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+	"towerdefense/config"
+	"towerdefense/repository"
+	"towerdefense/storage"
+	"towerdefense/utils"
+)
+
+var (
+	ErrNameLength    = errors.New("名称长度不符合要求")
+	ErrNameIllegal   = errors.New("名称包含非法字符")
+	ErrNameSensitive = errors.New("名称包含敏感词")
+	ErrNameUnchanged = errors.New("新名称与当前名称相同")
+	ErrRenameCooling = errors.New("改名冷却中")
+)
+
+// NameService 玩家名称服务：名称规则校验、全服唯一、改名费用与冷却
+type NameService struct {
+	repo       *repository.NameRepository
+	playerRepo *repository.PlayerRepository
+}
+
+var nameService *NameService
+
+// InitNameService 创建名称服务：加载敏感词库，并为还没有名称索引的玩家补建索引（必须在存储层初始化之后调用）
+func InitNameService() (*NameService, error) {
+	if path := config.Name.SensitiveWordFile; path != "" {
+		if err := utils.InitWordFilter(path); err != nil {
+			return nil, fmt.Errorf("加载敏感词库失败: %v", err)
+		}
+	}
+
+	nameService = &NameService{
+		repo:       repository.NewNameRepository(),
+		playerRepo: repository.NewPlayerRepository(),
+	}
+	if err := nameService.indexExistingPlayers(); err != nil {
+		return nil, err
+	}
+	utils.Info("名称服务初始化完成")
+	return nameService, nil
+}
+
+// GetNameService 获取名称服务
+func GetNameService() *NameService {
+	return nameService
+}
+
+// ValidateName 校验名称格式：长度（按字符计）、非法字符和敏感词
+func ValidateName(name string) error {
+	length := utf8.RuneCountInString(name)
+	if length < config.Name.MinLength || length > config.Name.MaxLength {
+		return fmt.Errorf("%w: %d~%d 个字符", ErrNameLength, config.Name.MinLength, config.Name.MaxLength)
+	}
+	for _, r := range name {
+		if unicode.IsSpace(r) || unicode.IsControl(r) || !unicode.IsPrint(r) {
+			return ErrNameIllegal
+		}
+	}
+	if utils.GetWordFilter().Contains(name) {
+		return ErrNameSensitive
+	}
+	return nil
+}
+
+// RenameCost 玩家下一次改名的钻石费用（首次改名免费）
+func RenameCost(player *repository.PlayerData) int {
+	if player.RenameCount == 0 {
+		return 0
+	}
+	return config.Name.RenameCost
+}
+
+// Rename 修改玩家名称，返回本次消耗的钻石
+// 名称被占用返回 repository.ErrNameTaken，钻石不足返回 repository.ErrInsufficientBalance
+func (ns *NameService) Rename(playerID, newName string) (int, error) {
+	newName = strings.TrimSpace(newName)
+	if err := ValidateName(newName); err != nil {
+		return 0, err
+	}
+
+	cost := 0
+	err := ns.repo.Rename(playerID, newName, func(player *repository.PlayerData) (*repository.LedgerEntry, error) {
+		if player.PlayerName == newName {
+			return nil, ErrNameUnchanged
+		}
+		now := time.Now()
+		cooldown := time.Duration(config.Name.RenameCooldown) * time.Hour
+		if player.RenameCount > 0 && now.Sub(player.RenameTime) < cooldown {
+			remain := cooldown - now.Sub(player.RenameTime)
+			return nil, fmt.Errorf("%w: 还需等待 %d 分钟", ErrRenameCooling, int(remain.Minutes())+1)
+		}
+
+		cost = RenameCost(player)
+		player.RenameCount++
+		player.RenameTime = now
+		if cost == 0 {
+			return nil, nil
+		}
+		return player.ChangeCurrency(repository.CurrencyDiamond, -cost, repository.LedgerReasonRename, newName)
+	})
+	if err != nil {
+		return 0, err
+	}
+	utils.Info("玩家 %s 改名为 %s，消耗钻石 %d", playerID, newName, cost)
+	return cost, nil
+}
+
+// indexExistingPlayers 为名称索引上线前创建的玩家补建索引，重名的玩家保留原名并记录警告
+func (ns *NameService) indexExistingPlayers() error {
+	keys, err := storage.GetStorage().Keys(repository.TablePlayer)
+	if err != nil {
+		return err
+	}
+
+	for _, playerID := range keys {
+		player, err := ns.playerRepo.Get(playerID)
+		if err != nil {
+			return err
+		}
+		err = ns.repo.IndexExisting(player)
+		if errors.Is(err, repository.ErrNameTaken) {
+			utils.Warn("玩家 %s 的名称 %s 与其他玩家重复，未建立索引", playerID, player.PlayerName)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
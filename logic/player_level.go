@@ -0,0 +1,114 @@
+package logic
+
+import (
+	"sync"
+	"towerdefense/config"
+	"towerdefense/game"
+	"towerdefense/repository"
+	"towerdefense/utils"
+)
+
+// LevelUpResult 经验增加结果
+type LevelUpResult struct {
+	OldLevel int
+	NewLevel int
+	Exp      int // 当前等级经验
+	Unlocks  []config.PlayerUnlock
+}
+
+// LeveledUp 是否升级
+func (r *LevelUpResult) LeveledUp() bool {
+	return r.NewLevel > r.OldLevel
+}
+
+// UnlockHook 解锁回调（玩家达到解锁等级时调用）
+type UnlockHook func(playerID string, unlock config.PlayerUnlock)
+
+var (
+	unlockHooks   = make(map[string][]UnlockHook)
+	unlockHooksMu sync.RWMutex
+)
+
+// RegisterUnlockHook 注册某类解锁内容的回调
+func RegisterUnlockHook(unlockType string, hook UnlockHook) {
+	unlockHooksMu.Lock()
+	defer unlockHooksMu.Unlock()
+	unlockHooks[unlockType] = append(unlockHooks[unlockType], hook)
+}
+
+// AddExp 增加经验并处理升级（支持连续升级，满级后经验清零），返回新的等级和经验
+func AddExp(level, exp, gained int) (int, int) {
+	cfg := &config.PlayerLevel
+	if level <= 0 {
+		level = 1
+	}
+	if level >= cfg.MaxLevel {
+		return cfg.MaxLevel, 0
+	}
+	exp += gained
+	for level < cfg.MaxLevel && exp >= cfg.ExpToNextLevel(level) {
+		exp -= cfg.ExpToNextLevel(level)
+		level++
+	}
+	if level >= cfg.MaxLevel {
+		exp = 0
+	}
+	return level, exp
+}
+
+// ApplyExp 给玩家数据增加经验，返回升级结果（不保存）
+func ApplyExp(player *repository.PlayerData, gained int) *LevelUpResult {
+	result := &LevelUpResult{OldLevel: player.Level}
+	player.Level, player.Exp = AddExp(player.Level, player.Exp, gained)
+	result.NewLevel = player.Level
+	result.Exp = player.Exp
+	for level := result.OldLevel + 1; level <= result.NewLevel; level++ {
+		result.Unlocks = append(result.Unlocks, config.PlayerLevel.GetUnlocks(level)...)
+	}
+	return result
+}
+
+// OnLevelUp 升级后处理：触发解锁回调并推送升级通知（未升级时不做任何事）
+func OnLevelUp(playerID string, result *LevelUpResult) {
+	if result == nil || !result.LeveledUp() {
+		return
+	}
+	utils.Info("玩家 %s 升级: %d -> %d", playerID, result.OldLevel, result.NewLevel)
+
+	unlockHooksMu.RLock()
+	for _, unlock := range result.Unlocks {
+		for _, hook := range unlockHooks[unlock.Type] {
+			hook(playerID, unlock)
+		}
+	}
+	unlockHooksMu.RUnlock()
+
+	broadcaster := game.GetMessageBroadcaster()
+	if broadcaster == nil {
+		return
+	}
+	notify := &game.LevelUpNotify{
+		OldLevel:     result.OldLevel,
+		NewLevel:     result.NewLevel,
+		Exp:          result.Exp,
+		NextLevelExp: config.PlayerLevel.ExpToNextLevel(result.NewLevel),
+		Unlocks:      make([]game.UnlockInfo, 0, len(result.Unlocks)),
+	}
+	for _, unlock := range result.Unlocks {
+		notify.Unlocks = append(notify.Unlocks, game.UnlockInfo{
+			Type: unlock.Type,
+			ID:   unlock.ID,
+			Name: unlock.Name,
+		})
+	}
+	broadcaster.BroadcastToPlayer(playerID, game.MsgTypeLevelUpNtf, notify)
+}
+
+// IsUnlocked 检查玩家等级是否已解锁某内容（未配置解锁条件的内容默认已解锁）
+func IsUnlocked(level int, unlockType string, id int) bool {
+	required, ok := config.PlayerLevel.UnlockLevel(unlockType, id)
+	if !ok {
+		return true
+	}
+	return level >= required
+}
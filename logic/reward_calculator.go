@@ -0,0 +1,74 @@
+package logic
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+	"towerdefense/config"
+	"towerdefense/game"
+)
+
+// RewardCalculator 战斗奖励计算器
+// 奖励参数按关卡ID从奖励配置读取，由胜负、通过波次、击杀数和个人得分决定
+type RewardCalculator struct {
+	rng *rand.Rand
+	mu  sync.Mutex
+}
+
+// NewRewardCalculator 创建奖励计算器
+func NewRewardCalculator() *RewardCalculator {
+	return &RewardCalculator{
+		rng: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Calculate 计算玩家的战斗奖励（道具掉落为随机结果，每场战斗只应计算一次）
+func (rc *RewardCalculator) Calculate(result *game.BattleResult, player game.PlayerResult) *game.BattleReward {
+	cfg := config.Reward.GetLevelReward(result.LevelID)
+
+	coin := cfg.BaseCoin + result.WaveNum*cfg.CoinPerWave + player.Kills*cfg.CoinPerKill + int(float64(player.Score)*cfg.ScoreCoinRate)
+	exp := cfg.BaseExp + result.WaveNum*cfg.ExpPerWave
+	if result.IsVictory {
+		coin += cfg.VictoryCoin
+		exp += cfg.VictoryExp
+	} else {
+		coin = int(float64(coin) * cfg.DefeatRate)
+		exp = int(float64(exp) * cfg.DefeatRate)
+	}
+
+	return &game.BattleReward{
+		Coin:  coin,
+		Exp:   exp,
+		Items: rc.rollDrops(cfg.DropTable, result.IsVictory),
+	}
+}
+
+// rollDrops 按掉落表判定道具掉落
+func (rc *RewardCalculator) rollDrops(table string, isVictory bool) []game.ItemReward {
+	items := make([]game.ItemReward, 0)
+	if table == "" {
+		return items
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	for _, entry := range config.Reward.DropTables[table] {
+		if entry.VictoryOnly && !isVictory {
+			continue
+		}
+		if rc.rng.Float64() >= entry.Chance {
+			continue
+		}
+		count := entry.MinCount
+		if entry.MaxCount > entry.MinCount {
+			count += rc.rng.Intn(entry.MaxCount - entry.MinCount + 1)
+		}
+		items = append(items, game.ItemReward{
+			ItemID:    entry.ItemID,
+			ItemCount: count,
+			ItemName:  entry.ItemName,
+		})
+	}
+	return items
+}
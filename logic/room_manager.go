@@ -1,11 +1,22 @@
 package logic
 
 import (
+	"errors"
+	"fmt"
 	"sync"
+	"towerdefense/config"
 	"towerdefense/game"
+	"towerdefense/repository"
 	"towerdefense/utils"
 )
 
+var (
+	ErrRoomNotFound    = errors.New("房间不存在")
+	ErrNotRoomHost     = errors.New("只有房主可以开始游戏")
+	ErrPlayersNotReady = errors.New("还有玩家未准备")
+	ErrLevelLocked     = errors.New("关卡未解锁")
+)
+
 // RoomManager 房间管理器
 type RoomManager struct {
 	rooms map[string]*game.Room
@@ -62,6 +73,63 @@ func (rm *RoomManager) RemoveRoom(roomID string) {
 	}
 }
 
+// StartGame 房主开始游戏：按房间关卡创建战斗并加入战斗管理器
+// 返回的战斗尚未启动，调用方发送开始游戏响应后调用 Battle.Start
+// 关卡未配置返回 game.ErrUnknownLevel，有玩家等级未解锁关卡返回 ErrLevelLocked，房间已开始返回 game.ErrRoomNotWaiting
+func (rm *RoomManager) StartGame(roomID, playerID string) (*game.Battle, error) {
+	room := rm.GetRoom(roomID)
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+	if room.GetHostID() != playerID {
+		return nil, ErrNotRoomHost
+	}
+	if !room.AllReady() {
+		return nil, ErrPlayersNotReady
+	}
+	if err := checkLevelUnlocked(room); err != nil {
+		return nil, err
+	}
+	
+	battle, err := room.StartGame()
+	if err != nil {
+		return nil, err
+	}
+	battle.SetFinishedCallback(func(battle *game.Battle) {
+		rm.onBattleFinished(room, battle)
+	})
+	GetBattleManager().AddBattle(battle)
+	return battle, nil
+}
+
+// onBattleFinished 战斗结算完成：从战斗管理器移除战斗，房间恢复等待状态，房间已无人时移除房间
+func (rm *RoomManager) onBattleFinished(room *game.Room, battle *game.Battle) {
+	GetBattleManager().RemoveBattle(battle.ID)
+	room.EndGame(battle)
+	if room.GetPlayerCount() == 0 {
+		rm.RemoveRoom(room.ID)
+	}
+}
+
+// checkLevelUnlocked 检查房间内所有玩家的等级都已解锁房间关卡
+func checkLevelUnlocked(room *game.Room) error {
+	required, ok := config.PlayerLevel.UnlockLevel(config.UnlockTypeLevel, room.LevelID)
+	if !ok || required <= 1 {
+		return nil
+	}
+	playerRepo := repository.NewPlayerRepository()
+	for _, p := range room.GetPlayers() {
+		player, err := playerRepo.Get(p.ID)
+		if err != nil {
+			return err
+		}
+		if !IsUnlocked(player.Level, config.UnlockTypeLevel, room.LevelID) {
+			return fmt.Errorf("%w: 玩家 %s 需要 %d 级", ErrLevelLocked, p.Name, required)
+		}
+	}
+	return nil
+}
+
 // GetAllRooms 获取所有房间
 func (rm *RoomManager) GetAllRooms() []*game.Room {
 	rm.mu.RLock()
@@ -87,6 +155,19 @@ func (rm *RoomManager) GetRoomByPlayerID(playerID string) *game.Room {
 	return nil
 }
 
+// GetPlayerBattle 查找玩家所在房间正在进行的战斗，不在战斗中返回 game.ErrNotInBattle
+func (rm *RoomManager) GetPlayerBattle(playerID string) (*game.Battle, error) {
+	room := rm.GetRoomByPlayerID(playerID)
+	if room == nil {
+		return nil, game.ErrNotInBattle
+	}
+	battle := room.GetBattle()
+	if battle == nil {
+		return nil, game.ErrNotInBattle
+	}
+	return battle, nil
+}
+
 // CleanEmptyRooms 清理空房间
 func (rm *RoomManager) CleanEmptyRooms() {
 	rm.mu.Lock()
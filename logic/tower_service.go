@@ -0,0 +1,94 @@
+package logic
+
+import (
+	"errors"
+	"fmt"
+	"towerdefense/config"
+	"towerdefense/game"
+	"towerdefense/repository"
+	"towerdefense/utils"
+)
+
+// ErrTowerLocked 玩家等级未达到防御塔的解锁等级
+var ErrTowerLocked = errors.New("防御塔未解锁")
+
+// TowerService 防御塔服务：查找玩家所在的战斗，校验解锁条件后执行建造、升级和出售
+type TowerService struct {
+	playerRepo *repository.PlayerRepository
+}
+
+var towerService *TowerService
+
+// InitTowerService 创建防御塔服务（必须在存储层初始化之后调用）
+func InitTowerService() *TowerService {
+	towerService = &TowerService{
+		playerRepo: repository.NewPlayerRepository(),
+	}
+	utils.Info("防御塔服务初始化完成")
+	return towerService
+}
+
+// GetTowerService 获取防御塔服务
+func GetTowerService() *TowerService {
+	return towerService
+}
+
+// PlaceTower 在玩家当前战斗中建造防御塔，返回防御塔状态和剩余金币
+// 类型未配置返回 game.ErrUnknownTowerType，等级不足返回 ErrTowerLocked
+func (ts *TowerService) PlaceTower(playerID string, towerType int, pos game.Vector3) (game.TowerState, int, error) {
+	def, ok := config.Towers.GetTower(towerType)
+	if !ok {
+		return game.TowerState{}, 0, fmt.Errorf("%w: %d", game.ErrUnknownTowerType, towerType)
+	}
+	if def.UnlockLevel > 1 {
+		player, err := ts.playerRepo.Get(playerID)
+		if err != nil {
+			return game.TowerState{}, 0, err
+		}
+		if player.Level < def.UnlockLevel {
+			return game.TowerState{}, 0, fmt.Errorf("%w: %s 需要 %d 级", ErrTowerLocked, def.Name, def.UnlockLevel)
+		}
+	}
+
+	battle, err := ts.findBattle(playerID)
+	if err != nil {
+		return game.TowerState{}, 0, err
+	}
+	return battle.PlaceTower(playerID, towerType, pos)
+}
+
+// UpgradeTower 升级玩家的防御塔，返回防御塔状态、花费和剩余金币
+func (ts *TowerService) UpgradeTower(playerID, towerID string) (game.TowerState, int, int, error) {
+	battle, err := ts.findBattle(playerID)
+	if err != nil {
+		return game.TowerState{}, 0, 0, err
+	}
+	return battle.UpgradeTower(playerID, towerID)
+}
+
+// SellTower 出售玩家的防御塔，返回返还金币和剩余金币
+func (ts *TowerService) SellTower(playerID, towerID string) (int, int, error) {
+	battle, err := ts.findBattle(playerID)
+	if err != nil {
+		return 0, 0, err
+	}
+	return battle.SellTower(playerID, towerID)
+}
+
+// SetTargetMode 设置玩家防御塔的目标选择模式，模式无效返回 game.ErrInvalidTargetMode
+func (ts *TowerService) SetTargetMode(playerID, towerID string, mode int) (game.TowerState, error) {
+	targetMode, err := game.ParseTargetMode(mode)
+	if err != nil {
+		return game.TowerState{}, err
+	}
+	battle, err := ts.findBattle(playerID)
+	if err != nil {
+		return game.TowerState{}, err
+	}
+	return battle.SetTargetMode(playerID, towerID, targetMode)
+}
+
+// findBattle 查找玩家所在房间正在进行的战斗
+func (ts *TowerService) findBattle(playerID string) (*game.Battle, error) {
+	return GetRoomManager().GetPlayerBattle(playerID)
+}
@@ -1,12 +1,16 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"strconv"
+	"strings"
+	"syscall"
 	"towerdefense/account"
 	"towerdefense/config"
 	"towerdefense/gameserver"
@@ -17,10 +21,25 @@ import (
 )
 
 var (
-	serverType = flag.String("type", "account", "服务器类型: account(账号服) 或 game(游戏服)")
+	serverType = flag.String("type", "account", "服务器类型: account(账号服) 或 game(游戏服)；工具: migrate(存储迁移)、schema-migrate(数据结构升级)、backup(备份)、restore(恢复)")
 	serverID   = flag.Int("id", 1, "游戏服ID")
 	serverName = flag.String("name", "一区", "游戏服名称")
 	addr       = flag.String("addr", ":8080", "服务监听地址")
+	
+	// 存储迁移参数（-type=migrate）
+	migrateSrc        = flag.String("src", "config.json", "迁移源配置文件（读取其中 storage 段）")
+	migrateDst        = flag.String("dst", "", "迁移目标配置文件（读取其中 storage 段）")
+	migrateTables     = flag.String("tables", "", "要迁移的表，逗号分隔，为空表示全部")
+	migrateBatch      = flag.Int("batch", 100, "每批写入条数")
+	migrateDryRun     = flag.Bool("dry-run", false, "只统计不写入（migrate / schema-migrate）")
+	migrateCheckpoint = flag.String("checkpoint", "migrate.checkpoint", "断点文件，中断后重新执行从断点继续")
+	
+	// 备份恢复参数（-type=backup / -type=restore）
+	backupDir     = flag.String("backup-dir", "./backups", "备份文件目录")
+	backupKeep    = flag.Int("keep", 0, "备份后只保留最新的 N 份，0 表示不清理")
+	restoreFile   = flag.String("archive", "", "要恢复的备份文件")
+	restoreTable  = flag.String("restore-table", "", "只恢复指定表")
+	restorePlayer = flag.String("restore-player", "", "只恢复指定玩家的记录")
 )
 
 func main() {
@@ -31,6 +50,24 @@ func main() {
 	//加载配置
 	config.LoadConfig()
 	
+	// 工具命令使用各自指定的存储，不初始化全局存储
+	if *serverType == "migrate" {
+		runMigrate()
+		return
+	}
+	if *serverType == "schema-migrate" {
+		runSchemaMigrate()
+		return
+	}
+	if *serverType == "backup" {
+		runBackup()
+		return
+	}
+	if *serverType == "restore" {
+		runRestore()
+		return
+	}
+	
 	// 初始化存储层
 	err := storage.InitStorage(
 		storage.StorageType(config.Storage.Type),
@@ -41,8 +78,21 @@ func main() {
 	}
 	defer storage.CloseStorage()
 	
+	// 数据由更高版本的服务写入过，继续运行会丢失新字段，拒绝启动
+	if err := storage.CheckSchemaCompatibility(storage.GetStorage()); err != nil {
+		log.Fatal("数据结构版本检查失败: ", err)
+	}
+	
 	utils.Info("存储层初始化成功，类型: %s", config.Storage.Type)
 	
+	// 收到退出信号时关闭存储（写回缓存中的脏数据）
+	go handleShutdown()
+	
+	// 定时备份
+	if backupConfig := storage.ParseBackupConfig(config.Storage.Settings); backupConfig != nil {
+		storage.StartBackupScheduler(*backupConfig)
+	}
+	
 	// 
 	// 根据类型启动不同服务器
 	if *serverType == "account" {
@@ -50,8 +100,22 @@ func main() {
 	} else if *serverType == "game" {
 		startGameServer()
 	} else {
-		log.Fatal("未知的服务器类型，请使用 -type=account 或 -type=game")
+		log.Fatal("未知的服务器类型，请使用 -type=account、-type=game、-type=migrate、-type=schema-migrate、-type=backup 或 -type=restore")
+	}
+}
+
+// handleShutdown 等待退出信号，关闭存储后退出
+func handleShutdown() {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-sigChan
+	
+	utils.Info("收到退出信号 %v，正在关闭存储...", sig)
+	if err := storage.CloseStorage(); err != nil {
+		utils.Error("关闭存储失败: %v", err)
+		os.Exit(1)
 	}
+	os.Exit(0)
 }
 
 // startAccountServer 启动账号服务器
@@ -125,20 +189,62 @@ func startGameServer() {
 	
 	// 加载配置
 	config.LoadConfig()
+	if err := config.LoadRewardConfig("reward_config.json"); err != nil {
+		log.Fatal(err)
+	}
+	if err := config.LoadPlayerLevelConfig("player_level_config.json"); err != nil {
+		log.Fatal(err)
+	}
+	if err := config.LoadItemConfig("item_config.json"); err != nil {
+		log.Fatal(err)
+	}
+	if err := config.LoadBuffConfig("buff_config.json"); err != nil {
+		log.Fatal(err)
+	}
+	if err := config.LoadSkillConfig("skill_config.json"); err != nil {
+		log.Fatal(err)
+	}
+	if err := config.LoadTowerConfig("tower_config.json"); err != nil {
+		log.Fatal(err)
+	}
+	if err := config.LoadEnemyConfig("enemy_config.json"); err != nil {
+		log.Fatal(err)
+	}
+	if err := config.LoadLevelConfig("level_config"); err != nil {
+		log.Fatal(err)
+	}
 	
 	// 初始化游戏服务器
 	gameserver.InitGameServer(*serverID, *serverName, *addr, config.Server.MaxPlayers)
 	
+	// 初始化仓储
+	network.InitRepositories()
+	
 	// 初始化广播器（必须在管理器之前初始化）
 	network.InitGameBroadcaster()
 	
 	// 初始化管理器
 	logic.InitRoomManager()
 	logic.InitBattleManager()
+	logic.InitBattleRecorder()
+	logic.InitInventoryService()
+	if _, err := logic.InitLedgerService(); err != nil {
+		log.Fatal(err)
+	}
+	logic.InitCosmeticService()
+	logic.InitTowerService()
+	if _, err := logic.InitNameService(); err != nil {
+		log.Fatal(err)
+	}
 	
 	// 注册WebSocket路由
 	http.HandleFunc("/game/login", network.HandleWebSocket)
 	
+	// GM 接口（需配置 gm_token）
+	http.HandleFunc("/gm/ledger", network.HandleGetLedger)
+	http.HandleFunc("/gm/currency", network.HandleAdjustCurrency)
+	http.HandleFunc("/gm/backup", network.HandleBackup)
+	
 	// 服务器信息接口
 	http.HandleFunc("/info", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -163,3 +269,179 @@ func startGameServer() {
 		log.Fatal("游戏服启动失败: ", err)
 	}
 }
+
+// runMigrate 在两种存储之间迁移数据
+func runMigrate() {
+	utils.Info("=== 存储迁移 ===")
+	
+	if *migrateDst == "" {
+		log.Fatal("请使用 -dst 指定迁移目标配置文件")
+	}
+	
+	srcCfg, err := config.LoadStorageConfig(*migrateSrc)
+	if err != nil {
+		log.Fatal("读取迁移源配置失败: ", err)
+	}
+	dstCfg, err := config.LoadStorageConfig(*migrateDst)
+	if err != nil {
+		log.Fatal("读取迁移目标配置失败: ", err)
+	}
+	
+	src, err := openToolStorage(srcCfg.Type, srcCfg.Settings)
+	if err != nil {
+		log.Fatal("迁移源存储初始化失败: ", err)
+	}
+	defer src.Close()
+	
+	dst, err := openToolStorage(dstCfg.Type, dstCfg.Settings)
+	if err != nil {
+		log.Fatal("迁移目标存储初始化失败: ", err)
+	}
+	defer dst.Close()
+	
+	var tables []string
+	if *migrateTables != "" {
+		tables = strings.Split(*migrateTables, ",")
+	}
+	
+	utils.Info("迁移: %s(%s) -> %s(%s), dry-run: %v", *migrateSrc, srcCfg.Type, *migrateDst, dstCfg.Type, *migrateDryRun)
+	
+	results, err := storage.Migrate(src, dst, storage.MigrateOptions{
+		Tables:         tables,
+		BatchSize:      *migrateBatch,
+		DryRun:         *migrateDryRun,
+		CheckpointFile: *migrateCheckpoint,
+		Source:         storage.StorageFingerprint(storage.StorageType(srcCfg.Type), srcCfg.Settings),
+		Target:         storage.StorageFingerprint(storage.StorageType(dstCfg.Type), dstCfg.Settings),
+		Verify:         true,
+		Progress: func(table string, done, total int) {
+			utils.Info("  [%s] %d/%d", table, done, total)
+		},
+	})
+	
+	failed := false
+	for _, r := range results {
+		if *migrateDryRun {
+			utils.Info("表 %s: %d 条（dry-run，未写入）", r.Table, r.SourceCount)
+			continue
+		}
+		utils.Info("表 %s: 源 %d 条, 写入 %d 条, 断点跳过 %d 条, 临时数据 %d 条（不迁移）, 目标 %d 条, 校验: %v",
+			r.Table, r.SourceCount, r.Copied, r.Skipped, r.Excluded, r.TargetCount, r.Verified)
+		if !r.Verified {
+			utils.Error("表 %s 校验不一致: 源 %s, 目标 %s", r.Table, r.SourceChecksum, r.TargetChecksum)
+			failed = true
+		}
+	}
+	
+	if err != nil {
+		log.Fatal("迁移失败（可重新执行从断点继续）: ", err)
+	}
+	if failed {
+		log.Fatal("迁移完成但校验未通过（未通过的表已清除断点，重新执行会整表重新复制）")
+	}
+	
+	if !*migrateDryRun {
+		// 全部完成，清除断点，下次执行重新迁移
+		os.Remove(*migrateCheckpoint)
+	}
+	utils.Info("迁移完成")
+}
+
+// runSchemaMigrate 将当前存储中的旧结构数据批量升级到代码版本
+func runSchemaMigrate() {
+	utils.Info("=== 数据结构升级 ===")
+	
+	// 直接操作原始存储，逐条读取-升级-写回；须在服务停止后执行，服务运行时旧数据在读取时按需升级
+	s, err := openToolStorage(config.Storage.Type, config.Storage.Settings)
+	if err != nil {
+		log.Fatal("存储初始化失败: ", err)
+	}
+	defer s.Close()
+	
+	if err := storage.CheckSchemaCompatibility(s); err != nil {
+		log.Fatal("数据结构版本检查失败: ", err)
+	}
+	
+	results, err := storage.MigrateAllSchemas(s, *migrateDryRun)
+	if err != nil {
+		log.Fatal("数据结构升级失败: ", err)
+	}
+	
+	for _, r := range results {
+		if *migrateDryRun {
+			utils.Info("表 %s: 共 %d 条，待升级 %d 条 -> v%d（dry-run，未写入）", r.Table, r.Total, r.Migrated, r.Version)
+		}
+	}
+	utils.Info("数据结构升级完成")
+}
+
+// openToolStorage 为工具命令独占打开存储
+// TXT 数据目录正被账号服或游戏服使用时拒绝执行：工具的启动恢复和写入会与服务进程冲突，服务的读缓存也看不到工具写入的数据
+func openToolStorage(storageType string, settings map[string]interface{}) (storage.IStorage, error) {
+	exclusive := make(map[string]interface{}, len(settings)+1)
+	for k, v := range settings {
+		exclusive[k] = v
+	}
+	exclusive["exclusive"] = true
+	
+	s, err := storage.NewStorage(storage.StorageType(storageType), exclusive)
+	if errors.Is(err, storage.ErrDataDirLocked) {
+		return nil, fmt.Errorf("%v，请先停止账号服和游戏服", err)
+	}
+	return s, err
+}
+
+// runBackup 对当前存储做一次快照备份（服务运行中请使用定时备份或 GM 接口 /gm/backup）
+func runBackup() {
+	utils.Info("=== 数据备份 ===")
+	
+	s, err := openToolStorage(config.Storage.Type, config.Storage.Settings)
+	if err != nil {
+		log.Fatal("存储初始化失败: ", err)
+	}
+	defer s.Close()
+	
+	filePath, manifest, err := storage.Backup(s, *backupDir)
+	if err != nil {
+		log.Fatal("备份失败: ", err)
+	}
+	for table, info := range manifest.Tables {
+		utils.Info("  表 %s: %d 条", table, info.Count)
+	}
+	utils.Info("备份完成: %s", filePath)
+	
+	if err := storage.RotateBackups(*backupDir, *backupKeep); err != nil {
+		utils.Error("备份轮换失败: %v", err)
+	}
+}
+
+// runRestore 从备份文件恢复数据（整库 / 单表 / 单个玩家）
+// 须在服务停止后执行，避免恢复的数据被服务缓存中的旧数据覆盖
+func runRestore() {
+	utils.Info("=== 数据恢复 ===")
+	
+	if *restoreFile == "" {
+		log.Fatal("请使用 -archive 指定备份文件")
+	}
+	
+	s, err := openToolStorage(config.Storage.Type, config.Storage.Settings)
+	if err != nil {
+		log.Fatal("存储初始化失败: ", err)
+	}
+	defer s.Close()
+	
+	restored, err := storage.Restore(s, *restoreFile, storage.RestoreOptions{
+		Table:    *restoreTable,
+		PlayerID: *restorePlayer,
+		DryRun:   *migrateDryRun,
+	})
+	if err != nil {
+		log.Fatal("恢复失败: ", err)
+	}
+	
+	for table, count := range restored {
+		utils.Info("  表 %s: 恢复 %d 条", table, count)
+	}
+	utils.Info("恢复完成: %s (dry-run: %v)", *restoreFile, *migrateDryRun)
+}
+
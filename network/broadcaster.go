@@ -13,14 +13,210 @@ type GameMessageBroadcaster struct{}
 func (gmb *GameMessageBroadcaster) BroadcastToPlayer(playerID string, msgType int, data interface{}) {
 	session := GetSessionManager().GetSessionByPlayerID(playerID)
 	if session != nil && data != nil {
-		if protoMsg, ok := data.(proto.Message); ok {
+		if protoMsg := toProtoMessage(data); protoMsg != nil {
 			session.SendProtoMessage(pb.Cmd(msgType), protoMsg)
 		}
 	}
 }
 
+// toProtoMessage 将游戏层消息转换为 protobuf 消息
+func toProtoMessage(data interface{}) proto.Message {
+	switch msg := data.(type) {
+	case proto.Message:
+		return msg
+	case game.GameOverBroadcast:
+		return toProtoGameOver(&msg)
+	case *game.GameOverBroadcast:
+		return toProtoGameOver(msg)
+	case *game.LevelUpNotify:
+		return toProtoLevelUp(msg)
+	case game.SyncStateBroadcast:
+		return toProtoSyncState(&msg)
+	case game.SyncDamageBroadcast:
+		return toProtoDamage(&msg)
+	case game.BuffSyncNotify:
+		return toProtoBuffSync(&msg)
+	case game.PathUpdateBroadcast:
+		return &pb.PathUpdateBroadcast{Paths: toProtoPaths(msg.Paths)}
+	case game.SkillCastBroadcast:
+		return &pb.SkillCastBroadcast{
+			PlayerId: msg.PlayerID,
+			SkillId:  int32(msg.SkillID),
+			Position: &pb.Vector3{X: msg.Position.X, Y: msg.Position.Y, Z: msg.Position.Z},
+		}
+	case game.BattlePauseBroadcast:
+		return &pb.BattlePauseBroadcast{PlayerId: msg.PlayerID, IsPause: msg.Paused}
+	}
+	return nil
+}
+
+// toProtoGameOver 转换游戏结束广播
+func toProtoGameOver(msg *game.GameOverBroadcast) *pb.GameOverBroadcast {
+	stats := make([]*pb.PlayerGameStats, 0, len(msg.PlayerStats))
+	for _, s := range msg.PlayerStats {
+		stats = append(stats, &pb.PlayerGameStats{
+			PlayerId:     s.PlayerID,
+			PlayerName:   s.PlayerName,
+			KillCount:    int32(s.KillCount),
+			TotalDamage:  s.TotalDamage,
+			TowerCount:   int32(s.TowerCount),
+			GoldEarned:   int32(s.GoldEarned),
+			GoldSpent:    int32(s.GoldSpent),
+			Score:        int32(s.Score),
+			UpgradeCount: int32(s.UpgradeCount),
+			SellCount:    int32(s.SellCount),
+		})
+	}
+	
+	return &pb.GameOverBroadcast{
+		IsVictory:   msg.IsVictory,
+		TotalWaves:  int32(msg.TotalWaves),
+		KillCount:   int32(msg.KillCount),
+		TotalDamage: msg.TotalDamage,
+		Score:       int32(msg.Score),
+		PlayerStats: stats,
+		Reward:      toProtoReward(msg.Reward),
+		MvpPlayerId: msg.MVPPlayerID,
+	}
+}
+
+// toProtoReward 转换战斗奖励
+func toProtoReward(reward *game.BattleReward) *pb.GameReward {
+	if reward == nil {
+		return nil
+	}
+	
+	items := make([]*pb.ItemReward, 0, len(reward.Items))
+	for _, item := range reward.Items {
+		items = append(items, &pb.ItemReward{
+			ItemId:    int32(item.ItemID),
+			ItemCount: int32(item.ItemCount),
+			ItemName:  item.ItemName,
+		})
+	}
+	return &pb.GameReward{
+		Coin:  int32(reward.Coin),
+		Exp:   int32(reward.Exp),
+		Items: items,
+	}
+}
+
+// toProtoLevelUp 转换升级通知
+func toProtoLevelUp(msg *game.LevelUpNotify) *pb.LevelUpNotify {
+	unlocks := make([]*pb.UnlockInfo, 0, len(msg.Unlocks))
+	for _, u := range msg.Unlocks {
+		unlocks = append(unlocks, &pb.UnlockInfo{
+			Type: u.Type,
+			Id:   int32(u.ID),
+			Name: u.Name,
+		})
+	}
+	
+	return &pb.LevelUpNotify{
+		OldLevel:     int32(msg.OldLevel),
+		NewLevel:     int32(msg.NewLevel),
+		Exp:          int32(msg.Exp),
+		NextLevelExp: int32(msg.NextLevelExp),
+		Unlocks:      unlocks,
+	}
+}
+
+// toProtoDamage 转换伤害同步广播
+func toProtoDamage(msg *game.SyncDamageBroadcast) *pb.SyncDamageBroadcast {
+	return &pb.SyncDamageBroadcast{
+		TowerId:    msg.TowerID,
+		EnemyId:    msg.EnemyID,
+		Damage:     int32(msg.Damage),
+		IsCrit:     msg.IsCrit,
+		IsKill:     msg.IsKill,
+		DamageType: int32(msg.DamageType),
+	}
+}
+
+// toProtoBuffSync 转换 Buff 同步通知
+func toProtoBuffSync(msg *game.BuffSyncNotify) *pb.BuffSyncNotify {
+	buffs := make([]*pb.BuffState, 0, len(msg.Buffs))
+	for _, bf := range msg.Buffs {
+		buffs = append(buffs, &pb.BuffState{
+			InstanceId: bf.InstanceID,
+			BuffId:     int32(bf.BuffID),
+			TargetId:   bf.TargetID,
+			Duration:   int32(bf.Duration),
+			StackCount: int32(bf.StackCount),
+			SourceId:   bf.SourceID,
+		})
+	}
+	
+	return &pb.BuffSyncNotify{
+		Buffs:          buffs,
+		RemovedBuffIds: msg.RemovedBuffIDs,
+	}
+}
+
+// toInt32s 转换ID列表
+func toInt32s(values []int) []int32 {
+	result := make([]int32, 0, len(values))
+	for _, v := range values {
+		result = append(result, int32(v))
+	}
+	return result
+}
+
+// toProtoSyncState 转换状态同步广播
+func toProtoSyncState(msg *game.SyncStateBroadcast) *pb.SyncStateBroadcast {
+	enemies := make([]*pb.EnemyState, 0, len(msg.Enemies))
+	for _, e := range msg.Enemies {
+		enemies = append(enemies, &pb.EnemyState{
+			EnemyId:      e.EnemyID,
+			Type:         int32(e.Type),
+			Hp:           int32(e.HP),
+			MaxHp:        int32(e.MaxHP),
+			Position:     &pb.Vector3{X: e.PosX, Y: e.PosY, Z: e.PosZ},
+			Speed:        e.Speed,
+			PathProgress: e.Progress,
+			VisualId:     int32(e.VisualID),
+			Flying:       e.Flying,
+			Debuffs:      toInt32s(e.Debuffs),
+		})
+	}
+	
+	towers := make([]*pb.TowerState, 0, len(msg.Towers))
+	for _, t := range msg.Towers {
+		towers = append(towers, &pb.TowerState{
+			TowerId:  t.TowerID,
+			Type:     int32(t.Type),
+			Level:    int32(t.Level),
+			Position: &pb.Vector3{X: t.PosX, Y: t.PosY, Z: t.PosZ},
+			TargetId: t.TargetID,
+			TargetMode: pb.TargetMode(t.TargetMode),
+			Buffs:    toInt32s(t.Buffs),
+		})
+	}
+	
+	return &pb.SyncStateBroadcast{
+		Gold:    int32(msg.Gold),
+		Life:    int32(msg.Life),
+		WaveNum: int32(msg.WaveNum),
+		Enemies: enemies,
+		Towers:  towers,
+	}
+}
+
 // InitGameBroadcaster 初始化游戏广播器
 func InitGameBroadcaster() {
 	broadcaster := &GameMessageBroadcaster{}
 	game.SetMessageBroadcaster(broadcaster)
 }
+
+// toProtoPaths 转换行进路线
+func toProtoPaths(routes []game.PathRoute) []*pb.PathInfo {
+	paths := make([]*pb.PathInfo, 0, len(routes))
+	for _, route := range routes {
+		points := make([]*pb.Vector3, 0, len(route.Points))
+		for _, p := range route.Points {
+			points = append(points, &pb.Vector3{X: p.X, Y: p.Y, Z: p.Z})
+		}
+		paths = append(paths, &pb.PathInfo{Id: route.PathID, Points: points})
+	}
+	return paths
+}
@@ -0,0 +1,162 @@
+package network
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"towerdefense/config"
+	"towerdefense/logic"
+	"towerdefense/repository"
+	"towerdefense/storage"
+	"towerdefense/utils"
+)
+
+// GM 接口（客服查询、人工调整），请求头 X-GM-Token 须与配置中的 gm_token 一致
+
+// HandleGetLedger 查询玩家货币流水
+// GET /gm/ledger?player_id=xxx&currency=gold&limit=50
+func HandleGetLedger(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !checkGMToken(w, r) {
+		return
+	}
+	
+	query := r.URL.Query()
+	playerID := query.Get("player_id")
+	if playerID == "" {
+		sendGMError(w, "player_id不能为空")
+		return
+	}
+	limit := 50
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			sendGMError(w, "limit无效")
+			return
+		}
+		limit = n
+	}
+	
+	entries, err := logic.GetLedgerService().History(playerID, query.Get("currency"), limit)
+	if err != nil {
+		sendGMError(w, "查询流水失败: "+err.Error())
+		return
+	}
+	sendGMSuccess(w, entries)
+}
+
+// HandleAdjustCurrency GM 调整玩家货币
+// POST /gm/currency {"player_id": "xxx", "currency": "diamond", "delta": 100, "operator": "admin", "note": "补偿"}
+func HandleAdjustCurrency(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !checkGMToken(w, r) {
+		return
+	}
+	
+	var req struct {
+		PlayerID string `json:"player_id"`
+		Currency string `json:"currency"`
+		Delta    int    `json:"delta"`
+		Operator string `json:"operator"`
+		Note     string `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendGMError(w, "请求数据格式错误")
+		return
+	}
+	if req.PlayerID == "" || req.Operator == "" || req.Delta == 0 {
+		sendGMError(w, "player_id、operator不能为空，delta不能为0")
+		return
+	}
+	
+	sourceRef := req.Operator
+	if req.Note != "" {
+		sourceRef += ": " + req.Note
+	}
+	balance, err := logic.GetLedgerService().Change(req.PlayerID, req.Currency, req.Delta, repository.LedgerReasonGM, sourceRef)
+	if err != nil {
+		if errors.Is(err, repository.ErrInsufficientBalance) || errors.Is(err, repository.ErrUnknownCurrency) {
+			sendGMError(w, err.Error())
+			return
+		}
+		sendGMError(w, "调整货币失败: "+err.Error())
+		return
+	}
+	
+	utils.Info("GM %s 调整玩家 %s 的 %s: %+d", req.Operator, req.PlayerID, req.Currency, req.Delta)
+	sendGMSuccess(w, map[string]interface{}{
+		"player_id": req.PlayerID,
+		"currency":  req.Currency,
+		"balance":   balance,
+	})
+}
+
+// HandleBackup 在服务进程内对当前存储做一次在线备份（一致性快照）
+// 备份目录和保留份数取存储配置的 backup 段，未配置时写入 ./backups 且不清理旧备份
+// POST /gm/backup
+func HandleBackup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !checkGMToken(w, r) {
+		return
+	}
+	
+	dir, keep := "./backups", 0
+	if cfg := storage.ParseBackupConfig(config.Storage.Settings); cfg != nil {
+		dir, keep = cfg.Dir, cfg.Keep
+	}
+	filePath, manifest, err := storage.Backup(storage.GetStorage(), dir)
+	if err != nil {
+		sendGMError(w, "备份失败: "+err.Error())
+		return
+	}
+	if err := storage.RotateBackups(dir, keep); err != nil {
+		utils.Error("备份轮换失败: %v", err)
+	}
+	
+	utils.Info("GM 备份完成: %s", filePath)
+	sendGMSuccess(w, map[string]interface{}{
+		"file":   filePath,
+		"tables": manifest.Tables,
+	})
+}
+
+// checkGMToken 校验 GM 令牌（未配置令牌时 GM 接口不可用）
+func checkGMToken(w http.ResponseWriter, r *http.Request) bool {
+	if config.Server.GMToken == "" || r.Header.Get("X-GM-Token") != config.Server.GMToken {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// sendGMSuccess 发送成功响应
+func sendGMSuccess(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"code":    0,
+		"message": "success",
+		"data":    data,
+	})
+}
+
+// sendGMError 发送错误响应
+func sendGMError(w http.ResponseWriter, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"code":    -1,
+		"message": message,
+		"data":    nil,
+	})
+}
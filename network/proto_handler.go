@@ -3,9 +3,14 @@ package network
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
+	"towerdefense/config"
+	"towerdefense/game"
+	"towerdefense/logic"
 	pb "towerdefense/proto"
 	"towerdefense/repository"
 	"towerdefense/utils"
@@ -13,8 +18,13 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
-// 玩家仓储单例
-var playerRepo = repository.NewPlayerRepository()
+// 玩家仓储单例（存储层初始化后由 InitRepositories 创建）
+var playerRepo *repository.PlayerRepository
+
+// InitRepositories 初始化网络层使用的仓储（必须在存储层初始化之后调用）
+func InitRepositories() {
+	playerRepo = repository.NewPlayerRepository()
+}
 
 // 账号服地址（用于验证token）
 var accountServerURL = "http://192.168.2.100:8080"
@@ -36,6 +46,12 @@ func (s *Session) HandleProtoMessage(packet *pb.NetworkPacket) {
 		s.handleProtoUpdatePlayerName(packet.Payload)
 	case pb.Cmd_MSG_UPDATE_PLAYER_ICON_REQ:
 		s.handleProtoUpdatePlayerIcon(packet.Payload)
+	case pb.Cmd_MSG_UPDATE_PLAYER_FRAME_REQ:
+		s.handleProtoUpdatePlayerFrame(packet.Payload)
+	case pb.Cmd_MSG_GET_INVENTORY_REQ:
+		s.handleProtoGetInventory(packet.Payload)
+	case pb.Cmd_MSG_USE_ITEM_REQ:
+		s.handleProtoUseItem(packet.Payload)
 	case pb.Cmd_MSG_CREATE_ROOM_REQ:
 		s.handleProtoCreateRoom(packet.Payload)
 	case pb.Cmd_MSG_JOIN_ROOM_REQ:
@@ -50,6 +66,12 @@ func (s *Session) HandleProtoMessage(packet *pb.NetworkPacket) {
 		s.handleProtoUpgradeTower(packet.Payload)
 	case pb.Cmd_MSG_SELL_TOWER_REQ:
 		s.handleProtoSellTower(packet.Payload)
+	case pb.Cmd_MSG_SET_TARGET_MODE_REQ:
+		s.handleProtoSetTargetMode(packet.Payload)
+	case pb.Cmd_MSG_USE_SKILL_REQ:
+		s.handleProtoUseSkill(packet.Payload)
+	case pb.Cmd_MSG_PAUSE_GAME_REQ:
+		s.handleProtoPauseGame(packet.Payload)
 	case pb.Cmd_MSG_WAVE_START_REQ:
 		s.handleProtoWaveStart(packet.Payload)
 	default:
@@ -179,6 +201,14 @@ func (s *Session) handleProtoLogin(payload []byte) {
 		return
 	}
 	
+	// 读取玩家数据（不存在则创建默认数据）
+	playerData, _, err := playerRepo.GetOrCreatePlayer(playerID, username)
+	if err != nil {
+		utils.Error("加载玩家数据失败: %s, %v", playerID, err)
+		s.SendProtoError(pb.ErrorCode_ERROR_UNKNOWN, "加载玩家数据失败")
+		return
+	}
+	
 	// 设置玩家信息（从 token 验证结果获取，同时保存 token 用于断开时清理）
 	s.SetPlayerInfo(playerID, username, req.Token)
 	
@@ -187,10 +217,13 @@ func (s *Session) handleProtoLogin(payload []byte) {
 		PlayerId:   playerID,
 		PlayerName: username,
 		PlayerInfo: &pb.PlayerBaseInfo{
-			PlayerId:   playerID,
-			PlayerName: username,
-			Level:      1,
-			Coin:       1000,
+			PlayerId:      playerID,
+			PlayerName:    playerData.PlayerName,
+			Level:         int32(playerData.Level),
+			Exp:           int32(playerData.Exp),
+			Coin:          int32(playerData.Gold),
+			Diamond:       int32(playerData.Diamond),
+			LastLoginTime: playerData.LastLoginTime.Unix(),
 		},
 	}
 	
@@ -266,72 +299,321 @@ func (s *Session) handleProtoLeaveRoom(payload []byte) {
 }
 
 func (s *Session) handleProtoStartGame(payload []byte) {
+	if s.PlayerID == "" {
+		s.SendProtoError(pb.ErrorCode_ERROR_NOT_LOGIN, "请先登录")
+		return
+	}
+	
 	var req pb.StartGameRequest
 	if err := proto.Unmarshal(payload, &req); err != nil {
 		s.SendProtoError(pb.ErrorCode_ERROR_INVALID_PARAM, "开始游戏数据解析失败")
 		return
 	}
 	
-	// TODO: 实现开始游戏逻辑
-	resp := &pb.StartGameResponse{
-		Success: true,
+	battle, err := logic.GetRoomManager().StartGame(req.RoomId, s.PlayerID)
+	if err != nil {
+		switch {
+		case errors.Is(err, logic.ErrRoomNotFound):
+			s.SendProtoError(pb.ErrorCode_ERROR_ROOM_NOT_FOUND, err.Error())
+		case errors.Is(err, logic.ErrNotRoomHost):
+			s.SendProtoError(pb.ErrorCode_ERROR_NOT_HOST, err.Error())
+		case errors.Is(err, logic.ErrLevelLocked):
+			s.SendProtoError(pb.ErrorCode_ERROR_PERMISSION_DENIED, err.Error())
+		case errors.Is(err, game.ErrRoomNotWaiting):
+			s.SendProtoError(pb.ErrorCode_ERROR_ROOM_ALREADY_STARTED, err.Error())
+		case errors.Is(err, logic.ErrPlayersNotReady), errors.Is(err, game.ErrUnknownLevel):
+			s.SendProtoError(pb.ErrorCode_ERROR_INVALID_PARAM, err.Error())
+		default:
+			s.SendProtoError(pb.ErrorCode_ERROR_UNKNOWN, "开始游戏失败: "+err.Error())
+		}
+		return
 	}
 	
-	s.SendProtoMessage(pb.Cmd_MSG_START_GAME_RSP, resp)
+	// 迷宫模式下敌人沿网格路线行进，开局数据发送当前路线而不是配置的路径点
+	routes := battle.Routes()
+	
+	// 每名玩家的初始金币可能因道具加成不同，分别发送
+	// 战斗在响应发送后才启动，客户端先收到开始游戏响应，再收到第一波和同步消息
+	startTime := time.Now().Unix()
+	for playerID, player := range battle.Players {
+		session := GetSessionManager().GetSessionByPlayerID(playerID)
+		if session == nil {
+			continue
+		}
+		resp := &pb.StartGameResponse{
+			Success:   true,
+			LevelId:   int32(battle.LevelID),
+			GameData:  toProtoGameInitData(battle.Level, routes, player.GetGold(), player.GetLife()),
+			Message:   "游戏开始",
+			StartTime: startTime,
+		}
+		session.SendProtoMessage(pb.Cmd_MSG_START_GAME_RSP, resp)
+	}
+	battle.Start()
+}
+
+// toProtoGameInitData 由关卡配置和战斗的当前路线生成开局数据，path_points 保留为第一条路径以兼容旧客户端
+// 未取得路线（战斗已结束）时使用配置的路径点
+func toProtoGameInitData(level *config.LevelDef, routes []game.PathRoute, gold, life int) *pb.GameInitData {
+	if len(routes) == 0 {
+		for _, path := range level.Paths {
+			route := game.PathRoute{PathID: path.ID}
+			for _, p := range path.Points {
+				route.Points = append(route.Points, game.Vector3{X: p.X, Y: p.Y, Z: p.Z})
+			}
+			routes = append(routes, route)
+		}
+	}
+	paths := toProtoPaths(routes)
+	
+	waves := make([]*pb.WaveInfo, 0, len(level.Waves))
+	for i, wave := range level.Waves {
+		info := &pb.WaveInfo{
+			WaveNum:    int32(i + 1),
+			Reward:     int32(wave.Reward),
+			Difficulty: int32(level.Difficulty),
+		}
+		for _, group := range wave.Groups {
+			info.EnemyTypes = append(info.EnemyTypes, int32(group.EnemyType))
+			info.EnemyCounts = append(info.EnemyCounts, int32(group.Count))
+		}
+		waves = append(waves, info)
+	}
+	
+	return &pb.GameInitData{
+		Gold:       int32(gold),
+		Life:       int32(life),
+		MapData:    level.MapData,
+		WaveInfo:   waves,
+		PathPoints: paths[0].Points,
+		TotalWaves: int32(level.TotalWaves()),
+		Paths:      paths,
+	}
 }
 
 // ========== 战斗相关消息处理 ==========
 
 func (s *Session) handleProtoPlaceTower(payload []byte) {
+	if s.PlayerID == "" {
+		s.SendProtoError(pb.ErrorCode_ERROR_NOT_LOGIN, "请先登录")
+		return
+	}
+	
 	var req pb.PlaceTowerRequest
 	if err := proto.Unmarshal(payload, &req); err != nil {
 		s.SendProtoError(pb.ErrorCode_ERROR_INVALID_PARAM, "放置防御塔数据解析失败")
 		return
 	}
+	if req.Position == nil {
+		s.SendProtoError(pb.ErrorCode_ERROR_INVALID_PARAM, "缺少防御塔位置")
+		return
+	}
+	
+	pos := game.Vector3{X: req.Position.X, Y: req.Position.Y, Z: req.Position.Z}
+	tower, gold, err := logic.GetTowerService().PlaceTower(s.PlayerID, int(req.TowerType), pos)
+	if err != nil {
+		s.sendBattleError("放置防御塔失败", err)
+		return
+	}
 	
-	// TODO: 实现放置防御塔逻辑
 	resp := &pb.PlaceTowerResponse{
-		Success: true,
-		TowerId: "tower_" + s.PlayerID,
+		Success:   true,
+		TowerId:   tower.TowerID,
+		TowerType: req.TowerType,
+		Position:  &pb.Vector3{X: tower.PosX, Y: tower.PosY, Z: tower.PosZ},
+		Gold:      int32(gold),
+		Message:   "放置成功",
 	}
 	
 	s.SendProtoMessage(pb.Cmd_MSG_PLACE_TOWER_RSP, resp)
+	utils.Info("玩家 %s 放置防御塔: %s 类型 %d", s.PlayerName, tower.TowerID, req.TowerType)
 }
 
 func (s *Session) handleProtoUpgradeTower(payload []byte) {
+	if s.PlayerID == "" {
+		s.SendProtoError(pb.ErrorCode_ERROR_NOT_LOGIN, "请先登录")
+		return
+	}
+	
 	var req pb.UpgradeTowerRequest
 	if err := proto.Unmarshal(payload, &req); err != nil {
 		s.SendProtoError(pb.ErrorCode_ERROR_INVALID_PARAM, "升级防御塔数据解析失败")
 		return
 	}
 	
-	// TODO: 实现升级防御塔逻辑
+	tower, cost, gold, err := logic.GetTowerService().UpgradeTower(s.PlayerID, req.TowerId)
+	if err != nil {
+		s.sendBattleError("升级防御塔失败", err)
+		return
+	}
+	
 	resp := &pb.UpgradeTowerResponse{
 		Success: true,
 		TowerId: req.TowerId,
-		Level:   2,
+		Level:   int32(tower.Level),
+		Cost:    int32(cost),
+		Gold:    int32(gold),
+		Message: "升级成功",
 	}
 	
 	s.SendProtoMessage(pb.Cmd_MSG_UPGRADE_TOWER_RSP, resp)
 }
 
 func (s *Session) handleProtoSellTower(payload []byte) {
+	if s.PlayerID == "" {
+		s.SendProtoError(pb.ErrorCode_ERROR_NOT_LOGIN, "请先登录")
+		return
+	}
+	
 	var req pb.SellTowerRequest
 	if err := proto.Unmarshal(payload, &req); err != nil {
 		s.SendProtoError(pb.ErrorCode_ERROR_INVALID_PARAM, "出售防御塔数据解析失败")
 		return
 	}
 	
-	// TODO: 实现出售防御塔逻辑
+	refund, gold, err := logic.GetTowerService().SellTower(s.PlayerID, req.TowerId)
+	if err != nil {
+		s.sendBattleError("出售防御塔失败", err)
+		return
+	}
+	
 	resp := &pb.SellTowerResponse{
 		Success: true,
 		TowerId: req.TowerId,
-		Refund:  100,
+		Refund:  int32(refund),
+		Gold:    int32(gold),
+		Message: "出售成功",
 	}
 	
 	s.SendProtoMessage(pb.Cmd_MSG_SELL_TOWER_RSP, resp)
 }
 
+func (s *Session) handleProtoSetTargetMode(payload []byte) {
+	if s.PlayerID == "" {
+		s.SendProtoError(pb.ErrorCode_ERROR_NOT_LOGIN, "请先登录")
+		return
+	}
+	
+	var req pb.SetTargetModeRequest
+	if err := proto.Unmarshal(payload, &req); err != nil {
+		s.SendProtoError(pb.ErrorCode_ERROR_INVALID_PARAM, "设置目标模式数据解析失败")
+		return
+	}
+	
+	tower, err := logic.GetTowerService().SetTargetMode(s.PlayerID, req.TowerId, int(req.TargetMode))
+	if err != nil {
+		s.sendBattleError("设置目标模式失败", err)
+		return
+	}
+	
+	resp := &pb.SetTargetModeResponse{
+		Success:    true,
+		TowerId:    tower.TowerID,
+		TargetMode: pb.TargetMode(tower.TargetMode),
+		Message:    "设置成功",
+	}
+	
+	s.SendProtoMessage(pb.Cmd_MSG_SET_TARGET_MODE_RSP, resp)
+}
+
+func (s *Session) handleProtoUseSkill(payload []byte) {
+	if s.PlayerID == "" {
+		s.SendProtoError(pb.ErrorCode_ERROR_NOT_LOGIN, "请先登录")
+		return
+	}
+	
+	var req pb.UseSkillRequest
+	if err := proto.Unmarshal(payload, &req); err != nil {
+		s.SendProtoError(pb.ErrorCode_ERROR_INVALID_PARAM, "释放技能数据解析失败")
+		return
+	}
+	if req.TargetPosition == nil && req.TargetId == "" {
+		s.SendProtoError(pb.ErrorCode_ERROR_INVALID_PARAM, "缺少技能目标")
+		return
+	}
+	
+	battle, err := logic.GetRoomManager().GetPlayerBattle(s.PlayerID)
+	if err != nil {
+		s.sendBattleError("释放技能失败", err)
+		return
+	}
+	var pos game.Vector3
+	if req.TargetPosition != nil {
+		pos = game.Vector3{X: req.TargetPosition.X, Y: req.TargetPosition.Y, Z: req.TargetPosition.Z}
+	}
+	gold, err := battle.CastSkill(s.PlayerID, int(req.SkillId), req.TargetId, pos)
+	if err != nil {
+		s.sendBattleError("释放技能失败", err)
+		return
+	}
+	
+	resp := &pb.UseSkillResponse{
+		Success: true,
+		SkillId: req.SkillId,
+		Gold:    int32(gold),
+		Message: "释放成功",
+	}
+	
+	s.SendProtoMessage(pb.Cmd_MSG_USE_SKILL_RSP, resp)
+}
+
+func (s *Session) handleProtoPauseGame(payload []byte) {
+	if s.PlayerID == "" {
+		s.SendProtoError(pb.ErrorCode_ERROR_NOT_LOGIN, "请先登录")
+		return
+	}
+	
+	var req pb.PauseGameRequest
+	if err := proto.Unmarshal(payload, &req); err != nil {
+		s.SendProtoError(pb.ErrorCode_ERROR_INVALID_PARAM, "暂停游戏数据解析失败")
+		return
+	}
+	
+	battle, err := logic.GetRoomManager().GetPlayerBattle(s.PlayerID)
+	if err != nil {
+		s.sendBattleError("暂停游戏失败", err)
+		return
+	}
+	if err := battle.SetPaused(s.PlayerID, req.IsPause); err != nil {
+		s.sendBattleError("暂停游戏失败", err)
+		return
+	}
+	
+	resp := &pb.PauseGameResponse{
+		Success: true,
+		IsPause: req.IsPause,
+		Message: "操作成功",
+	}
+	
+	s.SendProtoMessage(pb.Cmd_MSG_PAUSE_GAME_RSP, resp)
+	utils.Info("玩家 %s 设置战斗暂停: %v", s.PlayerName, req.IsPause)
+}
+
+// sendBattleError 将战斗操作（防御塔、技能、暂停）的错误转换为错误码发送
+func (s *Session) sendBattleError(action string, err error) {
+	switch {
+	case errors.Is(err, game.ErrUnknownTowerType), errors.Is(err, game.ErrTowerMaxLevel), errors.Is(err, game.ErrInvalidTargetMode):
+		s.SendProtoError(pb.ErrorCode_ERROR_INVALID_PARAM, err.Error())
+	case errors.Is(err, game.ErrUnknownSkill), errors.Is(err, game.ErrSkillCooldown), errors.Is(err, game.ErrSkillTarget),
+		errors.Is(err, game.ErrBattlePaused), errors.Is(err, game.ErrBattleNotPaused):
+		s.SendProtoError(pb.ErrorCode_ERROR_INVALID_PARAM, err.Error())
+	case errors.Is(err, logic.ErrTowerLocked):
+		s.SendProtoError(pb.ErrorCode_ERROR_PERMISSION_DENIED, err.Error())
+	case errors.Is(err, game.ErrNotEnoughGold):
+		s.SendProtoError(pb.ErrorCode_ERROR_NOT_ENOUGH_GOLD, err.Error())
+	case errors.Is(err, game.ErrInvalidPosition):
+		s.SendProtoError(pb.ErrorCode_ERROR_INVALID_POSITION, err.Error())
+	case errors.Is(err, game.ErrTowerNotFound):
+		s.SendProtoError(pb.ErrorCode_ERROR_TOWER_NOT_FOUND, err.Error())
+	case errors.Is(err, game.ErrNotInBattle):
+		s.SendProtoError(pb.ErrorCode_ERROR_GAME_NOT_STARTED, err.Error())
+	case errors.Is(err, game.ErrBattleFinished):
+		s.SendProtoError(pb.ErrorCode_ERROR_GAME_OVER, err.Error())
+	default:
+		s.SendProtoError(pb.ErrorCode_ERROR_UNKNOWN, action+": "+err.Error())
+	}
+}
+
 func (s *Session) handleProtoWaveStart(payload []byte) {
 	// TODO: 实现波次开始逻辑
 	utils.Info("玩家 %s 请求开始波次", s.PlayerName)
@@ -399,31 +681,46 @@ func (s *Session) handleProtoUpdatePlayerName(payload []byte) {
 		return
 	}
 	
-	if req.NewName == "" {
+	newName := strings.TrimSpace(req.NewName)
+	if newName == "" {
 		s.SendProtoError(pb.ErrorCode_ERROR_INVALID_PARAM, "名称不能为空")
 		return
 	}
 	
-	// TODO: 可以添加名称敏感词检测、长度检测等
-	
-	// 更新名称
-	err := playerRepo.UpdatePlayerName(s.PlayerID, req.NewName)
+	// 更新名称（校验长度、敏感词和唯一性，非首次改名扣除钻石）
+	cost, err := logic.GetNameService().Rename(s.PlayerID, newName)
 	if err != nil {
-		s.SendProtoError(pb.ErrorCode_ERROR_UNKNOWN, "更新名称失败: "+err.Error())
+		switch {
+		case errors.Is(err, logic.ErrNameLength), errors.Is(err, logic.ErrNameIllegal),
+			errors.Is(err, logic.ErrNameSensitive), errors.Is(err, logic.ErrNameUnchanged):
+			s.SendProtoError(pb.ErrorCode_ERROR_NAME_INVALID, err.Error())
+		case errors.Is(err, repository.ErrNameTaken):
+			s.SendProtoError(pb.ErrorCode_ERROR_NAME_TAKEN, err.Error())
+		case errors.Is(err, logic.ErrRenameCooling):
+			s.SendProtoError(pb.ErrorCode_ERROR_RENAME_COOLDOWN, err.Error())
+		case errors.Is(err, repository.ErrInsufficientBalance):
+			s.SendProtoError(pb.ErrorCode_ERROR_NOT_ENOUGH_DIAMOND, "钻石不足")
+		default:
+			s.SendProtoError(pb.ErrorCode_ERROR_UNKNOWN, "更新名称失败: "+err.Error())
+		}
 		return
 	}
 	
 	// 同时更新 session 中的名称
-	s.PlayerName = req.NewName
+	s.PlayerName = newName
 	
+	message := "修改成功"
+	if cost > 0 {
+		message = fmt.Sprintf("修改成功，消耗钻石 %d", cost)
+	}
 	resp := &pb.UpdatePlayerNameResponse{
 		Success: true,
-		Message: "修改成功",
-		NewName: req.NewName,
+		Message: message,
+		NewName: newName,
 	}
 	
 	s.SendProtoMessage(pb.Cmd_MSG_UPDATE_PLAYER_NAME_RSP, resp)
-	utils.Info("玩家 %s 修改名称为: %s", s.PlayerID, req.NewName)
+	utils.Info("玩家 %s 修改名称为: %s", s.PlayerID, newName)
 }
 
 func (s *Session) handleProtoUpdatePlayerIcon(payload []byte) {
@@ -439,10 +736,12 @@ func (s *Session) handleProtoUpdatePlayerIcon(payload []byte) {
 		return
 	}
 	
-	// TODO: 可以添加头像ID合法性检测（是否已解锁等）
-	
-	// 更新头像
-	err := playerRepo.UpdatePlayerIcon(s.PlayerID, int(req.IconId))
+	// 更新头像（须已解锁）
+	err := logic.GetCosmeticService().SetIcon(s.PlayerID, int(req.IconId))
+	if errors.Is(err, logic.ErrCosmeticLocked) {
+		s.SendProtoError(pb.ErrorCode_ERROR_PERMISSION_DENIED, "头像未解锁")
+		return
+	}
 	if err != nil {
 		s.SendProtoError(pb.ErrorCode_ERROR_UNKNOWN, "更新头像失败: "+err.Error())
 		return
@@ -457,3 +756,119 @@ func (s *Session) handleProtoUpdatePlayerIcon(payload []byte) {
 	s.SendProtoMessage(pb.Cmd_MSG_UPDATE_PLAYER_ICON_RSP, resp)
 	utils.Info("玩家 %s 修改头像为: %d", s.PlayerName, req.IconId)
 }
+
+func (s *Session) handleProtoUpdatePlayerFrame(payload []byte) {
+	// 检查是否已登录
+	if s.PlayerID == "" {
+		s.SendProtoError(pb.ErrorCode_ERROR_NOT_LOGIN, "请先登录")
+		return
+	}
+	
+	var req pb.UpdatePlayerFrameRequest
+	if err := proto.Unmarshal(payload, &req); err != nil {
+		s.SendProtoError(pb.ErrorCode_ERROR_INVALID_PARAM, "请求数据解析失败")
+		return
+	}
+	
+	// 更新头像框（须已解锁）
+	err := logic.GetCosmeticService().SetFrame(s.PlayerID, int(req.FrameId))
+	if errors.Is(err, logic.ErrCosmeticLocked) {
+		s.SendProtoError(pb.ErrorCode_ERROR_PERMISSION_DENIED, "头像框未解锁")
+		return
+	}
+	if err != nil {
+		s.SendProtoError(pb.ErrorCode_ERROR_UNKNOWN, "更新头像框失败: "+err.Error())
+		return
+	}
+	
+	resp := &pb.UpdatePlayerFrameResponse{
+		Success: true,
+		Message: "修改成功",
+		FrameId: req.FrameId,
+	}
+	
+	s.SendProtoMessage(pb.Cmd_MSG_UPDATE_PLAYER_FRAME_RSP, resp)
+	utils.Info("玩家 %s 修改头像框为: %d", s.PlayerName, req.FrameId)
+}
+
+// ========== 背包相关消息处理 ==========
+
+func (s *Session) handleProtoGetInventory(payload []byte) {
+	// 检查是否已登录
+	if s.PlayerID == "" {
+		s.SendProtoError(pb.ErrorCode_ERROR_NOT_LOGIN, "请先登录")
+		return
+	}
+	
+	inventory, err := logic.GetInventoryService().GetInventory(s.PlayerID)
+	if err != nil {
+		s.SendProtoError(pb.ErrorCode_ERROR_UNKNOWN, "获取背包失败: "+err.Error())
+		return
+	}
+	
+	items := make([]*pb.ItemInfo, 0, len(inventory.Items))
+	for _, item := range inventory.Items {
+		info := &pb.ItemInfo{
+			ItemId: int32(item.ItemID),
+			Count:  int32(item.Count),
+		}
+		if def, ok := config.Items.GetItem(item.ItemID); ok {
+			info.Name = def.Name
+			info.Category = def.Category
+		}
+		if !item.ExpireTime.IsZero() {
+			info.ExpireTime = item.ExpireTime.Unix()
+		}
+		items = append(items, info)
+	}
+	
+	resp := &pb.GetInventoryResponse{
+		Success:        true,
+		Message:        "获取成功",
+		Items:          items,
+		StartGoldBonus: int32(inventory.EffectValue(config.ItemEffectStartGold)),
+	}
+	
+	s.SendProtoMessage(pb.Cmd_MSG_GET_INVENTORY_RSP, resp)
+}
+
+func (s *Session) handleProtoUseItem(payload []byte) {
+	// 检查是否已登录
+	if s.PlayerID == "" {
+		s.SendProtoError(pb.ErrorCode_ERROR_NOT_LOGIN, "请先登录")
+		return
+	}
+	
+	var req pb.UseItemRequest
+	if err := proto.Unmarshal(payload, &req); err != nil {
+		s.SendProtoError(pb.ErrorCode_ERROR_INVALID_PARAM, "请求数据解析失败")
+		return
+	}
+	if req.Count < 0 {
+		s.SendProtoError(pb.ErrorCode_ERROR_INVALID_PARAM, "使用数量无效")
+		return
+	}
+	
+	remain, err := logic.GetInventoryService().UseItem(s.PlayerID, int(req.ItemId), int(req.Count))
+	if err != nil {
+		switch {
+		case errors.Is(err, logic.ErrItemUndefined), errors.Is(err, logic.ErrItemNotEnough):
+			s.SendProtoError(pb.ErrorCode_ERROR_NOT_FOUND, err.Error())
+		case errors.Is(err, logic.ErrItemNotUsable):
+			s.SendProtoError(pb.ErrorCode_ERROR_INVALID_PARAM, err.Error())
+		default:
+			s.SendProtoError(pb.ErrorCode_ERROR_UNKNOWN, "使用道具失败: "+err.Error())
+		}
+		return
+	}
+	
+	resp := &pb.UseItemResponse{
+		Success:     true,
+		Message:     "使用成功",
+		ItemId:      req.ItemId,
+		RemainCount: int32(remain),
+	}
+	
+	s.SendProtoMessage(pb.Cmd_MSG_USE_ITEM_RSP, resp)
+	utils.Info("玩家 %s 使用道具: %d", s.PlayerName, req.ItemId)
+}
@@ -204,6 +204,13 @@ func (s *Session) Close() {
 		s.IsAlive = false
 		s.Conn.Close()
 		
+		// 立即写回缓存中的玩家数据（启用写缓存时）
+		if s.PlayerID != "" && playerRepo != nil {
+			if err := playerRepo.Flush(s.PlayerID); err != nil {
+				utils.Error("玩家数据写回失败: 玩家=%s, %v", s.PlayerID, err)
+			}
+		}
+		
 		// 通知账号服清理 token（防止内存泄漏）
 		if s.Token != "" {
 			account.GetAccountServer().InvalidateToken(s.Token)
@@ -478,6 +478,7 @@ type UseSkillResponse struct {
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
 	SkillId       int32                  `protobuf:"varint,2,opt,name=skill_id,json=skillId,proto3" json:"skill_id,omitempty"`
 	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	Gold          int32                  `protobuf:"varint,4,opt,name=gold,proto3" json:"gold,omitempty"` // 释放后剩余的战斗金币
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -533,6 +534,13 @@ func (x *UseSkillResponse) GetMessage() string {
 	return ""
 }
 
+func (x *UseSkillResponse) GetGold() int32 {
+	if x != nil {
+		return x.Gold
+	}
+	return 0
+}
+
 // 波次开始广播
 type WaveStartBroadcast struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -698,7 +706,9 @@ type GameOverBroadcast struct {
 	// 各玩家统计
 	PlayerStats []*PlayerGameStats `protobuf:"bytes,6,rep,name=player_stats,json=playerStats,proto3" json:"player_stats,omitempty"`
 	// 奖励
-	Reward        *GameReward `protobuf:"bytes,7,opt,name=reward,proto3" json:"reward,omitempty"`
+	Reward *GameReward `protobuf:"bytes,7,opt,name=reward,proto3" json:"reward,omitempty"`
+	// MVP玩家ID
+	MvpPlayerId   string `protobuf:"bytes,8,opt,name=mvp_player_id,json=mvpPlayerId,proto3" json:"mvp_player_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -782,6 +792,13 @@ func (x *GameOverBroadcast) GetReward() *GameReward {
 	return nil
 }
 
+func (x *GameOverBroadcast) GetMvpPlayerId() string {
+	if x != nil {
+		return x.MvpPlayerId
+	}
+	return ""
+}
+
 // 玩家游戏统计
 type PlayerGameStats struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -793,6 +810,8 @@ type PlayerGameStats struct {
 	GoldEarned    int32                  `protobuf:"varint,6,opt,name=gold_earned,json=goldEarned,proto3" json:"gold_earned,omitempty"` // 获得金币
 	GoldSpent     int32                  `protobuf:"varint,7,opt,name=gold_spent,json=goldSpent,proto3" json:"gold_spent,omitempty"`    // 花费金币
 	Score         int32                  `protobuf:"varint,8,opt,name=score,proto3" json:"score,omitempty"`
+	UpgradeCount  int32                  `protobuf:"varint,9,opt,name=upgrade_count,json=upgradeCount,proto3" json:"upgrade_count,omitempty"` // 升级次数
+	SellCount     int32                  `protobuf:"varint,10,opt,name=sell_count,json=sellCount,proto3" json:"sell_count,omitempty"`         // 出售次数
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -883,6 +902,20 @@ func (x *PlayerGameStats) GetScore() int32 {
 	return 0
 }
 
+func (x *PlayerGameStats) GetUpgradeCount() int32 {
+	if x != nil {
+		return x.UpgradeCount
+	}
+	return 0
+}
+
+func (x *PlayerGameStats) GetSellCount() int32 {
+	if x != nil {
+		return x.SellCount
+	}
+	return 0
+}
+
 // 游戏奖励
 type GameReward struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -1111,6 +1144,242 @@ func (x *PauseGameResponse) GetMessage() string {
 	return ""
 }
 
+// 设置防御塔目标选择模式请求
+type SetTargetModeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TowerId       string                 `protobuf:"bytes,1,opt,name=tower_id,json=towerId,proto3" json:"tower_id,omitempty"`
+	TargetMode    TargetMode             `protobuf:"varint,2,opt,name=target_mode,json=targetMode,proto3,enum=TargetMode" json:"target_mode,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetTargetModeRequest) Reset() {
+	*x = SetTargetModeRequest{}
+	mi := &file_battle_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetTargetModeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetTargetModeRequest) ProtoMessage() {}
+
+func (x *SetTargetModeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_battle_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetTargetModeRequest.ProtoReflect.Descriptor instead.
+func (*SetTargetModeRequest) Descriptor() ([]byte, []int) {
+	return file_battle_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *SetTargetModeRequest) GetTowerId() string {
+	if x != nil {
+		return x.TowerId
+	}
+	return ""
+}
+
+func (x *SetTargetModeRequest) GetTargetMode() TargetMode {
+	if x != nil {
+		return x.TargetMode
+	}
+	return TargetMode_TARGET_CLOSEST
+}
+
+// 设置防御塔目标选择模式响应
+type SetTargetModeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	TowerId       string                 `protobuf:"bytes,2,opt,name=tower_id,json=towerId,proto3" json:"tower_id,omitempty"`
+	TargetMode    TargetMode             `protobuf:"varint,3,opt,name=target_mode,json=targetMode,proto3,enum=TargetMode" json:"target_mode,omitempty"`
+	Message       string                 `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetTargetModeResponse) Reset() {
+	*x = SetTargetModeResponse{}
+	mi := &file_battle_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetTargetModeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetTargetModeResponse) ProtoMessage() {}
+
+func (x *SetTargetModeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_battle_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetTargetModeResponse.ProtoReflect.Descriptor instead.
+func (*SetTargetModeResponse) Descriptor() ([]byte, []int) {
+	return file_battle_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *SetTargetModeResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *SetTargetModeResponse) GetTowerId() string {
+	if x != nil {
+		return x.TowerId
+	}
+	return ""
+}
+
+func (x *SetTargetModeResponse) GetTargetMode() TargetMode {
+	if x != nil {
+		return x.TargetMode
+	}
+	return TargetMode_TARGET_CLOSEST
+}
+
+func (x *SetTargetModeResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// 玩家释放技能广播（伤害和 Buff 通过伤害、Buff 同步通知下发）
+type SkillCastBroadcast struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PlayerId      string                 `protobuf:"bytes,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	SkillId       int32                  `protobuf:"varint,2,opt,name=skill_id,json=skillId,proto3" json:"skill_id,omitempty"`
+	Position      *Vector3               `protobuf:"bytes,3,opt,name=position,proto3" json:"position,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SkillCastBroadcast) Reset() {
+	*x = SkillCastBroadcast{}
+	mi := &file_battle_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SkillCastBroadcast) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SkillCastBroadcast) ProtoMessage() {}
+
+func (x *SkillCastBroadcast) ProtoReflect() protoreflect.Message {
+	mi := &file_battle_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SkillCastBroadcast.ProtoReflect.Descriptor instead.
+func (*SkillCastBroadcast) Descriptor() ([]byte, []int) {
+	return file_battle_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *SkillCastBroadcast) GetPlayerId() string {
+	if x != nil {
+		return x.PlayerId
+	}
+	return ""
+}
+
+func (x *SkillCastBroadcast) GetSkillId() int32 {
+	if x != nil {
+		return x.SkillId
+	}
+	return 0
+}
+
+func (x *SkillCastBroadcast) GetPosition() *Vector3 {
+	if x != nil {
+		return x.Position
+	}
+	return nil
+}
+
+// 战斗暂停/继续广播
+type BattlePauseBroadcast struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PlayerId      string                 `protobuf:"bytes,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	IsPause       bool                   `protobuf:"varint,2,opt,name=is_pause,json=isPause,proto3" json:"is_pause,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BattlePauseBroadcast) Reset() {
+	*x = BattlePauseBroadcast{}
+	mi := &file_battle_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BattlePauseBroadcast) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BattlePauseBroadcast) ProtoMessage() {}
+
+func (x *BattlePauseBroadcast) ProtoReflect() protoreflect.Message {
+	mi := &file_battle_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BattlePauseBroadcast.ProtoReflect.Descriptor instead.
+func (*BattlePauseBroadcast) Descriptor() ([]byte, []int) {
+	return file_battle_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *BattlePauseBroadcast) GetPlayerId() string {
+	if x != nil {
+		return x.PlayerId
+	}
+	return ""
+}
+
+func (x *BattlePauseBroadcast) GetIsPause() bool {
+	if x != nil {
+		return x.IsPause
+	}
+	return false
+}
+
 var File_battle_proto protoreflect.FileDescriptor
 
 const file_battle_proto_rawDesc = "" +
@@ -1148,11 +1417,12 @@ const file_battle_proto_rawDesc = "" +
 	"\x0fUseSkillRequest\x12\x19\n" +
 	"\bskill_id\x18\x01 \x01(\x05R\askillId\x121\n" +
 	"\x0ftarget_position\x18\x02 \x01(\v2\b.Vector3R\x0etargetPosition\x12\x1b\n" +
-	"\ttarget_id\x18\x03 \x01(\tR\btargetId\"a\n" +
+	"\ttarget_id\x18\x03 \x01(\tR\btargetId\"u\n" +
 	"\x10UseSkillResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x19\n" +
 	"\bskill_id\x18\x02 \x01(\x05R\askillId\x12\x18\n" +
-	"\amessage\x18\x03 \x01(\tR\amessage\"\x94\x01\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12\x12\n" +
+	"\x04gold\x18\x04 \x01(\x05R\x04gold\"\x94\x01\n" +
 	"\x12WaveStartBroadcast\x12\x19\n" +
 	"\bwave_num\x18\x01 \x01(\x05R\awaveNum\x12\x1f\n" +
 	"\venemy_count\x18\x02 \x01(\x05R\n" +
@@ -1168,7 +1438,7 @@ const file_battle_proto_rawDesc = "" +
 	"\ftotal_damage\x18\x04 \x01(\x05R\vtotalDamage\x12\x1d\n" +
 	"\n" +
 	"is_perfect\x18\x05 \x01(\bR\tisPerfect\x12!\n" +
-	"\fbonus_reward\x18\x06 \x01(\x05R\vbonusReward\"\x9f\x02\n" +
+	"\fbonus_reward\x18\x06 \x01(\x05R\vbonusReward\"\xc3\x02\n" +
 	"\x11GameOverBroadcast\x12\x1d\n" +
 	"\n" +
 	"is_victory\x18\x01 \x01(\bR\tisVictory\x12\x1f\n" +
@@ -1179,7 +1449,8 @@ const file_battle_proto_rawDesc = "" +
 	"\ftotal_damage\x18\x04 \x01(\x03R\vtotalDamage\x12\x14\n" +
 	"\x05score\x18\x05 \x01(\x05R\x05score\x12@\n" +
 	"\fplayer_stats\x18\x06 \x03(\v2\x1d.towerdefense.PlayerGameStatsR\vplayerStats\x120\n" +
-	"\x06reward\x18\a \x01(\v2\x18.towerdefense.GameRewardR\x06reward\"\x88\x02\n" +
+	"\x06reward\x18\a \x01(\v2\x18.towerdefense.GameRewardR\x06reward\x12\"\n" +
+	"\rmvp_player_id\x18\b \x01(\tR\vmvpPlayerId\"\xcc\x02\n" +
 	"\x0fPlayerGameStats\x12\x1b\n" +
 	"\tplayer_id\x18\x01 \x01(\tR\bplayerId\x12\x1f\n" +
 	"\vplayer_name\x18\x02 \x01(\tR\n" +
@@ -1193,7 +1464,11 @@ const file_battle_proto_rawDesc = "" +
 	"goldEarned\x12\x1d\n" +
 	"\n" +
 	"gold_spent\x18\a \x01(\x05R\tgoldSpent\x12\x14\n" +
-	"\x05score\x18\b \x01(\x05R\x05score\"b\n" +
+	"\x05score\x18\b \x01(\x05R\x05score\x12#\n" +
+	"\rupgrade_count\x18\t \x01(\x05R\fupgradeCount\x12\x1d\n" +
+	"\n" +
+	"sell_count\x18\n" +
+	" \x01(\x05R\tsellCount\"b\n" +
 	"\n" +
 	"GameReward\x12\x12\n" +
 	"\x04coin\x18\x01 \x01(\x05R\x04coin\x12\x10\n" +
@@ -1210,7 +1485,24 @@ const file_battle_proto_rawDesc = "" +
 	"\x11PauseGameResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x19\n" +
 	"\bis_pause\x18\x02 \x01(\bR\aisPause\x12\x18\n" +
-	"\amessage\x18\x03 \x01(\tR\amessageB)Z\x12towerdefense/proto\xaa\x02\x12TowerDefense.Protob\x06proto3"
+	"\amessage\x18\x03 \x01(\tR\amessage\"_\n" +
+	"\x14SetTargetModeRequest\x12\x19\n" +
+	"\btower_id\x18\x01 \x01(\tR\atowerId\x12,\n" +
+	"\vtarget_mode\x18\x02 \x01(\x0e2\v.TargetModeR\n" +
+	"targetMode\"\x94\x01\n" +
+	"\x15SetTargetModeResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x19\n" +
+	"\btower_id\x18\x02 \x01(\tR\atowerId\x12,\n" +
+	"\vtarget_mode\x18\x03 \x01(\x0e2\v.TargetModeR\n" +
+	"targetMode\x12\x18\n" +
+	"\amessage\x18\x04 \x01(\tR\amessage\"r\n" +
+	"\x12SkillCastBroadcast\x12\x1b\n" +
+	"\tplayer_id\x18\x01 \x01(\tR\bplayerId\x12\x19\n" +
+	"\bskill_id\x18\x02 \x01(\x05R\askillId\x12$\n" +
+	"\bposition\x18\x03 \x01(\v2\b.Vector3R\bposition\"N\n" +
+	"\x14BattlePauseBroadcast\x12\x1b\n" +
+	"\tplayer_id\x18\x01 \x01(\tR\bplayerId\x12\x19\n" +
+	"\bis_pause\x18\x02 \x01(\bR\aisPauseB)Z\x12towerdefense/proto\xaa\x02\x12TowerDefense.Protob\x06proto3"
 
 var (
 	file_battle_proto_rawDescOnce sync.Once
@@ -1224,7 +1516,7 @@ func file_battle_proto_rawDescGZIP() []byte {
 	return file_battle_proto_rawDescData
 }
 
-var file_battle_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_battle_proto_msgTypes = make([]protoimpl.MessageInfo, 20)
 var file_battle_proto_goTypes = []any{
 	(*PlaceTowerRequest)(nil),     // 0: towerdefense.PlaceTowerRequest
 	(*PlaceTowerResponse)(nil),    // 1: towerdefense.PlaceTowerResponse
@@ -1242,20 +1534,28 @@ var file_battle_proto_goTypes = []any{
 	(*ItemReward)(nil),            // 13: towerdefense.ItemReward
 	(*PauseGameRequest)(nil),      // 14: towerdefense.PauseGameRequest
 	(*PauseGameResponse)(nil),     // 15: towerdefense.PauseGameResponse
-	(*Vector3)(nil),               // 16: Vector3
+	(*SetTargetModeRequest)(nil),  // 16: towerdefense.SetTargetModeRequest
+	(*SetTargetModeResponse)(nil), // 17: towerdefense.SetTargetModeResponse
+	(*SkillCastBroadcast)(nil),    // 18: towerdefense.SkillCastBroadcast
+	(*BattlePauseBroadcast)(nil),  // 19: towerdefense.BattlePauseBroadcast
+	(*Vector3)(nil),               // 20: Vector3
+	(TargetMode)(0),               // 21: TargetMode
 }
 var file_battle_proto_depIdxs = []int32{
-	16, // 0: towerdefense.PlaceTowerRequest.position:type_name -> Vector3
-	16, // 1: towerdefense.PlaceTowerResponse.position:type_name -> Vector3
-	16, // 2: towerdefense.UseSkillRequest.target_position:type_name -> Vector3
+	20, // 0: towerdefense.PlaceTowerRequest.position:type_name -> Vector3
+	20, // 1: towerdefense.PlaceTowerResponse.position:type_name -> Vector3
+	20, // 2: towerdefense.UseSkillRequest.target_position:type_name -> Vector3
 	11, // 3: towerdefense.GameOverBroadcast.player_stats:type_name -> towerdefense.PlayerGameStats
 	12, // 4: towerdefense.GameOverBroadcast.reward:type_name -> towerdefense.GameReward
 	13, // 5: towerdefense.GameReward.items:type_name -> towerdefense.ItemReward
-	6,  // [6:6] is the sub-list for method output_type
-	6,  // [6:6] is the sub-list for method input_type
-	6,  // [6:6] is the sub-list for extension type_name
-	6,  // [6:6] is the sub-list for extension extendee
-	0,  // [0:6] is the sub-list for field type_name
+	21, // 6: towerdefense.SetTargetModeRequest.target_mode:type_name -> TargetMode
+	21, // 7: towerdefense.SetTargetModeResponse.target_mode:type_name -> TargetMode
+	20, // 8: towerdefense.SkillCastBroadcast.position:type_name -> Vector3
+	9,  // [9:9] is the sub-list for method output_type
+	9,  // [9:9] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
 }
 
 func init() { file_battle_proto_init() }
@@ -1270,7 +1570,7 @@ func file_battle_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_battle_proto_rawDesc), len(file_battle_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   16,
+			NumMessages:   20,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -33,12 +33,19 @@ const (
 	Cmd_MSG_LOGOUT_REQ    Cmd = 1004
 	Cmd_MSG_LOGOUT_RSP    Cmd = 1005
 	// 玩家相关 1100-1199
-	Cmd_MSG_GET_PLAYER_DATA_REQ    Cmd = 1100 // 获取玩家数据请求
-	Cmd_MSG_GET_PLAYER_DATA_RSP    Cmd = 1101 // 获取玩家数据响应
-	Cmd_MSG_UPDATE_PLAYER_NAME_REQ Cmd = 1102 // 修改玩家名请求
-	Cmd_MSG_UPDATE_PLAYER_NAME_RSP Cmd = 1103 // 修改玩家名响应
-	Cmd_MSG_UPDATE_PLAYER_ICON_REQ Cmd = 1104 // 修改头像请求
-	Cmd_MSG_UPDATE_PLAYER_ICON_RSP Cmd = 1105 // 修改头像响应
+	Cmd_MSG_GET_PLAYER_DATA_REQ     Cmd = 1100 // 获取玩家数据请求
+	Cmd_MSG_GET_PLAYER_DATA_RSP     Cmd = 1101 // 获取玩家数据响应
+	Cmd_MSG_UPDATE_PLAYER_NAME_REQ  Cmd = 1102 // 修改玩家名请求
+	Cmd_MSG_UPDATE_PLAYER_NAME_RSP  Cmd = 1103 // 修改玩家名响应
+	Cmd_MSG_UPDATE_PLAYER_ICON_REQ  Cmd = 1104 // 修改头像请求
+	Cmd_MSG_UPDATE_PLAYER_ICON_RSP  Cmd = 1105 // 修改头像响应
+	Cmd_MSG_LEVEL_UP_NTF            Cmd = 1106 // 升级通知（服务器主动推送）
+	Cmd_MSG_GET_INVENTORY_REQ       Cmd = 1107 // 获取背包请求
+	Cmd_MSG_GET_INVENTORY_RSP       Cmd = 1108 // 获取背包响应
+	Cmd_MSG_USE_ITEM_REQ            Cmd = 1109 // 使用道具请求
+	Cmd_MSG_USE_ITEM_RSP            Cmd = 1110 // 使用道具响应
+	Cmd_MSG_UPDATE_PLAYER_FRAME_REQ Cmd = 1111 // 修改头像框请求
+	Cmd_MSG_UPDATE_PLAYER_FRAME_RSP Cmd = 1112 // 修改头像框响应
 	// 房间相关 2000-2099
 	Cmd_MSG_CREATE_ROOM_REQ Cmd = 2000
 	Cmd_MSG_CREATE_ROOM_RSP Cmd = 2001
@@ -51,21 +58,31 @@ const (
 	Cmd_MSG_START_GAME_REQ  Cmd = 2008
 	Cmd_MSG_START_GAME_RSP  Cmd = 2009
 	// 战斗相关 3000-3099
-	Cmd_MSG_PLACE_TOWER_REQ   Cmd = 3000
-	Cmd_MSG_PLACE_TOWER_RSP   Cmd = 3001
-	Cmd_MSG_UPGRADE_TOWER_REQ Cmd = 3002
-	Cmd_MSG_UPGRADE_TOWER_RSP Cmd = 3003
-	Cmd_MSG_SELL_TOWER_REQ    Cmd = 3004
-	Cmd_MSG_SELL_TOWER_RSP    Cmd = 3005
-	Cmd_MSG_WAVE_START_REQ    Cmd = 3006
-	Cmd_MSG_WAVE_START_RSP    Cmd = 3007
-	Cmd_MSG_WAVE_COMPLETE_NTF Cmd = 3008 // 服务器通知，无需请求
-	Cmd_MSG_GAME_OVER_NTF     Cmd = 3009 // 服务器通知，无需请求
+	Cmd_MSG_PLACE_TOWER_REQ     Cmd = 3000
+	Cmd_MSG_PLACE_TOWER_RSP     Cmd = 3001
+	Cmd_MSG_UPGRADE_TOWER_REQ   Cmd = 3002
+	Cmd_MSG_UPGRADE_TOWER_RSP   Cmd = 3003
+	Cmd_MSG_SELL_TOWER_REQ      Cmd = 3004
+	Cmd_MSG_SELL_TOWER_RSP      Cmd = 3005
+	Cmd_MSG_WAVE_START_REQ      Cmd = 3006
+	Cmd_MSG_WAVE_START_RSP      Cmd = 3007
+	Cmd_MSG_WAVE_COMPLETE_NTF   Cmd = 3008 // 服务器通知，无需请求
+	Cmd_MSG_GAME_OVER_NTF       Cmd = 3009 // 服务器通知，无需请求
+	Cmd_MSG_SET_TARGET_MODE_REQ Cmd = 3010 // 设置防御塔目标选择模式
+	Cmd_MSG_SET_TARGET_MODE_RSP Cmd = 3011
+	Cmd_MSG_USE_SKILL_REQ       Cmd = 3012 // 释放技能
+	Cmd_MSG_USE_SKILL_RSP       Cmd = 3013
+	Cmd_MSG_PAUSE_GAME_REQ      Cmd = 3014 // 暂停或继续战斗
+	Cmd_MSG_PAUSE_GAME_RSP      Cmd = 3015
 	// 同步相关 4000-4099 (服务器主动推送，使用 NTF 后缀)
-	Cmd_MSG_SYNC_STATE_NTF  Cmd = 4000
-	Cmd_MSG_SYNC_ENEMY_NTF  Cmd = 4001
-	Cmd_MSG_SYNC_TOWER_NTF  Cmd = 4002
-	Cmd_MSG_SYNC_DAMAGE_NTF Cmd = 4003
+	Cmd_MSG_SYNC_STATE_NTF   Cmd = 4000
+	Cmd_MSG_SYNC_ENEMY_NTF   Cmd = 4001
+	Cmd_MSG_SYNC_TOWER_NTF   Cmd = 4002
+	Cmd_MSG_SYNC_DAMAGE_NTF  Cmd = 4003
+	Cmd_MSG_SYNC_BUFF_NTF    Cmd = 4004 // Buff 施加和移除通知
+	Cmd_MSG_PATH_UPDATE_NTF  Cmd = 4005 // 迷宫模式敌人路线变化通知
+	Cmd_MSG_SKILL_CAST_NTF   Cmd = 4006 // 玩家释放技能通知
+	Cmd_MSG_BATTLE_PAUSE_NTF Cmd = 4007 // 战斗暂停/继续通知
 	// 错误消息 9999
 	Cmd_MSG_ERROR Cmd = 9999
 )
@@ -86,6 +103,13 @@ var (
 		1103: "MSG_UPDATE_PLAYER_NAME_RSP",
 		1104: "MSG_UPDATE_PLAYER_ICON_REQ",
 		1105: "MSG_UPDATE_PLAYER_ICON_RSP",
+		1106: "MSG_LEVEL_UP_NTF",
+		1107: "MSG_GET_INVENTORY_REQ",
+		1108: "MSG_GET_INVENTORY_RSP",
+		1109: "MSG_USE_ITEM_REQ",
+		1110: "MSG_USE_ITEM_RSP",
+		1111: "MSG_UPDATE_PLAYER_FRAME_REQ",
+		1112: "MSG_UPDATE_PLAYER_FRAME_RSP",
 		2000: "MSG_CREATE_ROOM_REQ",
 		2001: "MSG_CREATE_ROOM_RSP",
 		2002: "MSG_JOIN_ROOM_REQ",
@@ -106,51 +130,78 @@ var (
 		3007: "MSG_WAVE_START_RSP",
 		3008: "MSG_WAVE_COMPLETE_NTF",
 		3009: "MSG_GAME_OVER_NTF",
+		3010: "MSG_SET_TARGET_MODE_REQ",
+		3011: "MSG_SET_TARGET_MODE_RSP",
+		3012: "MSG_USE_SKILL_REQ",
+		3013: "MSG_USE_SKILL_RSP",
+		3014: "MSG_PAUSE_GAME_REQ",
+		3015: "MSG_PAUSE_GAME_RSP",
 		4000: "MSG_SYNC_STATE_NTF",
 		4001: "MSG_SYNC_ENEMY_NTF",
 		4002: "MSG_SYNC_TOWER_NTF",
 		4003: "MSG_SYNC_DAMAGE_NTF",
+		4004: "MSG_SYNC_BUFF_NTF",
+		4005: "MSG_PATH_UPDATE_NTF",
+		4006: "MSG_SKILL_CAST_NTF",
+		4007: "MSG_BATTLE_PAUSE_NTF",
 		9999: "MSG_ERROR",
 	}
 	Cmd_value = map[string]int32{
-		"MSG_NONE":                   0,
-		"MSG_HEARTBEAT_REQ":          1000,
-		"MSG_HEARTBEAT_RSP":          1001,
-		"MSG_LOGIN_REQ":              1002,
-		"MSG_LOGIN_RSP":              1003,
-		"MSG_LOGOUT_REQ":             1004,
-		"MSG_LOGOUT_RSP":             1005,
-		"MSG_GET_PLAYER_DATA_REQ":    1100,
-		"MSG_GET_PLAYER_DATA_RSP":    1101,
-		"MSG_UPDATE_PLAYER_NAME_REQ": 1102,
-		"MSG_UPDATE_PLAYER_NAME_RSP": 1103,
-		"MSG_UPDATE_PLAYER_ICON_REQ": 1104,
-		"MSG_UPDATE_PLAYER_ICON_RSP": 1105,
-		"MSG_CREATE_ROOM_REQ":        2000,
-		"MSG_CREATE_ROOM_RSP":        2001,
-		"MSG_JOIN_ROOM_REQ":          2002,
-		"MSG_JOIN_ROOM_RSP":          2003,
-		"MSG_LEAVE_ROOM_REQ":         2004,
-		"MSG_LEAVE_ROOM_RSP":         2005,
-		"MSG_ROOM_INFO_REQ":          2006,
-		"MSG_ROOM_INFO_RSP":          2007,
-		"MSG_START_GAME_REQ":         2008,
-		"MSG_START_GAME_RSP":         2009,
-		"MSG_PLACE_TOWER_REQ":        3000,
-		"MSG_PLACE_TOWER_RSP":        3001,
-		"MSG_UPGRADE_TOWER_REQ":      3002,
-		"MSG_UPGRADE_TOWER_RSP":      3003,
-		"MSG_SELL_TOWER_REQ":         3004,
-		"MSG_SELL_TOWER_RSP":         3005,
-		"MSG_WAVE_START_REQ":         3006,
-		"MSG_WAVE_START_RSP":         3007,
-		"MSG_WAVE_COMPLETE_NTF":      3008,
-		"MSG_GAME_OVER_NTF":          3009,
-		"MSG_SYNC_STATE_NTF":         4000,
-		"MSG_SYNC_ENEMY_NTF":         4001,
-		"MSG_SYNC_TOWER_NTF":         4002,
-		"MSG_SYNC_DAMAGE_NTF":        4003,
-		"MSG_ERROR":                  9999,
+		"MSG_NONE":                    0,
+		"MSG_HEARTBEAT_REQ":           1000,
+		"MSG_HEARTBEAT_RSP":           1001,
+		"MSG_LOGIN_REQ":               1002,
+		"MSG_LOGIN_RSP":               1003,
+		"MSG_LOGOUT_REQ":              1004,
+		"MSG_LOGOUT_RSP":              1005,
+		"MSG_GET_PLAYER_DATA_REQ":     1100,
+		"MSG_GET_PLAYER_DATA_RSP":     1101,
+		"MSG_UPDATE_PLAYER_NAME_REQ":  1102,
+		"MSG_UPDATE_PLAYER_NAME_RSP":  1103,
+		"MSG_UPDATE_PLAYER_ICON_REQ":  1104,
+		"MSG_UPDATE_PLAYER_ICON_RSP":  1105,
+		"MSG_LEVEL_UP_NTF":            1106,
+		"MSG_GET_INVENTORY_REQ":       1107,
+		"MSG_GET_INVENTORY_RSP":       1108,
+		"MSG_USE_ITEM_REQ":            1109,
+		"MSG_USE_ITEM_RSP":            1110,
+		"MSG_UPDATE_PLAYER_FRAME_REQ": 1111,
+		"MSG_UPDATE_PLAYER_FRAME_RSP": 1112,
+		"MSG_CREATE_ROOM_REQ":         2000,
+		"MSG_CREATE_ROOM_RSP":         2001,
+		"MSG_JOIN_ROOM_REQ":           2002,
+		"MSG_JOIN_ROOM_RSP":           2003,
+		"MSG_LEAVE_ROOM_REQ":          2004,
+		"MSG_LEAVE_ROOM_RSP":          2005,
+		"MSG_ROOM_INFO_REQ":           2006,
+		"MSG_ROOM_INFO_RSP":           2007,
+		"MSG_START_GAME_REQ":          2008,
+		"MSG_START_GAME_RSP":          2009,
+		"MSG_PLACE_TOWER_REQ":         3000,
+		"MSG_PLACE_TOWER_RSP":         3001,
+		"MSG_UPGRADE_TOWER_REQ":       3002,
+		"MSG_UPGRADE_TOWER_RSP":       3003,
+		"MSG_SELL_TOWER_REQ":          3004,
+		"MSG_SELL_TOWER_RSP":          3005,
+		"MSG_WAVE_START_REQ":          3006,
+		"MSG_WAVE_START_RSP":          3007,
+		"MSG_WAVE_COMPLETE_NTF":       3008,
+		"MSG_GAME_OVER_NTF":           3009,
+		"MSG_SET_TARGET_MODE_REQ":     3010,
+		"MSG_SET_TARGET_MODE_RSP":     3011,
+		"MSG_USE_SKILL_REQ":           3012,
+		"MSG_USE_SKILL_RSP":           3013,
+		"MSG_PAUSE_GAME_REQ":          3014,
+		"MSG_PAUSE_GAME_RSP":          3015,
+		"MSG_SYNC_STATE_NTF":          4000,
+		"MSG_SYNC_ENEMY_NTF":          4001,
+		"MSG_SYNC_TOWER_NTF":          4002,
+		"MSG_SYNC_DAMAGE_NTF":         4003,
+		"MSG_SYNC_BUFF_NTF":           4004,
+		"MSG_PATH_UPDATE_NTF":         4005,
+		"MSG_SKILL_CAST_NTF":          4006,
+		"MSG_BATTLE_PAUSE_NTF":        4007,
+		"MSG_ERROR":                   9999,
 	}
 )
 
@@ -210,6 +261,11 @@ const (
 	ErrorCode_ERROR_TOWER_NOT_FOUND  ErrorCode = 4002
 	ErrorCode_ERROR_GAME_NOT_STARTED ErrorCode = 4003
 	ErrorCode_ERROR_GAME_OVER        ErrorCode = 4004
+	// 玩家错误 5000-5099
+	ErrorCode_ERROR_NOT_ENOUGH_DIAMOND ErrorCode = 5000
+	ErrorCode_ERROR_NAME_INVALID       ErrorCode = 5001 // 名称长度不符或含敏感词、非法字符
+	ErrorCode_ERROR_NAME_TAKEN         ErrorCode = 5002 // 名称已被使用
+	ErrorCode_ERROR_RENAME_COOLDOWN    ErrorCode = 5003 // 改名冷却中
 )
 
 // Enum value maps for ErrorCode.
@@ -236,6 +292,10 @@ var (
 		4002: "ERROR_TOWER_NOT_FOUND",
 		4003: "ERROR_GAME_NOT_STARTED",
 		4004: "ERROR_GAME_OVER",
+		5000: "ERROR_NOT_ENOUGH_DIAMOND",
+		5001: "ERROR_NAME_INVALID",
+		5002: "ERROR_NAME_TAKEN",
+		5003: "ERROR_RENAME_COOLDOWN",
 	}
 	ErrorCode_value = map[string]int32{
 		"ERROR_NONE":                 0,
@@ -259,6 +319,10 @@ var (
 		"ERROR_TOWER_NOT_FOUND":      4002,
 		"ERROR_GAME_NOT_STARTED":     4003,
 		"ERROR_GAME_OVER":            4004,
+		"ERROR_NOT_ENOUGH_DIAMOND":   5000,
+		"ERROR_NAME_INVALID":         5001,
+		"ERROR_NAME_TAKEN":           5002,
+		"ERROR_RENAME_COOLDOWN":      5003,
 	}
 )
 
@@ -289,6 +353,68 @@ func (ErrorCode) EnumDescriptor() ([]byte, []int) {
 	return file_common_proto_rawDescGZIP(), []int{1}
 }
 
+// 防御塔目标选择模式
+type TargetMode int32
+
+const (
+	TargetMode_TARGET_CLOSEST   TargetMode = 0 // 最近的敌人（默认）
+	TargetMode_TARGET_FIRST     TargetMode = 1 // 沿路径走得最远的敌人
+	TargetMode_TARGET_LAST      TargetMode = 2 // 沿路径走得最近的敌人
+	TargetMode_TARGET_STRONGEST TargetMode = 3 // 当前血量最高的敌人
+	TargetMode_TARGET_WEAKEST   TargetMode = 4 // 当前血量最低的敌人
+	TargetMode_TARGET_FASTEST   TargetMode = 5 // 移动速度最快的敌人
+	TargetMode_TARGET_KEEP      TargetMode = 6 // 保持当前目标直到死亡或离开射程，再选最近的敌人
+)
+
+// Enum value maps for TargetMode.
+var (
+	TargetMode_name = map[int32]string{
+		0: "TARGET_CLOSEST",
+		1: "TARGET_FIRST",
+		2: "TARGET_LAST",
+		3: "TARGET_STRONGEST",
+		4: "TARGET_WEAKEST",
+		5: "TARGET_FASTEST",
+		6: "TARGET_KEEP",
+	}
+	TargetMode_value = map[string]int32{
+		"TARGET_CLOSEST":   0,
+		"TARGET_FIRST":     1,
+		"TARGET_LAST":      2,
+		"TARGET_STRONGEST": 3,
+		"TARGET_WEAKEST":   4,
+		"TARGET_FASTEST":   5,
+		"TARGET_KEEP":      6,
+	}
+)
+
+func (x TargetMode) Enum() *TargetMode {
+	p := new(TargetMode)
+	*p = x
+	return p
+}
+
+func (x TargetMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TargetMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_common_proto_enumTypes[2].Descriptor()
+}
+
+func (TargetMode) Type() protoreflect.EnumType {
+	return &file_common_proto_enumTypes[2]
+}
+
+func (x TargetMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TargetMode.Descriptor instead.
+func (TargetMode) EnumDescriptor() ([]byte, []int) {
+	return file_common_proto_rawDescGZIP(), []int{2}
+}
+
 // 三维向量
 type Vector3 struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -507,7 +633,7 @@ const file_common_proto_rawDesc = "" +
 	"\rErrorResponse\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12\x16\n" +
-	"\x06detail\x18\x03 \x01(\tR\x06detail*\xc6\a\n" +
+	"\x06detail\x18\x03 \x01(\tR\x06detail*\x8b\v\n" +
 	"\x03Cmd\x12\f\n" +
 	"\bMSG_NONE\x10\x00\x12\x16\n" +
 	"\x11MSG_HEARTBEAT_REQ\x10\xe8\a\x12\x16\n" +
@@ -521,7 +647,14 @@ const file_common_proto_rawDesc = "" +
 	"\x1aMSG_UPDATE_PLAYER_NAME_REQ\x10\xce\b\x12\x1f\n" +
 	"\x1aMSG_UPDATE_PLAYER_NAME_RSP\x10\xcf\b\x12\x1f\n" +
 	"\x1aMSG_UPDATE_PLAYER_ICON_REQ\x10\xd0\b\x12\x1f\n" +
-	"\x1aMSG_UPDATE_PLAYER_ICON_RSP\x10\xd1\b\x12\x18\n" +
+	"\x1aMSG_UPDATE_PLAYER_ICON_RSP\x10\xd1\b\x12\x15\n" +
+	"\x10MSG_LEVEL_UP_NTF\x10\xd2\b\x12\x1a\n" +
+	"\x15MSG_GET_INVENTORY_REQ\x10\xd3\b\x12\x1a\n" +
+	"\x15MSG_GET_INVENTORY_RSP\x10\xd4\b\x12\x15\n" +
+	"\x10MSG_USE_ITEM_REQ\x10\xd5\b\x12\x15\n" +
+	"\x10MSG_USE_ITEM_RSP\x10\xd6\b\x12 \n" +
+	"\x1bMSG_UPDATE_PLAYER_FRAME_REQ\x10\xd7\b\x12 \n" +
+	"\x1bMSG_UPDATE_PLAYER_FRAME_RSP\x10\xd8\b\x12\x18\n" +
 	"\x13MSG_CREATE_ROOM_REQ\x10\xd0\x0f\x12\x18\n" +
 	"\x13MSG_CREATE_ROOM_RSP\x10\xd1\x0f\x12\x16\n" +
 	"\x11MSG_JOIN_ROOM_REQ\x10\xd2\x0f\x12\x16\n" +
@@ -541,12 +674,22 @@ const file_common_proto_rawDesc = "" +
 	"\x12MSG_WAVE_START_REQ\x10\xbe\x17\x12\x17\n" +
 	"\x12MSG_WAVE_START_RSP\x10\xbf\x17\x12\x1a\n" +
 	"\x15MSG_WAVE_COMPLETE_NTF\x10\xc0\x17\x12\x16\n" +
-	"\x11MSG_GAME_OVER_NTF\x10\xc1\x17\x12\x17\n" +
+	"\x11MSG_GAME_OVER_NTF\x10\xc1\x17\x12\x1c\n" +
+	"\x17MSG_SET_TARGET_MODE_REQ\x10\xc2\x17\x12\x1c\n" +
+	"\x17MSG_SET_TARGET_MODE_RSP\x10\xc3\x17\x12\x16\n" +
+	"\x11MSG_USE_SKILL_REQ\x10\xc4\x17\x12\x16\n" +
+	"\x11MSG_USE_SKILL_RSP\x10\xc5\x17\x12\x17\n" +
+	"\x12MSG_PAUSE_GAME_REQ\x10\xc6\x17\x12\x17\n" +
+	"\x12MSG_PAUSE_GAME_RSP\x10\xc7\x17\x12\x17\n" +
 	"\x12MSG_SYNC_STATE_NTF\x10\xa0\x1f\x12\x17\n" +
 	"\x12MSG_SYNC_ENEMY_NTF\x10\xa1\x1f\x12\x17\n" +
 	"\x12MSG_SYNC_TOWER_NTF\x10\xa2\x1f\x12\x18\n" +
-	"\x13MSG_SYNC_DAMAGE_NTF\x10\xa3\x1f\x12\x0e\n" +
-	"\tMSG_ERROR\x10\x8fN*\x96\x04\n" +
+	"\x13MSG_SYNC_DAMAGE_NTF\x10\xa3\x1f\x12\x16\n" +
+	"\x11MSG_SYNC_BUFF_NTF\x10\xa4\x1f\x12\x18\n" +
+	"\x13MSG_PATH_UPDATE_NTF\x10\xa5\x1f\x12\x17\n" +
+	"\x12MSG_SKILL_CAST_NTF\x10\xa6\x1f\x12\x19\n" +
+	"\x14MSG_BATTLE_PAUSE_NTF\x10\xa7\x1f\x12\x0e\n" +
+	"\tMSG_ERROR\x10\x8fN*\x81\x05\n" +
 	"\tErrorCode\x12\x0e\n" +
 	"\n" +
 	"ERROR_NONE\x10\x00\x12\x12\n" +
@@ -569,7 +712,20 @@ const file_common_proto_rawDesc = "" +
 	"\x16ERROR_INVALID_POSITION\x10\xa1\x1f\x12\x1a\n" +
 	"\x15ERROR_TOWER_NOT_FOUND\x10\xa2\x1f\x12\x1b\n" +
 	"\x16ERROR_GAME_NOT_STARTED\x10\xa3\x1f\x12\x14\n" +
-	"\x0fERROR_GAME_OVER\x10\xa4\x1fB)Z\x12towerdefense/proto\xaa\x02\x12TowerDefense.Protob\x06proto3"
+	"\x0fERROR_GAME_OVER\x10\xa4\x1f\x12\x1d\n" +
+	"\x18ERROR_NOT_ENOUGH_DIAMOND\x10\x88'\x12\x17\n" +
+	"\x12ERROR_NAME_INVALID\x10\x89'\x12\x15\n" +
+	"\x10ERROR_NAME_TAKEN\x10\x8a'\x12\x1a\n" +
+	"\x15ERROR_RENAME_COOLDOWN\x10\x8b'*\x92\x01\n" +
+	"\n" +
+	"TargetMode\x12\x12\n" +
+	"\x0eTARGET_CLOSEST\x10\x00\x12\x10\n" +
+	"\fTARGET_FIRST\x10\x01\x12\x0f\n" +
+	"\vTARGET_LAST\x10\x02\x12\x14\n" +
+	"\x10TARGET_STRONGEST\x10\x03\x12\x12\n" +
+	"\x0eTARGET_WEAKEST\x10\x04\x12\x12\n" +
+	"\x0eTARGET_FASTEST\x10\x05\x12\x0f\n" +
+	"\vTARGET_KEEP\x10\x06B)Z\x12towerdefense/proto\xaa\x02\x12TowerDefense.Protob\x06proto3"
 
 var (
 	file_common_proto_rawDescOnce sync.Once
@@ -583,14 +739,15 @@ func file_common_proto_rawDescGZIP() []byte {
 	return file_common_proto_rawDescData
 }
 
-var file_common_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_common_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
 var file_common_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
 var file_common_proto_goTypes = []any{
 	(Cmd)(0),              // 0: Cmd
 	(ErrorCode)(0),        // 1: ErrorCode
-	(*Vector3)(nil),       // 2: Vector3
-	(*NetworkPacket)(nil), // 3: NetworkPacket
-	(*ErrorResponse)(nil), // 4: ErrorResponse
+	(TargetMode)(0),       // 2: TargetMode
+	(*Vector3)(nil),       // 3: Vector3
+	(*NetworkPacket)(nil), // 4: NetworkPacket
+	(*ErrorResponse)(nil), // 5: ErrorResponse
 }
 var file_common_proto_depIdxs = []int32{
 	0, // [0:0] is the sub-list for method output_type
@@ -610,7 +767,7 @@ func file_common_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_common_proto_rawDesc), len(file_common_proto_rawDesc)),
-			NumEnums:      2,
+			NumEnums:      3,
 			NumMessages:   3,
 			NumExtensions: 0,
 			NumServices:   0,
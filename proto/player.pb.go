@@ -504,6 +504,555 @@ func (x *UpdatePlayerIconResponse) GetIconId() int32 {
 	return 0
 }
 
+// 解锁内容
+type UnlockInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"` // tower, icon, frame, level
+	Id            int32                  `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnlockInfo) Reset() {
+	*x = UnlockInfo{}
+	mi := &file_player_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnlockInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnlockInfo) ProtoMessage() {}
+
+func (x *UnlockInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_player_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnlockInfo.ProtoReflect.Descriptor instead.
+func (*UnlockInfo) Descriptor() ([]byte, []int) {
+	return file_player_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *UnlockInfo) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *UnlockInfo) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UnlockInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// 升级通知
+type LevelUpNotify struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OldLevel      int32                  `protobuf:"varint,1,opt,name=old_level,json=oldLevel,proto3" json:"old_level,omitempty"`
+	NewLevel      int32                  `protobuf:"varint,2,opt,name=new_level,json=newLevel,proto3" json:"new_level,omitempty"`
+	Exp           int32                  `protobuf:"varint,3,opt,name=exp,proto3" json:"exp,omitempty"`                                         // 当前等级经验
+	NextLevelExp  int32                  `protobuf:"varint,4,opt,name=next_level_exp,json=nextLevelExp,proto3" json:"next_level_exp,omitempty"` // 升到下一级所需经验，满级为 0
+	Unlocks       []*UnlockInfo          `protobuf:"bytes,5,rep,name=unlocks,proto3" json:"unlocks,omitempty"`                                  // 本次升级解锁的内容
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LevelUpNotify) Reset() {
+	*x = LevelUpNotify{}
+	mi := &file_player_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LevelUpNotify) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LevelUpNotify) ProtoMessage() {}
+
+func (x *LevelUpNotify) ProtoReflect() protoreflect.Message {
+	mi := &file_player_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LevelUpNotify.ProtoReflect.Descriptor instead.
+func (*LevelUpNotify) Descriptor() ([]byte, []int) {
+	return file_player_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *LevelUpNotify) GetOldLevel() int32 {
+	if x != nil {
+		return x.OldLevel
+	}
+	return 0
+}
+
+func (x *LevelUpNotify) GetNewLevel() int32 {
+	if x != nil {
+		return x.NewLevel
+	}
+	return 0
+}
+
+func (x *LevelUpNotify) GetExp() int32 {
+	if x != nil {
+		return x.Exp
+	}
+	return 0
+}
+
+func (x *LevelUpNotify) GetNextLevelExp() int32 {
+	if x != nil {
+		return x.NextLevelExp
+	}
+	return 0
+}
+
+func (x *LevelUpNotify) GetUnlocks() []*UnlockInfo {
+	if x != nil {
+		return x.Unlocks
+	}
+	return nil
+}
+
+// 背包道具
+type ItemInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ItemId        int32                  `protobuf:"varint,1,opt,name=item_id,json=itemId,proto3" json:"item_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Category      string                 `protobuf:"bytes,3,opt,name=category,proto3" json:"category,omitempty"` // consumable, material
+	Count         int32                  `protobuf:"varint,4,opt,name=count,proto3" json:"count,omitempty"`
+	ExpireTime    int64                  `protobuf:"varint,5,opt,name=expire_time,json=expireTime,proto3" json:"expire_time,omitempty"` // 过期时间戳，0 表示永久
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ItemInfo) Reset() {
+	*x = ItemInfo{}
+	mi := &file_player_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ItemInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ItemInfo) ProtoMessage() {}
+
+func (x *ItemInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_player_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ItemInfo.ProtoReflect.Descriptor instead.
+func (*ItemInfo) Descriptor() ([]byte, []int) {
+	return file_player_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ItemInfo) GetItemId() int32 {
+	if x != nil {
+		return x.ItemId
+	}
+	return 0
+}
+
+func (x *ItemInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ItemInfo) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *ItemInfo) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *ItemInfo) GetExpireTime() int64 {
+	if x != nil {
+		return x.ExpireTime
+	}
+	return 0
+}
+
+// 获取背包请求
+type GetInventoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetInventoryRequest) Reset() {
+	*x = GetInventoryRequest{}
+	mi := &file_player_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetInventoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetInventoryRequest) ProtoMessage() {}
+
+func (x *GetInventoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_player_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetInventoryRequest.ProtoReflect.Descriptor instead.
+func (*GetInventoryRequest) Descriptor() ([]byte, []int) {
+	return file_player_proto_rawDescGZIP(), []int{10}
+}
+
+// 获取背包响应
+type GetInventoryResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Success        bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message        string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Items          []*ItemInfo            `protobuf:"bytes,3,rep,name=items,proto3" json:"items,omitempty"`
+	StartGoldBonus int32                  `protobuf:"varint,4,opt,name=start_gold_bonus,json=startGoldBonus,proto3" json:"start_gold_bonus,omitempty"` // 已生效、下一场战斗额外获得的初始金币
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GetInventoryResponse) Reset() {
+	*x = GetInventoryResponse{}
+	mi := &file_player_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetInventoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetInventoryResponse) ProtoMessage() {}
+
+func (x *GetInventoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_player_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetInventoryResponse.ProtoReflect.Descriptor instead.
+func (*GetInventoryResponse) Descriptor() ([]byte, []int) {
+	return file_player_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetInventoryResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *GetInventoryResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *GetInventoryResponse) GetItems() []*ItemInfo {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *GetInventoryResponse) GetStartGoldBonus() int32 {
+	if x != nil {
+		return x.StartGoldBonus
+	}
+	return 0
+}
+
+// 使用道具请求
+type UseItemRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ItemId        int32                  `protobuf:"varint,1,opt,name=item_id,json=itemId,proto3" json:"item_id,omitempty"`
+	Count         int32                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"` // 使用数量，0 按 1 处理
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UseItemRequest) Reset() {
+	*x = UseItemRequest{}
+	mi := &file_player_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UseItemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UseItemRequest) ProtoMessage() {}
+
+func (x *UseItemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_player_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UseItemRequest.ProtoReflect.Descriptor instead.
+func (*UseItemRequest) Descriptor() ([]byte, []int) {
+	return file_player_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *UseItemRequest) GetItemId() int32 {
+	if x != nil {
+		return x.ItemId
+	}
+	return 0
+}
+
+func (x *UseItemRequest) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+// 使用道具响应
+type UseItemResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	ItemId        int32                  `protobuf:"varint,3,opt,name=item_id,json=itemId,proto3" json:"item_id,omitempty"`
+	RemainCount   int32                  `protobuf:"varint,4,opt,name=remain_count,json=remainCount,proto3" json:"remain_count,omitempty"` // 剩余数量
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UseItemResponse) Reset() {
+	*x = UseItemResponse{}
+	mi := &file_player_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UseItemResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UseItemResponse) ProtoMessage() {}
+
+func (x *UseItemResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_player_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UseItemResponse.ProtoReflect.Descriptor instead.
+func (*UseItemResponse) Descriptor() ([]byte, []int) {
+	return file_player_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *UseItemResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *UseItemResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *UseItemResponse) GetItemId() int32 {
+	if x != nil {
+		return x.ItemId
+	}
+	return 0
+}
+
+func (x *UseItemResponse) GetRemainCount() int32 {
+	if x != nil {
+		return x.RemainCount
+	}
+	return 0
+}
+
+// 修改头像框请求
+type UpdatePlayerFrameRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FrameId       int32                  `protobuf:"varint,1,opt,name=frame_id,json=frameId,proto3" json:"frame_id,omitempty"` // 新头像框ID
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdatePlayerFrameRequest) Reset() {
+	*x = UpdatePlayerFrameRequest{}
+	mi := &file_player_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdatePlayerFrameRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdatePlayerFrameRequest) ProtoMessage() {}
+
+func (x *UpdatePlayerFrameRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_player_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdatePlayerFrameRequest.ProtoReflect.Descriptor instead.
+func (*UpdatePlayerFrameRequest) Descriptor() ([]byte, []int) {
+	return file_player_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *UpdatePlayerFrameRequest) GetFrameId() int32 {
+	if x != nil {
+		return x.FrameId
+	}
+	return 0
+}
+
+// 修改头像框响应
+type UpdatePlayerFrameResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	FrameId       int32                  `protobuf:"varint,3,opt,name=frame_id,json=frameId,proto3" json:"frame_id,omitempty"` // 修改后的头像框ID
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdatePlayerFrameResponse) Reset() {
+	*x = UpdatePlayerFrameResponse{}
+	mi := &file_player_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdatePlayerFrameResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdatePlayerFrameResponse) ProtoMessage() {}
+
+func (x *UpdatePlayerFrameResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_player_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdatePlayerFrameResponse.ProtoReflect.Descriptor instead.
+func (*UpdatePlayerFrameResponse) Descriptor() ([]byte, []int) {
+	return file_player_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *UpdatePlayerFrameResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *UpdatePlayerFrameResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *UpdatePlayerFrameResponse) GetFrameId() int32 {
+	if x != nil {
+		return x.FrameId
+	}
+	return 0
+}
+
 var File_player_proto protoreflect.FileDescriptor
 
 const file_player_proto_rawDesc = "" +
@@ -550,7 +1099,45 @@ const file_player_proto_rawDesc = "" +
 	"\x18UpdatePlayerIconResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12\x17\n" +
-	"\aicon_id\x18\x03 \x01(\x05R\x06iconIdB)Z\x12towerdefense/proto\xaa\x02\x12TowerDefense.Protob\x06proto3"
+	"\aicon_id\x18\x03 \x01(\x05R\x06iconId\"D\n" +
+	"\n" +
+	"UnlockInfo\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\x05R\x02id\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\"\xa8\x01\n" +
+	"\rLevelUpNotify\x12\x1b\n" +
+	"\told_level\x18\x01 \x01(\x05R\boldLevel\x12\x1b\n" +
+	"\tnew_level\x18\x02 \x01(\x05R\bnewLevel\x12\x10\n" +
+	"\x03exp\x18\x03 \x01(\x05R\x03exp\x12$\n" +
+	"\x0enext_level_exp\x18\x04 \x01(\x05R\fnextLevelExp\x12%\n" +
+	"\aunlocks\x18\x05 \x03(\v2\v.UnlockInfoR\aunlocks\"\x8a\x01\n" +
+	"\bItemInfo\x12\x17\n" +
+	"\aitem_id\x18\x01 \x01(\x05R\x06itemId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1a\n" +
+	"\bcategory\x18\x03 \x01(\tR\bcategory\x12\x14\n" +
+	"\x05count\x18\x04 \x01(\x05R\x05count\x12\x1f\n" +
+	"\vexpire_time\x18\x05 \x01(\x03R\n" +
+	"expireTime\"\x15\n" +
+	"\x13GetInventoryRequest\"\x95\x01\n" +
+	"\x14GetInventoryResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1f\n" +
+	"\x05items\x18\x03 \x03(\v2\t.ItemInfoR\x05items\x12(\n" +
+	"\x10start_gold_bonus\x18\x04 \x01(\x05R\x0estartGoldBonus\"?\n" +
+	"\x0eUseItemRequest\x12\x17\n" +
+	"\aitem_id\x18\x01 \x01(\x05R\x06itemId\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x05R\x05count\"\x81\x01\n" +
+	"\x0fUseItemResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x17\n" +
+	"\aitem_id\x18\x03 \x01(\x05R\x06itemId\x12!\n" +
+	"\fremain_count\x18\x04 \x01(\x05R\vremainCount\"5\n" +
+	"\x18UpdatePlayerFrameRequest\x12\x19\n" +
+	"\bframe_id\x18\x01 \x01(\x05R\aframeId\"j\n" +
+	"\x19UpdatePlayerFrameResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x19\n" +
+	"\bframe_id\x18\x03 \x01(\x05R\aframeIdB)Z\x12towerdefense/proto\xaa\x02\x12TowerDefense.Protob\x06proto3"
 
 var (
 	file_player_proto_rawDescOnce sync.Once
@@ -564,23 +1151,34 @@ func file_player_proto_rawDescGZIP() []byte {
 	return file_player_proto_rawDescData
 }
 
-var file_player_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_player_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
 var file_player_proto_goTypes = []any{
-	(*PlayerData)(nil),               // 0: PlayerData
-	(*GetPlayerDataRequest)(nil),     // 1: GetPlayerDataRequest
-	(*GetPlayerDataResponse)(nil),    // 2: GetPlayerDataResponse
-	(*UpdatePlayerNameRequest)(nil),  // 3: UpdatePlayerNameRequest
-	(*UpdatePlayerNameResponse)(nil), // 4: UpdatePlayerNameResponse
-	(*UpdatePlayerIconRequest)(nil),  // 5: UpdatePlayerIconRequest
-	(*UpdatePlayerIconResponse)(nil), // 6: UpdatePlayerIconResponse
+	(*PlayerData)(nil),                // 0: PlayerData
+	(*GetPlayerDataRequest)(nil),      // 1: GetPlayerDataRequest
+	(*GetPlayerDataResponse)(nil),     // 2: GetPlayerDataResponse
+	(*UpdatePlayerNameRequest)(nil),   // 3: UpdatePlayerNameRequest
+	(*UpdatePlayerNameResponse)(nil),  // 4: UpdatePlayerNameResponse
+	(*UpdatePlayerIconRequest)(nil),   // 5: UpdatePlayerIconRequest
+	(*UpdatePlayerIconResponse)(nil),  // 6: UpdatePlayerIconResponse
+	(*UnlockInfo)(nil),                // 7: UnlockInfo
+	(*LevelUpNotify)(nil),             // 8: LevelUpNotify
+	(*ItemInfo)(nil),                  // 9: ItemInfo
+	(*GetInventoryRequest)(nil),       // 10: GetInventoryRequest
+	(*GetInventoryResponse)(nil),      // 11: GetInventoryResponse
+	(*UseItemRequest)(nil),            // 12: UseItemRequest
+	(*UseItemResponse)(nil),           // 13: UseItemResponse
+	(*UpdatePlayerFrameRequest)(nil),  // 14: UpdatePlayerFrameRequest
+	(*UpdatePlayerFrameResponse)(nil), // 15: UpdatePlayerFrameResponse
 }
 var file_player_proto_depIdxs = []int32{
 	0, // 0: GetPlayerDataResponse.player_data:type_name -> PlayerData
-	1, // [1:1] is the sub-list for method output_type
-	1, // [1:1] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	7, // 1: LevelUpNotify.unlocks:type_name -> UnlockInfo
+	9, // 2: GetInventoryResponse.items:type_name -> ItemInfo
+	3, // [3:3] is the sub-list for method output_type
+	3, // [3:3] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_player_proto_init() }
@@ -594,7 +1192,7 @@ func file_player_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_player_proto_rawDesc), len(file_player_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   7,
+			NumMessages:   16,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -903,6 +903,7 @@ type GameInitData struct {
 	WaveInfo      []*WaveInfo            `protobuf:"bytes,4,rep,name=wave_info,json=waveInfo,proto3" json:"wave_info,omitempty"`
 	PathPoints    []*Vector3             `protobuf:"bytes,5,rep,name=path_points,json=pathPoints,proto3" json:"path_points,omitempty"` // 路径点
 	TotalWaves    int32                  `protobuf:"varint,6,opt,name=total_waves,json=totalWaves,proto3" json:"total_waves,omitempty"`
+	Paths         []*PathInfo            `protobuf:"bytes,7,rep,name=paths,proto3" json:"paths,omitempty"` // 全部路径（多出生点/分支），path_points 为第一条路径
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -979,6 +980,13 @@ func (x *GameInitData) GetTotalWaves() int32 {
 	return 0
 }
 
+func (x *GameInitData) GetPaths() []*PathInfo {
+	if x != nil {
+		return x.Paths
+	}
+	return nil
+}
+
 // 开始游戏响应
 type StartGameResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -1186,6 +1194,104 @@ func (x *RoomListResponse) GetPageSize() int32 {
 	return 0
 }
 
+// 敌人路径（第一个点为出生点，最后一个点为终点）
+type PathInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Points        []*Vector3             `protobuf:"bytes,2,rep,name=points,proto3" json:"points,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PathInfo) Reset() {
+	*x = PathInfo{}
+	mi := &file_room_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PathInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PathInfo) ProtoMessage() {}
+
+func (x *PathInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_room_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PathInfo.ProtoReflect.Descriptor instead.
+func (*PathInfo) Descriptor() ([]byte, []int) {
+	return file_room_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *PathInfo) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *PathInfo) GetPoints() []*Vector3 {
+	if x != nil {
+		return x.Points
+	}
+	return nil
+}
+
+// 迷宫模式下建造或出售防御塔后敌人路线的变化通知（包含全部路径的当前路线）
+type PathUpdateBroadcast struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Paths         []*PathInfo            `protobuf:"bytes,1,rep,name=paths,proto3" json:"paths,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PathUpdateBroadcast) Reset() {
+	*x = PathUpdateBroadcast{}
+	mi := &file_room_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PathUpdateBroadcast) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PathUpdateBroadcast) ProtoMessage() {}
+
+func (x *PathUpdateBroadcast) ProtoReflect() protoreflect.Message {
+	mi := &file_room_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PathUpdateBroadcast.ProtoReflect.Descriptor instead.
+func (*PathUpdateBroadcast) Descriptor() ([]byte, []int) {
+	return file_room_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *PathUpdateBroadcast) GetPaths() []*PathInfo {
+	if x != nil {
+		return x.Paths
+	}
+	return nil
+}
+
 var File_room_proto protoreflect.FileDescriptor
 
 const file_room_proto_rawDesc = "" +
@@ -1265,7 +1371,7 @@ const file_room_proto_rawDesc = "" +
 	"\x06reward\x18\x04 \x01(\x05R\x06reward\x12\x1e\n" +
 	"\n" +
 	"difficulty\x18\x05 \x01(\x05R\n" +
-	"difficulty\"\xd2\x01\n" +
+	"difficulty\"\x80\x02\n" +
 	"\fGameInitData\x12\x12\n" +
 	"\x04gold\x18\x01 \x01(\x05R\x04gold\x12\x12\n" +
 	"\x04life\x18\x02 \x01(\x05R\x04life\x12\x19\n" +
@@ -1274,7 +1380,8 @@ const file_room_proto_rawDesc = "" +
 	"\vpath_points\x18\x05 \x03(\v2\b.Vector3R\n" +
 	"pathPoints\x12\x1f\n" +
 	"\vtotal_waves\x18\x06 \x01(\x05R\n" +
-	"totalWaves\"\xba\x01\n" +
+	"totalWaves\x12,\n" +
+	"\x05paths\x18\a \x03(\v2\x16.towerdefense.PathInfoR\x05paths\"\xba\x01\n" +
 	"\x11StartGameResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x19\n" +
 	"\blevel_id\x18\x02 \x01(\x05R\alevelId\x127\n" +
@@ -1290,7 +1397,12 @@ const file_room_proto_rawDesc = "" +
 	"\x05rooms\x18\x01 \x03(\v2\x16.towerdefense.RoomInfoR\x05rooms\x12\x14\n" +
 	"\x05total\x18\x02 \x01(\x05R\x05total\x12\x12\n" +
 	"\x04page\x18\x03 \x01(\x05R\x04page\x12\x1b\n" +
-	"\tpage_size\x18\x04 \x01(\x05R\bpageSizeB)Z\x12towerdefense/proto\xaa\x02\x12TowerDefense.Protob\x06proto3"
+	"\tpage_size\x18\x04 \x01(\x05R\bpageSize\"<\n" +
+	"\bPathInfo\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12 \n" +
+	"\x06points\x18\x02 \x03(\v2\b.Vector3R\x06points\"C\n" +
+	"\x13PathUpdateBroadcast\x12,\n" +
+	"\x05paths\x18\x01 \x03(\v2\x16.towerdefense.PathInfoR\x05pathsB)Z\x12towerdefense/proto\xaa\x02\x12TowerDefense.Protob\x06proto3"
 
 var (
 	file_room_proto_rawDescOnce sync.Once
@@ -1304,26 +1416,28 @@ func file_room_proto_rawDescGZIP() []byte {
 	return file_room_proto_rawDescData
 }
 
-var file_room_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_room_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
 var file_room_proto_goTypes = []any{
-	(*PlayerInfo)(nil),         // 0: towerdefense.PlayerInfo
-	(*RoomInfo)(nil),           // 1: towerdefense.RoomInfo
-	(*CreateRoomRequest)(nil),  // 2: towerdefense.CreateRoomRequest
-	(*CreateRoomResponse)(nil), // 3: towerdefense.CreateRoomResponse
-	(*JoinRoomRequest)(nil),    // 4: towerdefense.JoinRoomRequest
-	(*JoinRoomResponse)(nil),   // 5: towerdefense.JoinRoomResponse
-	(*LeaveRoomRequest)(nil),   // 6: towerdefense.LeaveRoomRequest
-	(*LeaveRoomResponse)(nil),  // 7: towerdefense.LeaveRoomResponse
-	(*RoomInfoBroadcast)(nil),  // 8: towerdefense.RoomInfoBroadcast
-	(*ReadyRequest)(nil),       // 9: towerdefense.ReadyRequest
-	(*ReadyResponse)(nil),      // 10: towerdefense.ReadyResponse
-	(*StartGameRequest)(nil),   // 11: towerdefense.StartGameRequest
-	(*WaveInfo)(nil),           // 12: towerdefense.WaveInfo
-	(*GameInitData)(nil),       // 13: towerdefense.GameInitData
-	(*StartGameResponse)(nil),  // 14: towerdefense.StartGameResponse
-	(*RoomListRequest)(nil),    // 15: towerdefense.RoomListRequest
-	(*RoomListResponse)(nil),   // 16: towerdefense.RoomListResponse
-	(*Vector3)(nil),            // 17: Vector3
+	(*PlayerInfo)(nil),          // 0: towerdefense.PlayerInfo
+	(*RoomInfo)(nil),            // 1: towerdefense.RoomInfo
+	(*CreateRoomRequest)(nil),   // 2: towerdefense.CreateRoomRequest
+	(*CreateRoomResponse)(nil),  // 3: towerdefense.CreateRoomResponse
+	(*JoinRoomRequest)(nil),     // 4: towerdefense.JoinRoomRequest
+	(*JoinRoomResponse)(nil),    // 5: towerdefense.JoinRoomResponse
+	(*LeaveRoomRequest)(nil),    // 6: towerdefense.LeaveRoomRequest
+	(*LeaveRoomResponse)(nil),   // 7: towerdefense.LeaveRoomResponse
+	(*RoomInfoBroadcast)(nil),   // 8: towerdefense.RoomInfoBroadcast
+	(*ReadyRequest)(nil),        // 9: towerdefense.ReadyRequest
+	(*ReadyResponse)(nil),       // 10: towerdefense.ReadyResponse
+	(*StartGameRequest)(nil),    // 11: towerdefense.StartGameRequest
+	(*WaveInfo)(nil),            // 12: towerdefense.WaveInfo
+	(*GameInitData)(nil),        // 13: towerdefense.GameInitData
+	(*StartGameResponse)(nil),   // 14: towerdefense.StartGameResponse
+	(*RoomListRequest)(nil),     // 15: towerdefense.RoomListRequest
+	(*RoomListResponse)(nil),    // 16: towerdefense.RoomListResponse
+	(*PathInfo)(nil),            // 17: towerdefense.PathInfo
+	(*PathUpdateBroadcast)(nil), // 18: towerdefense.PathUpdateBroadcast
+	(*Vector3)(nil),             // 19: Vector3
 }
 var file_room_proto_depIdxs = []int32{
 	0,  // 0: towerdefense.RoomInfo.players:type_name -> towerdefense.PlayerInfo
@@ -1331,14 +1445,17 @@ var file_room_proto_depIdxs = []int32{
 	1,  // 2: towerdefense.JoinRoomResponse.room_info:type_name -> towerdefense.RoomInfo
 	1,  // 3: towerdefense.RoomInfoBroadcast.room_info:type_name -> towerdefense.RoomInfo
 	12, // 4: towerdefense.GameInitData.wave_info:type_name -> towerdefense.WaveInfo
-	17, // 5: towerdefense.GameInitData.path_points:type_name -> Vector3
-	13, // 6: towerdefense.StartGameResponse.game_data:type_name -> towerdefense.GameInitData
-	1,  // 7: towerdefense.RoomListResponse.rooms:type_name -> towerdefense.RoomInfo
-	8,  // [8:8] is the sub-list for method output_type
-	8,  // [8:8] is the sub-list for method input_type
-	8,  // [8:8] is the sub-list for extension type_name
-	8,  // [8:8] is the sub-list for extension extendee
-	0,  // [0:8] is the sub-list for field type_name
+	19, // 5: towerdefense.GameInitData.path_points:type_name -> Vector3
+	17, // 6: towerdefense.GameInitData.paths:type_name -> towerdefense.PathInfo
+	13, // 7: towerdefense.StartGameResponse.game_data:type_name -> towerdefense.GameInitData
+	1,  // 8: towerdefense.RoomListResponse.rooms:type_name -> towerdefense.RoomInfo
+	19, // 9: towerdefense.PathInfo.points:type_name -> Vector3
+	17, // 10: towerdefense.PathUpdateBroadcast.paths:type_name -> towerdefense.PathInfo
+	11, // [11:11] is the sub-list for method output_type
+	11, // [11:11] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
 }
 
 func init() { file_room_proto_init() }
@@ -1353,7 +1470,7 @@ func file_room_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_room_proto_rawDesc), len(file_room_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   17,
+			NumMessages:   19,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -34,6 +34,8 @@ type EnemyState struct {
 	PathProgress  float32                `protobuf:"fixed32,8,opt,name=path_progress,json=pathProgress,proto3" json:"path_progress,omitempty"` // 路径进度 0-1
 	Buffs         []int32                `protobuf:"varint,9,rep,packed,name=buffs,proto3" json:"buffs,omitempty"`                             // Buff列表
 	Debuffs       []int32                `protobuf:"varint,10,rep,packed,name=debuffs,proto3" json:"debuffs,omitempty"`                        // Debuff列表
+	VisualId      int32                  `protobuf:"varint,11,opt,name=visual_id,json=visualId,proto3" json:"visual_id,omitempty"`             // 客户端模型/特效ID
+	Flying        bool                   `protobuf:"varint,12,opt,name=flying,proto3" json:"flying,omitempty"`                                 // 飞行单位
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -138,6 +140,20 @@ func (x *EnemyState) GetDebuffs() []int32 {
 	return nil
 }
 
+func (x *EnemyState) GetVisualId() int32 {
+	if x != nil {
+		return x.VisualId
+	}
+	return 0
+}
+
+func (x *EnemyState) GetFlying() bool {
+	if x != nil {
+		return x.Flying
+	}
+	return false
+}
+
 // 防御塔状态
 type TowerState struct {
 	state          protoimpl.MessageState `protogen:"open.v1"`
@@ -145,11 +161,13 @@ type TowerState struct {
 	Type           int32                  `protobuf:"varint,2,opt,name=type,proto3" json:"type,omitempty"`
 	Level          int32                  `protobuf:"varint,3,opt,name=level,proto3" json:"level,omitempty"`
 	Position       *Vector3               `protobuf:"bytes,4,opt,name=position,proto3" json:"position,omitempty"`
-	TargetId       string                 `protobuf:"bytes,5,opt,name=target_id,json=targetId,proto3" json:"target_id,omitempty"`                     // 当前目标ID
-	AttackCooldown float32                `protobuf:"fixed32,6,opt,name=attack_cooldown,json=attackCooldown,proto3" json:"attack_cooldown,omitempty"` // 攻击冷却剩余时间
-	TotalDamage    int32                  `protobuf:"varint,7,opt,name=total_damage,json=totalDamage,proto3" json:"total_damage,omitempty"`           // 累计伤害
-	KillCount      int32                  `protobuf:"varint,8,opt,name=kill_count,json=killCount,proto3" json:"kill_count,omitempty"`                 // 击杀数
-	OwnerId        string                 `protobuf:"bytes,9,opt,name=owner_id,json=ownerId,proto3" json:"owner_id,omitempty"`                        // 所属玩家ID
+	TargetId       string                 `protobuf:"bytes,5,opt,name=target_id,json=targetId,proto3" json:"target_id,omitempty"`                         // 当前目标ID
+	AttackCooldown float32                `protobuf:"fixed32,6,opt,name=attack_cooldown,json=attackCooldown,proto3" json:"attack_cooldown,omitempty"`     // 攻击冷却剩余时间
+	TotalDamage    int32                  `protobuf:"varint,7,opt,name=total_damage,json=totalDamage,proto3" json:"total_damage,omitempty"`               // 累计伤害
+	KillCount      int32                  `protobuf:"varint,8,opt,name=kill_count,json=killCount,proto3" json:"kill_count,omitempty"`                     // 击杀数
+	OwnerId        string                 `protobuf:"bytes,9,opt,name=owner_id,json=ownerId,proto3" json:"owner_id,omitempty"`                            // 所属玩家ID
+	TargetMode     TargetMode             `protobuf:"varint,10,opt,name=target_mode,json=targetMode,proto3,enum=TargetMode" json:"target_mode,omitempty"` // 目标选择模式
+	Buffs          []int32                `protobuf:"varint,11,rep,packed,name=buffs,proto3" json:"buffs,omitempty"`                                      // Buff列表
 	unknownFields  protoimpl.UnknownFields
 	sizeCache      protoimpl.SizeCache
 }
@@ -247,6 +265,20 @@ func (x *TowerState) GetOwnerId() string {
 	return ""
 }
 
+func (x *TowerState) GetTargetMode() TargetMode {
+	if x != nil {
+		return x.TargetMode
+	}
+	return TargetMode_TARGET_CLOSEST
+}
+
+func (x *TowerState) GetBuffs() []int32 {
+	if x != nil {
+		return x.Buffs
+	}
+	return nil
+}
+
 // 状态同步广播
 type SyncStateBroadcast struct {
 	state            protoimpl.MessageState `protogen:"open.v1"`
@@ -455,7 +487,7 @@ type SyncDamageBroadcast struct {
 	IsCrit        bool                   `protobuf:"varint,4,opt,name=is_crit,json=isCrit,proto3" json:"is_crit,omitempty"`
 	IsKill        bool                   `protobuf:"varint,5,opt,name=is_kill,json=isKill,proto3" json:"is_kill,omitempty"`
 	Position      *Vector3               `protobuf:"bytes,6,opt,name=position,proto3" json:"position,omitempty"`                        // 伤害位置
-	DamageType    int32                  `protobuf:"varint,7,opt,name=damage_type,json=damageType,proto3" json:"damage_type,omitempty"` // 伤害类型: 1=物理 2=魔法 3=真实
+	DamageType    int32                  `protobuf:"varint,7,opt,name=damage_type,json=damageType,proto3" json:"damage_type,omitempty"` // 伤害类型: 1=物理 2=魔法 3=真实 4=溅射
 	Effects       []int32                `protobuf:"varint,8,rep,packed,name=effects,proto3" json:"effects,omitempty"`                  // 伤害特效列表
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -1046,6 +1078,7 @@ type BuffState struct {
 	Duration      int32                  `protobuf:"varint,3,opt,name=duration,proto3" json:"duration,omitempty"`                       // 持续时间(ms)
 	StackCount    int32                  `protobuf:"varint,4,opt,name=stack_count,json=stackCount,proto3" json:"stack_count,omitempty"` // 叠加层数
 	SourceId      string                 `protobuf:"bytes,5,opt,name=source_id,json=sourceId,proto3" json:"source_id,omitempty"`        // 来源ID
+	InstanceId    string                 `protobuf:"bytes,6,opt,name=instance_id,json=instanceId,proto3" json:"instance_id,omitempty"`  // Buff实例ID（与 removed_buff_ids 对应）
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -1115,6 +1148,13 @@ func (x *BuffState) GetSourceId() string {
 	return ""
 }
 
+func (x *BuffState) GetInstanceId() string {
+	if x != nil {
+		return x.InstanceId
+	}
+	return ""
+}
+
 // Buff同步通知
 type BuffSyncNotify struct {
 	state          protoimpl.MessageState `protogen:"open.v1"`
@@ -1173,7 +1213,7 @@ var File_sync_proto protoreflect.FileDescriptor
 const file_sync_proto_rawDesc = "" +
 	"\n" +
 	"\n" +
-	"sync.proto\x12\ftowerdefense\x1a\fcommon.proto\"\x92\x02\n" +
+	"sync.proto\x12\ftowerdefense\x1a\fcommon.proto\"\xc7\x02\n" +
 	"\n" +
 	"EnemyState\x12\x19\n" +
 	"\benemy_id\x18\x01 \x01(\tR\aenemyId\x12\x12\n" +
@@ -1187,7 +1227,9 @@ const file_sync_proto_rawDesc = "" +
 	"\rpath_progress\x18\b \x01(\x02R\fpathProgress\x12\x14\n" +
 	"\x05buffs\x18\t \x03(\x05R\x05buffs\x12\x18\n" +
 	"\adebuffs\x18\n" +
-	" \x03(\x05R\adebuffs\"\x9a\x02\n" +
+	" \x03(\x05R\adebuffs\x12\x1b\n" +
+	"\tvisual_id\x18\v \x01(\x05R\bvisualId\x12\x16\n" +
+	"\x06flying\x18\f \x01(\bR\x06flying\"\xde\x02\n" +
 	"\n" +
 	"TowerState\x12\x19\n" +
 	"\btower_id\x18\x01 \x01(\tR\atowerId\x12\x12\n" +
@@ -1199,7 +1241,11 @@ const file_sync_proto_rawDesc = "" +
 	"\ftotal_damage\x18\a \x01(\x05R\vtotalDamage\x12\x1d\n" +
 	"\n" +
 	"kill_count\x18\b \x01(\x05R\tkillCount\x12\x19\n" +
-	"\bowner_id\x18\t \x01(\tR\aownerId\"\x88\x02\n" +
+	"\bowner_id\x18\t \x01(\tR\aownerId\x12,\n" +
+	"\vtarget_mode\x18\n" +
+	" \x01(\x0e2\v.TargetModeR\n" +
+	"targetMode\x12\x14\n" +
+	"\x05buffs\x18\v \x03(\x05R\x05buffs\"\x88\x02\n" +
 	"\x12SyncStateBroadcast\x12\x12\n" +
 	"\x04gold\x18\x01 \x01(\x05R\x04gold\x12\x12\n" +
 	"\x04life\x18\x02 \x01(\x05R\x04life\x12\x19\n" +
@@ -1265,14 +1311,16 @@ const file_sync_proto_rawDesc = "" +
 	"\x18RequestGameStateResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12;\n" +
 	"\bsnapshot\x18\x02 \x01(\v2\x1f.towerdefense.GameStateSnapshotR\bsnapshot\x12\x18\n" +
-	"\amessage\x18\x03 \x01(\tR\amessage\"\x9b\x01\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\"\xbc\x01\n" +
 	"\tBuffState\x12\x17\n" +
 	"\abuff_id\x18\x01 \x01(\x05R\x06buffId\x12\x1b\n" +
 	"\ttarget_id\x18\x02 \x01(\tR\btargetId\x12\x1a\n" +
 	"\bduration\x18\x03 \x01(\x05R\bduration\x12\x1f\n" +
 	"\vstack_count\x18\x04 \x01(\x05R\n" +
 	"stackCount\x12\x1b\n" +
-	"\tsource_id\x18\x05 \x01(\tR\bsourceId\"i\n" +
+	"\tsource_id\x18\x05 \x01(\tR\bsourceId\x12\x1f\n" +
+	"\vinstance_id\x18\x06 \x01(\tR\n" +
+	"instanceId\"i\n" +
 	"\x0eBuffSyncNotify\x12-\n" +
 	"\x05buffs\x18\x01 \x03(\v2\x17.towerdefense.BuffStateR\x05buffs\x12(\n" +
 	"\x10removed_buff_ids\x18\x02 \x03(\tR\x0eremovedBuffIdsB)Z\x12towerdefense/proto\xaa\x02\x12TowerDefense.Protob\x06proto3"
@@ -1307,29 +1355,31 @@ var file_sync_proto_goTypes = []any{
 	(*BuffState)(nil),                // 13: towerdefense.BuffState
 	(*BuffSyncNotify)(nil),           // 14: towerdefense.BuffSyncNotify
 	(*Vector3)(nil),                  // 15: Vector3
+	(TargetMode)(0),                  // 16: TargetMode
 }
 var file_sync_proto_depIdxs = []int32{
 	15, // 0: towerdefense.EnemyState.position:type_name -> Vector3
 	15, // 1: towerdefense.TowerState.position:type_name -> Vector3
-	0,  // 2: towerdefense.SyncStateBroadcast.enemies:type_name -> towerdefense.EnemyState
-	1,  // 3: towerdefense.SyncStateBroadcast.towers:type_name -> towerdefense.TowerState
-	0,  // 4: towerdefense.SyncEnemyBroadcast.enemies:type_name -> towerdefense.EnemyState
-	1,  // 5: towerdefense.SyncTowerBroadcast.towers:type_name -> towerdefense.TowerState
-	15, // 6: towerdefense.SyncDamageBroadcast.position:type_name -> Vector3
-	0,  // 7: towerdefense.EnemySpawnNotify.enemy:type_name -> towerdefense.EnemyState
-	15, // 8: towerdefense.EnemyDeathNotify.death_position:type_name -> Vector3
-	15, // 9: towerdefense.TowerAttackNotify.from_position:type_name -> Vector3
-	15, // 10: towerdefense.TowerAttackNotify.to_position:type_name -> Vector3
-	0,  // 11: towerdefense.GameStateSnapshot.enemies:type_name -> towerdefense.EnemyState
-	1,  // 12: towerdefense.GameStateSnapshot.towers:type_name -> towerdefense.TowerState
-	9,  // 13: towerdefense.GameStateSnapshot.players:type_name -> towerdefense.PlayerStateSync
-	10, // 14: towerdefense.RequestGameStateResponse.snapshot:type_name -> towerdefense.GameStateSnapshot
-	13, // 15: towerdefense.BuffSyncNotify.buffs:type_name -> towerdefense.BuffState
-	16, // [16:16] is the sub-list for method output_type
-	16, // [16:16] is the sub-list for method input_type
-	16, // [16:16] is the sub-list for extension type_name
-	16, // [16:16] is the sub-list for extension extendee
-	0,  // [0:16] is the sub-list for field type_name
+	16, // 2: towerdefense.TowerState.target_mode:type_name -> TargetMode
+	0,  // 3: towerdefense.SyncStateBroadcast.enemies:type_name -> towerdefense.EnemyState
+	1,  // 4: towerdefense.SyncStateBroadcast.towers:type_name -> towerdefense.TowerState
+	0,  // 5: towerdefense.SyncEnemyBroadcast.enemies:type_name -> towerdefense.EnemyState
+	1,  // 6: towerdefense.SyncTowerBroadcast.towers:type_name -> towerdefense.TowerState
+	15, // 7: towerdefense.SyncDamageBroadcast.position:type_name -> Vector3
+	0,  // 8: towerdefense.EnemySpawnNotify.enemy:type_name -> towerdefense.EnemyState
+	15, // 9: towerdefense.EnemyDeathNotify.death_position:type_name -> Vector3
+	15, // 10: towerdefense.TowerAttackNotify.from_position:type_name -> Vector3
+	15, // 11: towerdefense.TowerAttackNotify.to_position:type_name -> Vector3
+	0,  // 12: towerdefense.GameStateSnapshot.enemies:type_name -> towerdefense.EnemyState
+	1,  // 13: towerdefense.GameStateSnapshot.towers:type_name -> towerdefense.TowerState
+	9,  // 14: towerdefense.GameStateSnapshot.players:type_name -> towerdefense.PlayerStateSync
+	10, // 15: towerdefense.RequestGameStateResponse.snapshot:type_name -> towerdefense.GameStateSnapshot
+	13, // 16: towerdefense.BuffSyncNotify.buffs:type_name -> towerdefense.BuffState
+	17, // [17:17] is the sub-list for method output_type
+	17, // [17:17] is the sub-list for method input_type
+	17, // [17:17] is the sub-list for extension type_name
+	17, // [17:17] is the sub-list for extension extendee
+	0,  // [0:17] is the sub-list for field type_name
 }
 
 func init() { file_sync_proto_init() }
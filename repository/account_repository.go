@@ -8,6 +8,8 @@ import (
 
 // AccountData 账号数据模型（对应数据库表结构）
 type AccountData struct {
+	storage.VersionedRecord // 乐观锁版本号
+	storage.SchemaRecord    // 结构版本号
 	Username      string    `json:"username"`
 	Password      string    `json:"password"`       // MD5加密
 	PlayerID      string    `json:"player_id"`
@@ -33,9 +35,15 @@ func NewAccountRepository() *AccountRepository {
 	}
 }
 
-// Save 保存账号
+// Save 保存账号（比较并保存）
+// 新账号版本号为 0，仅在用户名未被占用时写入
 func (ar *AccountRepository) Save(account *AccountData) error {
-	return ar.storage.Save(TableAccount, account.Username, account)
+	return ar.storage.CompareAndSave(TableAccount, account.Username, account.Version, account)
+}
+
+// Update 读取-修改-写回账号数据，版本冲突时自动重试
+func (ar *AccountRepository) Update(username string, fn func(account *AccountData) error) error {
+	return storage.Update(ar.storage, TableAccount, username, fn)
 }
 
 // GetByUsername 根据用户名获取账号
@@ -98,13 +106,9 @@ func (ar *AccountRepository) GetAll() ([]*AccountData, error) {
 
 // UpdateLastLogin 更新最后登录时间
 func (ar *AccountRepository) UpdateLastLogin(username string) error {
-	account, err := ar.GetByUsername(username)
-	if err != nil {
-		return err
-	}
-	
-	account.LastLoginTime = time.Now()
-	account.LoginCount++
-	
-	return ar.Save(account)
+	return ar.Update(username, func(account *AccountData) error {
+		account.LastLoginTime = time.Now()
+		account.LoginCount++
+		return nil
+	})
 }
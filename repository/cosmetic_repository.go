@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"towerdefense/storage"
+)
+
+// 外观类型
+const (
+	CosmeticTypeIcon  = "icon"  // 头像
+	CosmeticTypeFrame = "frame" // 头像框
+)
+
+// 外观解锁来源
+const (
+	CosmeticSourceDefault     = "default"     // 默认拥有
+	CosmeticSourceLevel       = "level"       // 等级解锁
+	CosmeticSourceAchievement = "achievement" // 成就奖励
+	CosmeticSourceShop        = "shop"        // 商店购买
+)
+
+// 默认外观（新角色使用，所有玩家都拥有）
+const (
+	DefaultIconID  = 1
+	DefaultFrameID = 1
+)
+
+// CosmeticUnlock 外观解锁记录
+type CosmeticUnlock struct {
+	Type       string    `json:"type"` // icon, frame
+	ID         int       `json:"id"`
+	Source     string    `json:"source"`
+	SourceRef  string    `json:"source_ref"` // 来源详情：等级、成就ID、订单号等
+	UnlockTime time.Time `json:"unlock_time"`
+}
+
+// CosmeticData 玩家已解锁的外观（每个玩家一条）
+type CosmeticData struct {
+	storage.VersionedRecord // 乐观锁版本号
+	storage.SchemaRecord    // 结构版本号
+	PlayerID string           `json:"player_id"`
+	Unlocks  []CosmeticUnlock `json:"unlocks"`
+}
+
+const TableCosmetic = "cosmetics"
+
+// Has 是否已解锁（默认外观视为已解锁）
+func (cd *CosmeticData) Has(cosmeticType string, id int) bool {
+	if IsDefaultCosmetic(cosmeticType, id) {
+		return true
+	}
+	for _, unlock := range cd.Unlocks {
+		if unlock.Type == cosmeticType && unlock.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Add 添加解锁记录，已解锁时返回 false
+func (cd *CosmeticData) Add(unlock CosmeticUnlock) bool {
+	if cd.Has(unlock.Type, unlock.ID) {
+		return false
+	}
+	cd.Unlocks = append(cd.Unlocks, unlock)
+	return true
+}
+
+// IsDefaultCosmetic 是否为默认外观
+func IsDefaultCosmetic(cosmeticType string, id int) bool {
+	switch cosmeticType {
+	case CosmeticTypeIcon:
+		return id == DefaultIconID
+	case CosmeticTypeFrame:
+		return id == DefaultFrameID
+	}
+	return false
+}
+
+// CosmeticRepository 外观仓储
+type CosmeticRepository struct {
+	storage storage.IStorage
+}
+
+// NewCosmeticRepository 创建外观仓储
+func NewCosmeticRepository() *CosmeticRepository {
+	return &CosmeticRepository{
+		storage: storage.GetStorage(),
+	}
+}
+
+// Get 获取玩家已解锁的外观，没有记录时返回空记录
+func (cr *CosmeticRepository) Get(playerID string) (*CosmeticData, error) {
+	exists, err := cr.storage.Exists(TableCosmetic, playerID)
+	if err != nil {
+		return nil, err
+	}
+	cosmetics := &CosmeticData{PlayerID: playerID}
+	if !exists {
+		return cosmetics, nil
+	}
+	if err := cr.storage.Get(TableCosmetic, playerID, cosmetics); err != nil {
+		return nil, err
+	}
+	return cosmetics, nil
+}
+
+// Unlock 添加解锁记录（已解锁时不做修改），返回是否新解锁
+func (cr *CosmeticRepository) Unlock(playerID string, unlock CosmeticUnlock) (bool, error) {
+	for i := 0; i < storage.MaxUpdateRetries; i++ {
+		cosmetics, err := cr.Get(playerID)
+		if err != nil {
+			return false, err
+		}
+
+		expected := cosmetics.Version
+		if !cosmetics.Add(unlock) {
+			return false, nil
+		}
+
+		err = cr.storage.CompareAndSave(TableCosmetic, playerID, expected, cosmetics)
+		if err == nil {
+			return true, nil
+		}
+		if !errors.Is(err, storage.ErrVersionConflict) {
+			return false, err
+		}
+	}
+
+	return false, fmt.Errorf("%w: 解锁玩家 %s 外观重试 %d 次仍失败", storage.ErrVersionConflict, playerID, storage.MaxUpdateRetries)
+}
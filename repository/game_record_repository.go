@@ -7,6 +7,7 @@ import (
 
 // GameRecordData 游戏记录数据模型
 type GameRecordData struct {
+	storage.SchemaRecord // 结构版本号
 	RecordID   string    `json:"record_id"`
 	RoomID     string    `json:"room_id"`
 	RoomName   string    `json:"room_name"`
@@ -18,6 +19,22 @@ type GameRecordData struct {
 	TotalKills int       `json:"total_kills"`
 	StartTime  time.Time `json:"start_time"`
 	EndTime    time.Time `json:"end_time"`
+	Seed       int64     `json:"seed"`          // 随机种子
+	Commands   []BattleCommandRecord `json:"commands"` // 玩家操作日志，与随机种子一起用于回放
+}
+
+// BattleCommandRecord 战斗中执行的一次玩家操作
+type BattleCommandRecord struct {
+	Frame      int64   `json:"frame"` // 执行时已完成的逻辑帧数
+	PlayerID   string  `json:"player_id"`
+	Op         string  `json:"op"`
+	TowerID    string  `json:"tower_id,omitempty"`
+	TowerType  int     `json:"tower_type,omitempty"`
+	PosX       float32 `json:"pos_x,omitempty"`
+	PosY       float32 `json:"pos_y,omitempty"`
+	PosZ       float32 `json:"pos_z,omitempty"`
+	TargetMode int     `json:"target_mode,omitempty"`
+	SkillID    int     `json:"skill_id,omitempty"`
 }
 
 const TableGameRecord = "game_records"
@@ -39,6 +56,11 @@ func (gr *GameRecordRepository) Save(record *GameRecordData) error {
 	return gr.storage.Save(TableGameRecord, record.RecordID, record)
 }
 
+// Exists 检查游戏记录是否存在
+func (gr *GameRecordRepository) Exists(recordID string) (bool, error) {
+	return gr.storage.Exists(TableGameRecord, recordID)
+}
+
 // Get 获取游戏记录
 func (gr *GameRecordRepository) Get(recordID string) (*GameRecordData, error) {
 	var record GameRecordData
@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+	"towerdefense/storage"
+)
+
+// InventoryItem 背包中的一格道具
+type InventoryItem struct {
+	ItemID     int       `json:"item_id"`
+	Count      int       `json:"count"`
+	ExpireTime time.Time `json:"expire_time"` // 零值表示永久
+	ObtainTime time.Time `json:"obtain_time"`
+}
+
+// IsExpired 是否已过期
+func (item *InventoryItem) IsExpired(now time.Time) bool {
+	return !item.ExpireTime.IsZero() && !now.Before(item.ExpireTime)
+}
+
+// ActiveEffect 已使用、等待生效的道具效果（如下一场战斗的额外初始金币）
+type ActiveEffect struct {
+	Type         string    `json:"type"`
+	Value        int       `json:"value"`
+	ItemID       int       `json:"item_id"` // 来源道具
+	ActivateTime time.Time `json:"activate_time"`
+}
+
+// InventoryData 玩家背包（每个玩家一条）
+type InventoryData struct {
+	storage.VersionedRecord // 乐观锁版本号
+	storage.SchemaRecord    // 结构版本号
+	PlayerID   string          `json:"player_id"`
+	Items      []InventoryItem `json:"items"`
+	Effects    []ActiveEffect  `json:"effects"`
+	UpdateTime time.Time       `json:"update_time"`
+}
+
+const TableInventory = "inventories"
+
+// CountItem 统计未过期的道具数量
+func (inv *InventoryData) CountItem(itemID int, now time.Time) int {
+	total := 0
+	for i := range inv.Items {
+		if inv.Items[i].ItemID == itemID && !inv.Items[i].IsExpired(now) {
+			total += inv.Items[i].Count
+		}
+	}
+	return total
+}
+
+// AddItem 放入道具：优先叠加到同一过期时间且未满的格子，放不下时新开格子
+func (inv *InventoryData) AddItem(itemID, count, maxStack int, expireTime, now time.Time) {
+	if maxStack <= 0 {
+		maxStack = 1
+	}
+	for i := range inv.Items {
+		if count <= 0 {
+			return
+		}
+		item := &inv.Items[i]
+		if item.ItemID != itemID || !item.ExpireTime.Equal(expireTime) || item.IsExpired(now) || item.Count >= maxStack {
+			continue
+		}
+		added := min(count, maxStack-item.Count)
+		item.Count += added
+		count -= added
+	}
+	for count > 0 {
+		added := min(count, maxStack)
+		inv.Items = append(inv.Items, InventoryItem{
+			ItemID:     itemID,
+			Count:      added,
+			ExpireTime: expireTime,
+			ObtainTime: now,
+		})
+		count -= added
+	}
+}
+
+// RemoveItem 扣除道具（优先扣除最早过期的），数量不足时不做修改并返回 false
+func (inv *InventoryData) RemoveItem(itemID, count int, now time.Time) bool {
+	if count <= 0 || inv.CountItem(itemID, now) < count {
+		return false
+	}
+
+	indexes := make([]int, 0)
+	for i := range inv.Items {
+		if inv.Items[i].ItemID == itemID && !inv.Items[i].IsExpired(now) {
+			indexes = append(indexes, i)
+		}
+	}
+	sort.SliceStable(indexes, func(a, b int) bool {
+		ea, eb := inv.Items[indexes[a]].ExpireTime, inv.Items[indexes[b]].ExpireTime
+		if ea.IsZero() || eb.IsZero() {
+			return !ea.IsZero() && eb.IsZero()
+		}
+		return ea.Before(eb)
+	})
+	for _, i := range indexes {
+		removed := min(count, inv.Items[i].Count)
+		inv.Items[i].Count -= removed
+		count -= removed
+		if count == 0 {
+			break
+		}
+	}
+	inv.compact(now)
+	return true
+}
+
+// RemoveExpired 清理过期和数量为 0 的格子，返回被清理的过期道具
+func (inv *InventoryData) RemoveExpired(now time.Time) []InventoryItem {
+	expired := make([]InventoryItem, 0)
+	for _, item := range inv.Items {
+		if item.Count > 0 && item.IsExpired(now) {
+			expired = append(expired, item)
+		}
+	}
+	inv.compact(now)
+	return expired
+}
+
+// compact 移除过期和数量为 0 的格子
+func (inv *InventoryData) compact(now time.Time) {
+	items := inv.Items[:0]
+	for _, item := range inv.Items {
+		if item.Count > 0 && !item.IsExpired(now) {
+			items = append(items, item)
+		}
+	}
+	inv.Items = items
+}
+
+// AddEffect 添加等待生效的道具效果
+func (inv *InventoryData) AddEffect(effect ActiveEffect) {
+	inv.Effects = append(inv.Effects, effect)
+}
+
+// EffectValue 某类待生效效果的数值合计
+func (inv *InventoryData) EffectValue(effectType string) int {
+	total := 0
+	for _, effect := range inv.Effects {
+		if effect.Type == effectType {
+			total += effect.Value
+		}
+	}
+	return total
+}
+
+// TakeEffects 取出某类待生效效果（取出后移除），返回数值合计
+func (inv *InventoryData) TakeEffects(effectType string) int {
+	total := 0
+	effects := inv.Effects[:0]
+	for _, effect := range inv.Effects {
+		if effect.Type == effectType {
+			total += effect.Value
+			continue
+		}
+		effects = append(effects, effect)
+	}
+	inv.Effects = effects
+	return total
+}
+
+// InventoryRepository 背包仓储
+type InventoryRepository struct {
+	storage storage.IStorage
+}
+
+// NewInventoryRepository 创建背包仓储
+func NewInventoryRepository() *InventoryRepository {
+	return &InventoryRepository{
+		storage: storage.GetStorage(),
+	}
+}
+
+// Get 获取玩家背包，没有背包时返回空背包
+func (ir *InventoryRepository) Get(playerID string) (*InventoryData, error) {
+	return loadInventory(ir.storage, playerID)
+}
+
+// Update 读取-修改-写回玩家背包（背包不存在时创建），版本冲突时自动重试
+func (ir *InventoryRepository) Update(playerID string, fn func(inventory *InventoryData) error) error {
+	for i := 0; i < storage.MaxUpdateRetries; i++ {
+		inventory, err := loadInventory(ir.storage, playerID)
+		if err != nil {
+			return err
+		}
+
+		expected := inventory.Version
+		if err := fn(inventory); err != nil {
+			return err
+		}
+		inventory.UpdateTime = time.Now()
+
+		err = ir.storage.CompareAndSave(TableInventory, playerID, expected, inventory)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, storage.ErrVersionConflict) {
+			return err
+		}
+	}
+
+	return fmt.Errorf("%w: 更新背包 %s 重试 %d 次仍失败", storage.ErrVersionConflict, playerID, storage.MaxUpdateRetries)
+}
+
+// loadInventory 读取玩家背包，不存在时返回版本号为 0 的空背包
+func loadInventory(s storage.IStorage, playerID string) (*InventoryData, error) {
+	exists, err := s.Exists(TableInventory, playerID)
+	if err != nil {
+		return nil, err
+	}
+	inventory := &InventoryData{PlayerID: playerID}
+	if !exists {
+		return inventory, nil
+	}
+	if err := s.Get(TableInventory, playerID, inventory); err != nil {
+		return nil, err
+	}
+	return inventory, nil
+}
@@ -0,0 +1,281 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"towerdefense/storage"
+
+	"github.com/google/uuid"
+)
+
+// 货币类型
+const (
+	CurrencyGold    = "gold"
+	CurrencyDiamond = "diamond"
+)
+
+// 货币变更原因
+const (
+	LedgerReasonInitial      = "initial"       // 创建角色时的初始货币
+	LedgerReasonBattleReward = "battle_reward" // 战斗奖励（来源：战斗ID）
+	LedgerReasonPurchase     = "purchase"      // 充值/购买（来源：订单号）
+	LedgerReasonConsume      = "consume"       // 游戏内消费
+	LedgerReasonRename       = "rename"        // 改名（来源：新名称）
+	LedgerReasonGM           = "gm"            // GM 操作（来源：操作人）
+)
+
+var (
+	ErrInsufficientBalance = errors.New("余额不足")
+	ErrUnknownCurrency     = errors.New("未知的货币类型")
+)
+
+// LedgerEntry 货币流水（每次货币变更一条，只写入不修改）
+type LedgerEntry struct {
+	storage.VersionedRecord // 乐观锁版本号（只在不存在时写入）
+	storage.SchemaRecord    // 结构版本号
+	EntryID      string    `json:"entry_id"`
+	PlayerID     string    `json:"player_id"`
+	Currency     string    `json:"currency"`
+	Delta        int       `json:"delta"`
+	BalanceAfter int       `json:"balance_after"`
+	Reason       string    `json:"reason"`
+	SourceRef    string    `json:"source_ref"` // 来源：战斗ID、订单号、GM 操作人等
+	CreateTime   time.Time `json:"create_time"`
+}
+
+// LedgerIndexData 玩家流水索引：记录玩家最新一页流水的ID，写满后整页归档，查询时只读取该玩家的流水
+type LedgerIndexData struct {
+	storage.VersionedRecord // 乐观锁版本号
+	storage.SchemaRecord    // 结构版本号
+	PlayerID string   `json:"player_id"`
+	Pages    int      `json:"pages"`     // 已归档的页数
+	EntryIDs []string `json:"entry_ids"` // 最新一页的流水ID（按时间正序）
+}
+
+// LedgerPageData 归档的一页流水ID（只写入不修改）
+type LedgerPageData struct {
+	storage.VersionedRecord // 乐观锁版本号（只在不存在时写入）
+	storage.SchemaRecord    // 结构版本号
+	PlayerID string   `json:"player_id"`
+	Page     int      `json:"page"`
+	EntryIDs []string `json:"entry_ids"` // 按时间正序
+}
+
+const (
+	TableLedger      = "currency_ledger"
+	TableLedgerIndex = "currency_ledger_index" // 键：玩家ID
+	TableLedgerPage  = "currency_ledger_page"  // 键：玩家ID_页号
+)
+
+// ledgerPageSize 索引每页的流水条数
+const ledgerPageSize = 100
+
+// ChangeCurrency 修改玩家货币余额并生成对应的流水（不保存）
+// 余额不足时不做修改并返回 ErrInsufficientBalance
+func (player *PlayerData) ChangeCurrency(currency string, delta int, reason, sourceRef string) (*LedgerEntry, error) {
+	var balance *int
+	switch currency {
+	case CurrencyGold:
+		balance = &player.Gold
+	case CurrencyDiamond:
+		balance = &player.Diamond
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCurrency, currency)
+	}
+	if *balance+delta < 0 {
+		return nil, fmt.Errorf("%w: %s 余额 %d, 变更 %d", ErrInsufficientBalance, currency, *balance, delta)
+	}
+	*balance += delta
+
+	now := time.Now()
+	return &LedgerEntry{
+		// 键以玩家ID和时间开头，便于按玩家查询并按时间排序
+		EntryID:      fmt.Sprintf("%s_%019d_%s", player.PlayerID, now.UnixNano(), uuid.New().String()[:8]),
+		PlayerID:     player.PlayerID,
+		Currency:     currency,
+		Delta:        delta,
+		BalanceAfter: *balance,
+		Reason:       reason,
+		SourceRef:    sourceRef,
+		CreateTime:   now,
+	}, nil
+}
+
+// ledgerPageKey 归档页的键（页号定长，玩家ID含下划线时也不会与其他玩家的键重复）
+func ledgerPageKey(playerID string, page int) string {
+	return fmt.Sprintf("%s_%06d", playerID, page)
+}
+
+// loadLedgerIndex 读取玩家流水索引，不存在时返回 nil
+func loadLedgerIndex(s storage.IStorage, playerID string) (*LedgerIndexData, error) {
+	exists, err := s.Exists(TableLedgerIndex, playerID)
+	if err != nil || !exists {
+		return nil, err
+	}
+	var index LedgerIndexData
+	if err := s.Get(TableLedgerIndex, playerID, &index); err != nil {
+		return nil, err
+	}
+	return &index, nil
+}
+
+// ledgerOps 写入流水并更新玩家流水索引的事务操作（entries 属于同一个玩家，按时间正序）
+func ledgerOps(s storage.IStorage, entries ...*LedgerEntry) ([]storage.TxOp, error) {
+	playerID := entries[0].PlayerID
+	index, err := loadLedgerIndex(s, playerID)
+	if err != nil {
+		return nil, err
+	}
+	if index == nil {
+		index = &LedgerIndexData{PlayerID: playerID}
+	}
+	expected := index.Version
+
+	ops := make([]storage.TxOp, 0, len(entries)+2)
+	for _, entry := range entries {
+		ops = append(ops, storage.TxOp{Table: TableLedger, Key: entry.EntryID, ExpectedVersion: 0, Data: entry})
+		if len(index.EntryIDs) >= ledgerPageSize {
+			index.Pages++
+			page := &LedgerPageData{PlayerID: playerID, Page: index.Pages, EntryIDs: index.EntryIDs}
+			ops = append(ops, storage.TxOp{Table: TableLedgerPage, Key: ledgerPageKey(playerID, page.Page), ExpectedVersion: 0, Data: page})
+			index.EntryIDs = nil
+		}
+		index.EntryIDs = append(index.EntryIDs, entry.EntryID)
+	}
+	return append(ops, storage.TxOp{Table: TableLedgerIndex, Key: playerID, ExpectedVersion: expected, Data: index}), nil
+}
+
+// LedgerRepository 货币流水仓储
+type LedgerRepository struct {
+	storage storage.IStorage
+}
+
+// NewLedgerRepository 创建货币流水仓储
+func NewLedgerRepository() *LedgerRepository {
+	return &LedgerRepository{
+		storage: storage.GetStorage(),
+	}
+}
+
+// ChangeCurrency 修改玩家货币，余额与流水在同一个事务中写入
+func (lr *LedgerRepository) ChangeCurrency(playerID, currency string, delta int, reason, sourceRef string) (*LedgerEntry, error) {
+	for i := 0; i < storage.MaxUpdateRetries; i++ {
+		var player PlayerData
+		if err := lr.storage.Get(TablePlayer, playerID, &player); err != nil {
+			return nil, err
+		}
+
+		expected := player.Version
+		entry, err := player.ChangeCurrency(currency, delta, reason, sourceRef)
+		if err != nil {
+			return nil, err
+		}
+
+		ops, err := ledgerOps(lr.storage, entry)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, storage.TxOp{Table: TablePlayer, Key: playerID, ExpectedVersion: expected, Data: &player})
+
+		err = storage.CompareAndSaveMulti(lr.storage, ops)
+		if err == nil {
+			return entry, nil
+		}
+		if !errors.Is(err, storage.ErrVersionConflict) {
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf("%w: 修改玩家 %s 货币重试 %d 次仍失败", storage.ErrVersionConflict, playerID, storage.MaxUpdateRetries)
+}
+
+// GetHistory 查询玩家的货币流水（按时间倒序），currency 为空时查询全部货币，limit <= 0 时不限制条数
+// 通过玩家流水索引从最新一页向前读取，不扫描整张流水表
+func (lr *LedgerRepository) GetHistory(playerID, currency string, limit int) ([]*LedgerEntry, error) {
+	entries := make([]*LedgerEntry, 0)
+	index, err := loadLedgerIndex(lr.storage, playerID)
+	if err != nil || index == nil {
+		return entries, err
+	}
+
+	ids := index.EntryIDs
+	for page := index.Pages; ; page-- {
+		for i := len(ids) - 1; i >= 0; i-- {
+			var entry LedgerEntry
+			if err := lr.storage.Get(TableLedger, ids[i], &entry); err != nil {
+				return nil, err
+			}
+			if currency != "" && entry.Currency != currency {
+				continue
+			}
+			entries = append(entries, &entry)
+			if limit > 0 && len(entries) >= limit {
+				return entries, nil
+			}
+		}
+		if page == 0 {
+			return entries, nil
+		}
+
+		var archived LedgerPageData
+		if err := lr.storage.Get(TableLedgerPage, ledgerPageKey(playerID, page), &archived); err != nil {
+			return nil, err
+		}
+		ids = archived.EntryIDs
+	}
+}
+
+// IndexExisting 为流水索引上线前写入的流水补建索引，已有索引的玩家跳过，返回补建的玩家数
+// 流水ID为 玩家ID_时间_随机串，按最后两段之前的部分归属玩家
+func (lr *LedgerRepository) IndexExisting() (int, error) {
+	keys, err := lr.storage.Keys(TableLedger)
+	if err != nil {
+		return 0, err
+	}
+
+	byPlayer := make(map[string][]string)
+	for _, key := range keys {
+		parts := strings.Split(key, "_")
+		if len(parts) < 3 {
+			continue
+		}
+		playerID := strings.Join(parts[:len(parts)-2], "_")
+		byPlayer[playerID] = append(byPlayer[playerID], key)
+	}
+
+	indexed := 0
+	for playerID, ids := range byPlayer {
+		exists, err := lr.storage.Exists(TableLedgerIndex, playerID)
+		if err != nil {
+			return indexed, err
+		}
+		if exists {
+			continue
+		}
+
+		sort.Strings(ids)
+		index := &LedgerIndexData{PlayerID: playerID}
+		ops := make([]storage.TxOp, 0, len(ids)/ledgerPageSize+1)
+		for len(ids) > ledgerPageSize {
+			index.Pages++
+			page := &LedgerPageData{PlayerID: playerID, Page: index.Pages, EntryIDs: ids[:ledgerPageSize]}
+			ops = append(ops, storage.TxOp{Table: TableLedgerPage, Key: ledgerPageKey(playerID, page.Page), ExpectedVersion: 0, Data: page})
+			ids = ids[ledgerPageSize:]
+		}
+		index.EntryIDs = ids
+		ops = append(ops, storage.TxOp{Table: TableLedgerIndex, Key: playerID, ExpectedVersion: 0, Data: index})
+
+		err = storage.CompareAndSaveMulti(lr.storage, ops)
+		if errors.Is(err, storage.ErrVersionConflict) {
+			continue // 其他进程已建立索引
+		}
+		if err != nil {
+			return indexed, err
+		}
+		indexed++
+	}
+	return indexed, nil
+}
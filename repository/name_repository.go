@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"towerdefense/storage"
+)
+
+// ErrNameTaken 名称已被其他玩家使用
+var ErrNameTaken = errors.New("名称已被使用")
+
+// NameIndexData 玩家名称唯一索引（每个名称一条，改名后旧名称标记为释放，可被他人使用）
+type NameIndexData struct {
+	storage.VersionedRecord // 乐观锁版本号
+	storage.SchemaRecord    // 结构版本号
+	Name       string    `json:"name"`
+	PlayerID   string    `json:"player_id"`
+	Released   bool      `json:"released"`
+	UpdateTime time.Time `json:"update_time"`
+}
+
+const TableNameIndex = "player_names"
+
+// NormalizeName 名称查重时使用的规范形式（去除首尾空白，忽略大小写）
+func NormalizeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// nameKey 名称索引的键（十六进制编码，避免名称中的特殊字符影响存储）
+func nameKey(name string) string {
+	return hex.EncodeToString([]byte(NormalizeName(name)))
+}
+
+// loadNameIndex 读取名称索引，不存在时返回 nil
+func loadNameIndex(s storage.IStorage, name string) (*NameIndexData, error) {
+	key := nameKey(name)
+	exists, err := s.Exists(TableNameIndex, key)
+	if err != nil || !exists {
+		return nil, err
+	}
+	var index NameIndexData
+	if err := s.Get(TableNameIndex, key, &index); err != nil {
+		return nil, err
+	}
+	return &index, nil
+}
+
+// claimNameOp 占用名称的事务操作，名称被其他玩家占用时返回 ErrNameTaken
+func claimNameOp(s storage.IStorage, name, playerID string) (storage.TxOp, error) {
+	index, err := loadNameIndex(s, name)
+	if err != nil {
+		return storage.TxOp{}, err
+	}
+	var expected int64
+	if index != nil {
+		if !index.Released && index.PlayerID != playerID {
+			return storage.TxOp{}, ErrNameTaken
+		}
+		expected = index.Version
+	}
+	return storage.TxOp{
+		Table:           TableNameIndex,
+		Key:             nameKey(name),
+		ExpectedVersion: expected,
+		Data:            &NameIndexData{Name: name, PlayerID: playerID, UpdateTime: time.Now()},
+	}, nil
+}
+
+// releaseNameOp 释放名称的事务操作，名称不属于该玩家（如未建立索引的旧数据）时返回 nil
+func releaseNameOp(s storage.IStorage, name, playerID string) (*storage.TxOp, error) {
+	index, err := loadNameIndex(s, name)
+	if err != nil || index == nil || index.PlayerID != playerID || index.Released {
+		return nil, err
+	}
+	expected := index.Version
+	index.Released = true
+	index.UpdateTime = time.Now()
+	return &storage.TxOp{Table: TableNameIndex, Key: nameKey(name), ExpectedVersion: expected, Data: index}, nil
+}
+
+// NameRepository 玩家名称仓储
+type NameRepository struct {
+	storage storage.IStorage
+}
+
+// NewNameRepository 创建玩家名称仓储
+func NewNameRepository() *NameRepository {
+	return &NameRepository{
+		storage: storage.GetStorage(),
+	}
+}
+
+// IsAvailable 名称是否可被该玩家使用
+func (nr *NameRepository) IsAvailable(name, playerID string) (bool, error) {
+	index, err := loadNameIndex(nr.storage, name)
+	if err != nil {
+		return false, err
+	}
+	return index == nil || index.Released || index.PlayerID == playerID, nil
+}
+
+// Rename 修改玩家名称：占用新名称、释放旧名称、修改玩家数据（及扣费流水）在同一个事务中写入
+// apply 负责冷却、费用等检查并修改玩家数据，需要扣费时返回对应的流水
+func (nr *NameRepository) Rename(playerID, newName string, apply func(player *PlayerData) (*LedgerEntry, error)) error {
+	for i := 0; i < storage.MaxUpdateRetries; i++ {
+		var player PlayerData
+		if err := nr.storage.Get(TablePlayer, playerID, &player); err != nil {
+			return err
+		}
+
+		claim, err := claimNameOp(nr.storage, newName, playerID)
+		if err != nil {
+			return err
+		}
+		ops := []storage.TxOp{claim}
+		// 仅大小写不同时新旧名称是同一条索引，不能释放
+		if NormalizeName(player.PlayerName) != NormalizeName(newName) {
+			release, err := releaseNameOp(nr.storage, player.PlayerName, playerID)
+			if err != nil {
+				return err
+			}
+			if release != nil {
+				ops = append(ops, *release)
+			}
+		}
+
+		expected := player.Version
+		entry, err := apply(&player)
+		if err != nil {
+			return err
+		}
+		player.PlayerName = newName
+		ops = append(ops, storage.TxOp{Table: TablePlayer, Key: playerID, ExpectedVersion: expected, Data: &player})
+		if entry != nil {
+			entryOps, err := ledgerOps(nr.storage, entry)
+			if err != nil {
+				return err
+			}
+			ops = append(ops, entryOps...)
+		}
+
+		err = storage.CompareAndSaveMulti(nr.storage, ops)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, storage.ErrVersionConflict) {
+			return err
+		}
+	}
+
+	return fmt.Errorf("%w: 玩家 %s 改名重试 %d 次仍失败", storage.ErrVersionConflict, playerID, storage.MaxUpdateRetries)
+}
+
+// IndexExisting 为已有玩家补建名称索引（名称已被占用时返回 ErrNameTaken）
+func (nr *NameRepository) IndexExisting(player *PlayerData) error {
+	index, err := loadNameIndex(nr.storage, player.PlayerName)
+	if err != nil {
+		return err
+	}
+	if index != nil && !index.Released && index.PlayerID == player.PlayerID {
+		return nil // 已建立索引
+	}
+	claim, err := claimNameOp(nr.storage, player.PlayerName, player.PlayerID)
+	if err != nil {
+		return err
+	}
+	return nr.storage.CompareAndSave(claim.Table, claim.Key, claim.ExpectedVersion, claim.Data)
+}
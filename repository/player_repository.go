@@ -1,12 +1,18 @@
 package repository
 
 import (
+	"errors"
+	"fmt"
+	"towerdefense/config"
 	"towerdefense/storage"
 	"time"
+	"unicode/utf8"
 )
 
 // PlayerData 玩家数据模型（对应数据库表结构）
 type PlayerData struct {
+	storage.VersionedRecord // 乐观锁版本号
+	storage.SchemaRecord    // 结构版本号
 	PlayerID      string    `json:"player_id"`
 	PlayerName    string    `json:"player_name"`
 	IconID        int       `json:"icon_id"`         // 头像ID
@@ -24,10 +30,25 @@ type PlayerData struct {
 	CreateTime    time.Time `json:"create_time"`
 	LastLoginTime time.Time `json:"last_login_time"`
 	IsNewPlayer   bool      `json:"is_new_player"`   // 是否新玩家
+	RenameCount   int       `json:"rename_count"`    // 已改名次数
+	RenameTime    time.Time `json:"rename_time"`     // 最近一次改名时间
+	RecentBattles []string  `json:"recent_battles,omitempty"` // 最近已结算的战斗ID（防止重复结算）
 }
 
+// maxRecentBattles 保留的已结算战斗ID数量
+const maxRecentBattles = 20
+
+// 新角色初始货币
+const (
+	initialGold    = 1000
+	initialDiamond = 100
+)
+
 const TablePlayer = "players"
 
+// maxFallbackNames 初始名称被占用时最多尝试的备用名称个数
+const maxFallbackNames = 10
+
 // PlayerRepository 玩家仓储
 type PlayerRepository struct {
 	storage storage.IStorage
@@ -40,9 +61,16 @@ func NewPlayerRepository() *PlayerRepository {
 	}
 }
 
-// Save 保存玩家数据
+// Save 保存玩家数据（比较并保存）
+// 以 player 读取时的版本号为准，期间若被其他写入者修改则返回 storage.ErrVersionConflict
+// 需要读-改-写的场景请使用 Update
 func (pr *PlayerRepository) Save(player *PlayerData) error {
-	return pr.storage.Save(TablePlayer, player.PlayerID, player)
+	return pr.storage.CompareAndSave(TablePlayer, player.PlayerID, player.Version, player)
+}
+
+// Update 读取-修改-写回玩家数据，版本冲突时自动重试
+func (pr *PlayerRepository) Update(playerID string, fn func(player *PlayerData) error) error {
+	return storage.Update(pr.storage, TablePlayer, playerID, fn)
 }
 
 // Get 获取玩家数据
@@ -55,6 +83,11 @@ func (pr *PlayerRepository) Get(playerID string) (*PlayerData, error) {
 	return &player, nil
 }
 
+// Flush 将缓存中该玩家的数据立即写回存储（玩家登出时调用）
+func (pr *PlayerRepository) Flush(playerID string) error {
+	return storage.FlushKey(TablePlayer, playerID)
+}
+
 // Delete 删除玩家数据
 func (pr *PlayerRepository) Delete(playerID string) error {
 	return pr.storage.Delete(TablePlayer, playerID)
@@ -65,36 +98,43 @@ func (pr *PlayerRepository) Exists(playerID string) (bool, error) {
 	return pr.storage.Exists(TablePlayer, playerID)
 }
 
-// UpdateGold 更新金币
-func (pr *PlayerRepository) UpdateGold(playerID string, gold int) error {
-	player, err := pr.Get(playerID)
-	if err != nil {
-		return err
-	}
-	
-	player.Gold = gold
-	return pr.Save(player)
-}
-
 // UpdateLevel 更新等级
 func (pr *PlayerRepository) UpdateLevel(playerID string, level int, exp int) error {
-	player, err := pr.Get(playerID)
-	if err != nil {
-		return err
-	}
-	
-	player.Level = level
-	player.Exp = exp
-	return pr.Save(player)
+	return pr.Update(playerID, func(player *PlayerData) error {
+		player.Level = level
+		player.Exp = exp
+		return nil
+	})
 }
 
 // AddBattleRecord 添加战斗记录
 func (pr *PlayerRepository) AddBattleRecord(playerID string, isWin bool, kills int, wave int) error {
-	player, err := pr.Get(playerID)
-	if err != nil {
-		return err
+	return pr.Update(playerID, func(player *PlayerData) error {
+		player.ApplyBattle(isWin, kills, wave)
+		return nil
+	})
+}
+
+// hasBattle 是否已结算过该战斗
+func (player *PlayerData) hasBattle(battleID string) bool {
+	for _, id := range player.RecentBattles {
+		if id == battleID {
+			return true
+		}
 	}
-	
+	return false
+}
+
+// addRecentBattle 记录已结算的战斗ID（只保留最近的若干个）
+func (player *PlayerData) addRecentBattle(battleID string) {
+	player.RecentBattles = append(player.RecentBattles, battleID)
+	if len(player.RecentBattles) > maxRecentBattles {
+		player.RecentBattles = player.RecentBattles[len(player.RecentBattles)-maxRecentBattles:]
+	}
+}
+
+// ApplyBattle 累加一场战斗的统计
+func (player *PlayerData) ApplyBattle(isWin bool, kills int, wave int) {
 	player.TotalBattles++
 	if isWin {
 		player.WinCount++
@@ -106,8 +146,6 @@ func (pr *PlayerRepository) AddBattleRecord(playerID string, isWin bool, kills i
 	if wave > player.MaxWave {
 		player.MaxWave = wave
 	}
-	
-	return pr.Save(player)
 }
 
 // GetTopPlayers 获取排行榜（按等级排序）
@@ -133,12 +171,12 @@ func (pr *PlayerRepository) CreateDefaultPlayer(playerID, playerName string) (*P
 	player := &PlayerData{
 		PlayerID:      playerID,
 		PlayerName:    playerName,
-		IconID:        1,        // 默认头像ID
-		FrameID:       1,        // 默认头像框ID
+		IconID:        DefaultIconID,
+		FrameID:       DefaultFrameID,
 		Level:         1,        // 默认等级
 		Exp:           0,
-		Gold:          1000,     // 初始金币
-		Diamond:       100,      // 初始钻石
+		Gold:          0,        // 初始货币通过流水发放
+		Diamond:       0,
 		VipLevel:      0,
 		TotalBattles:  0,
 		WinCount:      0,
@@ -150,48 +188,121 @@ func (pr *PlayerRepository) CreateDefaultPlayer(playerID, playerName string) (*P
 		IsNewPlayer:   true,     // 标记为新玩家
 	}
 	
-	err := pr.Save(player)
+	entries := make([]*LedgerEntry, 0, 2)
+	for _, grant := range []struct {
+		currency string
+		amount   int
+	}{
+		{CurrencyGold, initialGold},
+		{CurrencyDiamond, initialDiamond},
+	} {
+		entry, err := player.ChangeCurrency(grant.currency, grant.amount, LedgerReasonInitial, "")
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	ops, err := ledgerOps(pr.storage, entries...)
+	if err != nil {
+		return nil, err
+	}
+	
+	// 初始名称（账号名）已被其他玩家改名占用时，截断后加上玩家ID后缀（仍被占用时再加序号），玩家可免费改名一次
+	claim, err := claimNameOp(pr.storage, player.PlayerName, playerID)
+	for i := 1; errors.Is(err, ErrNameTaken) && i <= maxFallbackNames; i++ {
+		suffix := shortID(playerID)
+		if i > 1 {
+			suffix = fmt.Sprintf("%s%d", suffix, i)
+		}
+		player.PlayerName = fallbackName(playerName, suffix)
+		claim, err = claimNameOp(pr.storage, player.PlayerName, playerID)
+	}
 	if err != nil {
 		return nil, err
 	}
+	ops = append(ops, claim)
+	
+	// 版本号为 0，仅在玩家数据不存在时写入，避免并发创建互相覆盖
+	ops = append(ops, storage.TxOp{Table: TablePlayer, Key: playerID, ExpectedVersion: 0, Data: player})
+	if err := storage.CompareAndSaveMulti(pr.storage, ops); err != nil {
+		return nil, err
+	}
 	return player, nil
 }
 
 // GetOrCreatePlayer 获取玩家数据，不存在则创建默认数据
 func (pr *PlayerRepository) GetOrCreatePlayer(playerID, playerName string) (*PlayerData, bool, error) {
-	player, err := pr.Get(playerID)
-	if err == nil {
-		// 玩家存在，更新最后登录时间
-		player.LastLoginTime = time.Now()
-		player.IsNewPlayer = false
-		pr.Save(player)
-		return player, false, nil // false 表示不是新创建的
+	for attempt := 0; ; attempt++ {
+		exists, err := pr.Exists(playerID)
+		if err != nil {
+			return nil, false, err
+		}
+		if exists {
+			break
+		}
+		if attempt >= storage.MaxUpdateRetries {
+			return nil, false, fmt.Errorf("%w: 玩家 %s 创建重试 %d 次仍失败", storage.ErrVersionConflict, playerID, storage.MaxUpdateRetries)
+		}
+		
+		// 玩家不存在，创建默认数据
+		player, err := pr.CreateDefaultPlayer(playerID, playerName)
+		if err == nil {
+			return player, true, nil // true 表示是新创建的
+		}
+		if !errors.Is(err, storage.ErrVersionConflict) {
+			return nil, false, err
+		}
+		// 版本冲突可能来自玩家数据（其他请求已创建，下一轮按已存在处理），
+		// 也可能来自名称索引或初始流水（名称刚被他人占用），重新检查后再创建
 	}
 	
-	// 玩家不存在，创建默认数据
-	player, err = pr.CreateDefaultPlayer(playerID, playerName)
+	// 玩家存在，更新最后登录时间
+	var player *PlayerData
+	err := pr.Update(playerID, func(p *PlayerData) error {
+		p.LastLoginTime = time.Now()
+		p.IsNewPlayer = false
+		player = p
+		return nil
+	})
 	if err != nil {
 		return nil, false, err
 	}
-	return player, true, nil // true 表示是新创建的
+	return player, false, nil // false 表示不是新创建的
 }
 
-// UpdatePlayerName 更新玩家名称
-func (pr *PlayerRepository) UpdatePlayerName(playerID, newName string) error {
-	player, err := pr.Get(playerID)
-	if err != nil {
-		return err
+// UpdatePlayerIcon 更新玩家头像
+func (pr *PlayerRepository) UpdatePlayerIcon(playerID string, iconID int) error {
+	return pr.Update(playerID, func(player *PlayerData) error {
+		player.IconID = iconID
+		return nil
+	})
+}
+
+// UpdatePlayerFrame 更新玩家头像框
+func (pr *PlayerRepository) UpdatePlayerFrame(playerID string, frameID int) error {
+	return pr.Update(playerID, func(player *PlayerData) error {
+		player.FrameID = frameID
+		return nil
+	})
+}
+
+// fallbackName 备用名称：账号名截断后加上后缀，总长度不超过名称长度上限
+func fallbackName(name, suffix string) string {
+	keep := config.Name.MaxLength - utf8.RuneCountInString(suffix) - 1
+	if keep <= 0 {
+		return suffix
+	}
+	runes := []rune(name)
+	if len(runes) > keep {
+		runes = runes[:keep]
 	}
-	player.PlayerName = newName
-	return pr.Save(player)
+	return string(runes) + "_" + suffix
 }
 
-// UpdatePlayerIcon 更新玩家头像
-func (pr *PlayerRepository) UpdatePlayerIcon(playerID string, iconID int) error {
-	player, err := pr.Get(playerID)
-	if err != nil {
-		return err
+// shortID 取玩家ID末尾几位（用于区分重名）
+func shortID(playerID string) string {
+	if len(playerID) <= 6 {
+		return playerID
 	}
-	player.IconID = iconID
-	return pr.Save(player)
+	return playerID[len(playerID)-6:]
 }
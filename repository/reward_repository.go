@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"towerdefense/storage"
+)
+
+// RewardItemData 奖励道具
+type RewardItemData struct {
+	ItemID    int    `json:"item_id"`
+	ItemCount int    `json:"item_count"`
+	ItemName  string `json:"item_name"`
+}
+
+// BattleRewardData 战斗奖励记录（每场战斗每个玩家一条）
+type BattleRewardData struct {
+	storage.VersionedRecord // 乐观锁版本号（只在不存在时写入）
+	storage.SchemaRecord    // 结构版本号
+	BattleID    string           `json:"battle_id"`
+	PlayerID    string           `json:"player_id"`
+	LevelID     int              `json:"level_id"`
+	IsWin       bool             `json:"is_win"`
+	Coin        int              `json:"coin"`
+	Exp         int              `json:"exp"`
+	Items       []RewardItemData `json:"items"`
+	LevelBefore int              `json:"level_before"`
+	LevelAfter  int              `json:"level_after"`
+	CreateTime  time.Time        `json:"create_time"`
+}
+
+const TableBattleReward = "battle_rewards"
+
+// BattleRewardKey 奖励记录的键
+func BattleRewardKey(battleID, playerID string) string {
+	return battleID + "_" + playerID
+}
+
+// RewardRepository 战斗奖励仓储
+type RewardRepository struct {
+	storage storage.IStorage
+}
+
+// NewRewardRepository 创建战斗奖励仓储
+func NewRewardRepository() *RewardRepository {
+	return &RewardRepository{
+		storage: storage.GetStorage(),
+	}
+}
+
+// Get 获取玩家某场战斗的奖励记录
+func (rr *RewardRepository) Get(battleID, playerID string) (*BattleRewardData, error) {
+	var reward BattleRewardData
+	if err := rr.storage.Get(TableBattleReward, BattleRewardKey(battleID, playerID), &reward); err != nil {
+		return nil, err
+	}
+	return &reward, nil
+}
+
+// Exists 检查奖励是否已发放
+func (rr *RewardRepository) Exists(battleID, playerID string) (bool, error) {
+	return rr.storage.Exists(TableBattleReward, BattleRewardKey(battleID, playerID))
+}
+
+// SettleBattle 结算一场战斗（幂等）
+// 玩家数据（统计、经验等）和背包（掉落道具）由 apply 修改，奖励金币记入货币流水，与奖励记录在同一个事务中写入：
+// 玩家已结算过该战斗时不做任何修改并返回 false
+func (rr *RewardRepository) SettleBattle(reward *BattleRewardData, apply func(player *PlayerData, inventory *InventoryData) error) (bool, error) {
+	for i := 0; i < storage.MaxUpdateRetries; i++ {
+		var player PlayerData
+		if err := rr.storage.Get(TablePlayer, reward.PlayerID, &player); err != nil {
+			return false, err
+		}
+		if player.hasBattle(reward.BattleID) {
+			return false, nil
+		}
+		inventory, err := loadInventory(rr.storage, reward.PlayerID)
+		if err != nil {
+			return false, err
+		}
+
+		expected := player.Version
+		expectedInventory := inventory.Version
+		if err := apply(&player, inventory); err != nil {
+			return false, err
+		}
+		player.addRecentBattle(reward.BattleID)
+		var entry *LedgerEntry
+		if reward.Coin > 0 {
+			entry, err = player.ChangeCurrency(CurrencyGold, reward.Coin, LedgerReasonBattleReward, reward.BattleID)
+			if err != nil {
+				return false, err
+			}
+		}
+
+		record := *reward
+		record.Version = 0
+		ops := []storage.TxOp{
+			{Table: TablePlayer, Key: player.PlayerID, ExpectedVersion: expected, Data: &player},
+			{Table: TableBattleReward, Key: BattleRewardKey(reward.BattleID, reward.PlayerID), ExpectedVersion: 0, Data: &record},
+		}
+		if len(reward.Items) > 0 {
+			inventory.UpdateTime = time.Now()
+			ops = append(ops, storage.TxOp{Table: TableInventory, Key: reward.PlayerID, ExpectedVersion: expectedInventory, Data: inventory})
+		}
+		if entry != nil {
+			entryOps, err := ledgerOps(rr.storage, entry)
+			if err != nil {
+				return false, err
+			}
+			ops = append(ops, entryOps...)
+		}
+
+		err = storage.CompareAndSaveMulti(rr.storage, ops)
+		if err == nil {
+			*reward = record
+			return true, nil
+		}
+		if !errors.Is(err, storage.ErrVersionConflict) {
+			return false, err
+		}
+	}
+
+	return false, fmt.Errorf("%w: 结算战斗 %s 玩家 %s 重试 %d 次仍失败", storage.ErrVersionConflict, reward.BattleID, reward.PlayerID, storage.MaxUpdateRetries)
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"towerdefense/storage"
+)
+
+// 各表当前结构版本
+// 修改数据模型（新增字段、调整字段含义）时：版本号 +1，并注册从旧版本升级的迁移函数
+// 迁移函数按版本号依次执行，读取时惰性升级，也可通过 -type=schema-migrate 批量升级
+const (
+	PlayerSchemaVersion       = 1
+	AccountSchemaVersion      = 1
+	GameRecordSchemaVersion   = 2
+	BattleRewardSchemaVersion = 1
+	InventorySchemaVersion    = 1
+	LedgerSchemaVersion       = 1
+	LedgerIndexSchemaVersion  = 1
+	CosmeticSchemaVersion     = 1
+	NameIndexSchemaVersion    = 1
+)
+
+func init() {
+	storage.RegisterSchema(TablePlayer, PlayerSchemaVersion)
+	storage.RegisterMigration(TablePlayer, 0, migratePlayerV0)
+
+	storage.RegisterSchema(TableAccount, AccountSchemaVersion)
+	storage.RegisterMigration(TableAccount, 0, migrateAccountV0)
+
+	storage.RegisterSchema(TableGameRecord, GameRecordSchemaVersion)
+	storage.RegisterMigration(TableGameRecord, 1, migrateGameRecordV1)
+
+	storage.RegisterSchema(TableBattleReward, BattleRewardSchemaVersion)
+
+	storage.RegisterSchema(TableInventory, InventorySchemaVersion)
+
+	storage.RegisterSchema(TableLedger, LedgerSchemaVersion)
+	storage.RegisterSchema(TableLedgerIndex, LedgerIndexSchemaVersion)
+	storage.RegisterSchema(TableLedgerPage, LedgerIndexSchemaVersion)
+
+	storage.RegisterSchema(TableCosmetic, CosmeticSchemaVersion)
+
+	storage.RegisterSchema(TableNameIndex, NameIndexSchemaVersion)
+}
+
+// migratePlayerV0 v0 -> v1：补齐未带版本号的旧数据中缺失的默认值
+func migratePlayerV0(record map[string]interface{}) error {
+	setDefaultNumber(record, "icon_id", 1)
+	setDefaultNumber(record, "frame_id", 1)
+	setDefaultNumber(record, "level", 1)
+	return nil
+}
+
+// migrateAccountV0 v0 -> v1：旧账号数据没有状态字段
+func migrateAccountV0(record map[string]interface{}) error {
+	if status, _ := record["status"].(string); status == "" {
+		record["status"] = "active"
+	}
+	return nil
+}
+
+// migrateGameRecordV1 v1 -> v2：旧战斗记录没有随机种子和操作日志，无法回放
+func migrateGameRecordV1(record map[string]interface{}) error {
+	setDefaultNumber(record, "seed", 0)
+	if _, ok := record["commands"].([]interface{}); !ok {
+		record["commands"] = []interface{}{}
+	}
+	return nil
+}
+
+// setDefaultNumber 字段缺失或为 0 时设置默认值
+func setDefaultNumber(record map[string]interface{}, field string, value float64) {
+	if v, ok := record[field].(float64); !ok || v == 0 {
+		record[field] = value
+	}
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"time"
+	"towerdefense/storage"
+)
+
+// SessionData 登录会话（带过期时间的临时数据）
+type SessionData struct {
+	Token      string    `json:"token"`
+	PlayerID   string    `json:"player_id"`
+	Username   string    `json:"username"`
+	ExpireTime time.Time `json:"expire_time"`
+}
+
+// playerSessionData 玩家当前会话索引（player_id -> token）
+type playerSessionData struct {
+	Token string `json:"token"`
+}
+
+const (
+	TableSession       = "sessions"        // token -> 会话
+	TablePlayerSession = "player_sessions" // player_id -> 当前 token
+)
+
+// SessionRepository 会话仓储
+// 会话以 TTL 写入存储，到期后由存储自动清理
+type SessionRepository struct {
+	storage storage.IStorage
+}
+
+// NewSessionRepository 创建会话仓储
+func NewSessionRepository() *SessionRepository {
+	return &SessionRepository{
+		storage: storage.GetStorage(),
+	}
+}
+
+// Save 保存会话，并登记为该玩家的当前会话
+func (sr *SessionRepository) Save(session *SessionData) error {
+	ttl := time.Until(session.ExpireTime)
+	if err := sr.storage.SaveWithTTL(TableSession, session.Token, session, ttl); err != nil {
+		return err
+	}
+	return sr.storage.SaveWithTTL(TablePlayerSession, session.PlayerID, &playerSessionData{Token: session.Token}, ttl)
+}
+
+// Get 根据 token 获取会话（不存在或已过期时返回错误）
+func (sr *SessionRepository) Get(token string) (*SessionData, error) {
+	var session SessionData
+	if err := sr.storage.Get(TableSession, token, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetTokenByPlayer 获取玩家当前会话的 token，没有有效会话时返回空字符串
+func (sr *SessionRepository) GetTokenByPlayer(playerID string) string {
+	var index playerSessionData
+	if err := sr.storage.Get(TablePlayerSession, playerID, &index); err != nil {
+		return ""
+	}
+	return index.Token
+}
+
+// Delete 删除会话（同时清理玩家会话索引）
+func (sr *SessionRepository) Delete(session *SessionData) error {
+	if err := sr.storage.Delete(TableSession, session.Token); err != nil {
+		return err
+	}
+	if sr.GetTokenByPlayer(session.PlayerID) == session.Token {
+		return sr.storage.Delete(TablePlayerSession, session.PlayerID)
+	}
+	return nil
+}
+
+// Count 当前有效会话数量
+func (sr *SessionRepository) Count() (int, error) {
+	keys, err := sr.storage.Keys(TableSession)
+	if err != nil {
+		return 0, err
+	}
+	return len(keys), nil
+}
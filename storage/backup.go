@@ -0,0 +1,474 @@
+package storage
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"towerdefense/utils"
+)
+
+const (
+	backupFilePrefix    = "backup-"
+	backupFileSuffix    = ".tar.gz"
+	backupManifestName  = "manifest.json"
+	backupFormatVersion = 1
+)
+
+// Snapshot 全量数据快照：table -> key -> 原始JSON
+type Snapshot map[string]map[string]json.RawMessage
+
+// ISnapshotter 支持一致性快照的存储（可选能力）
+// 快照期间阻塞写入，保证得到同一时刻的全量数据
+type ISnapshotter interface {
+	Snapshot() (Snapshot, error)
+}
+
+// IExpiryReporter 支持按记录设置过期时间的存储（可选能力）
+// 快照和迁移不复制带过期时间的临时数据（会话等）：写入目标时过期时间会丢失，临时数据会变为永久数据
+type IExpiryReporter interface {
+	HasExpiry(table, key string) (bool, error)
+}
+
+// recordHasExpiry 记录是否设置了过期时间，存储不支持过期时间时返回 false
+func recordHasExpiry(s IStorage, table, key string) (bool, error) {
+	if reporter, ok := s.(IExpiryReporter); ok {
+		return reporter.HasExpiry(table, key)
+	}
+	return false, nil
+}
+
+// snapshotWrapper 装饰其他存储的存储（结构版本、缓存），快照是否一致由下层存储决定
+type snapshotWrapper interface {
+	wrappedSnapshot() (Snapshot, bool, error)
+}
+
+// BackupManifest 备份清单
+type BackupManifest struct {
+	FormatVersion int                        `json:"format_version"`
+	CreateTime    time.Time                  `json:"create_time"`
+	Consistent    bool                       `json:"consistent"` // 是否为一致性快照
+	Tables        map[string]BackupTableInfo `json:"tables"`
+}
+
+// BackupTableInfo 备份中单表信息
+type BackupTableInfo struct {
+	Count    int    `json:"count"`
+	Checksum string `json:"checksum"`
+}
+
+// RestoreOptions 恢复选项
+type RestoreOptions struct {
+	Table    string // 只恢复指定表，为空表示全部
+	PlayerID string // 只恢复指定玩家的记录（键为玩家ID或 player_id 字段匹配）
+	DryRun   bool   // 只统计不写入
+}
+
+// TakeSnapshot 获取全量快照，带过期时间的临时数据不进入快照
+// 存储支持 ISnapshotter 时为一致性快照，否则逐表读取（运行中写入的数据可能只包含一部分）
+// 装饰器按下层存储的能力获取快照
+func TakeSnapshot(s IStorage) (Snapshot, bool, error) {
+	if wrapper, ok := s.(snapshotWrapper); ok {
+		return wrapper.wrappedSnapshot()
+	}
+	if snapshotter, ok := s.(ISnapshotter); ok {
+		snapshot, err := snapshotter.Snapshot()
+		return snapshot, true, err
+	}
+
+	tables, err := s.Tables()
+	if err != nil {
+		return nil, false, err
+	}
+
+	snapshot := make(Snapshot)
+	for _, table := range tables {
+		keys, err := s.Keys(table)
+		if err != nil {
+			return nil, false, err
+		}
+		records := make(map[string]json.RawMessage, len(keys))
+		for _, key := range keys {
+			temporary, err := recordHasExpiry(s, table, key)
+			if err != nil {
+				return nil, false, err
+			}
+			if temporary {
+				continue
+			}
+			var raw json.RawMessage
+			if err := s.Get(table, key, &raw); err != nil {
+				return nil, false, fmt.Errorf("读取 %s/%s 失败: %v", table, key, err)
+			}
+			records[key] = raw
+		}
+		snapshot[table] = records
+	}
+	return snapshot, false, nil
+}
+
+// Backup 备份全部数据到 dir 下的压缩包，返回备份文件路径
+func Backup(s IStorage, dir string) (string, *BackupManifest, error) {
+	snapshot, consistent, err := TakeSnapshot(s)
+	if err != nil {
+		return "", nil, fmt.Errorf("获取快照失败: %v", err)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", nil, fmt.Errorf("创建备份目录失败: %v", err)
+	}
+
+	manifest := &BackupManifest{
+		FormatVersion: backupFormatVersion,
+		CreateTime:    time.Now(),
+		Consistent:    consistent,
+		Tables:        make(map[string]BackupTableInfo),
+	}
+
+	// 文件名精确到毫秒，同一秒内的多次备份不会互相覆盖；仍然重名时追加序号（字典序仍为时间序）
+	stamp := manifest.CreateTime.Format("20060102-150405.000")
+	filePath := filepath.Join(dir, backupFilePrefix+stamp+backupFileSuffix)
+	for i := 1; ; i++ {
+		if _, err := os.Stat(filePath); err != nil {
+			break
+		}
+		filePath = filepath.Join(dir, fmt.Sprintf("%s%s_%d%s", backupFilePrefix, stamp, i, backupFileSuffix))
+	}
+	tmpPath := filePath + ".tmp"
+
+	if err := writeBackupArchive(tmpPath, snapshot, manifest); err != nil {
+		os.Remove(tmpPath)
+		return "", nil, err
+	}
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		os.Remove(tmpPath)
+		return "", nil, fmt.Errorf("保存备份文件失败: %v", err)
+	}
+
+	return filePath, manifest, nil
+}
+
+// writeBackupArchive 写入 tar.gz：manifest.json + tables/<table>/<key>.json
+func writeBackupArchive(filePath string, snapshot Snapshot, manifest *BackupManifest) error {
+	f, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("创建备份文件失败: %v", err)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	tables := make([]string, 0, len(snapshot))
+	for table := range snapshot {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	// 先计算清单，清单放在压缩包第一个，恢复时可先校验
+	for _, table := range tables {
+		manifest.Tables[table] = BackupTableInfo{
+			Count:    len(snapshot[table]),
+			Checksum: snapshotChecksum(snapshot[table]),
+		}
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeTarFile(tw, backupManifestName, manifestData, manifest.CreateTime); err != nil {
+		return err
+	}
+
+	for _, table := range tables {
+		keys := sortedKeys(snapshot[table])
+		for _, key := range keys {
+			name := path.Join("tables", table, key+".json")
+			if err := writeTarFile(tw, name, snapshot[table][key], manifest.CreateTime); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+func writeTarFile(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// snapshotChecksum 单表校验和（记录规范化后计算，与 TableChecksum 一致）
+func snapshotChecksum(records map[string]json.RawMessage) string {
+	hash := sha256.New()
+	for _, key := range sortedKeys(records) {
+		var value interface{}
+		canonical := []byte(records[key])
+		if err := json.Unmarshal(records[key], &value); err == nil {
+			canonical, _ = json.Marshal(value)
+		}
+		hash.Write([]byte(key))
+		hash.Write([]byte{0})
+		hash.Write(canonical)
+		hash.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func sortedKeys(records map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(records))
+	for key := range records {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ReadBackup 读取备份压缩包，校验清单中的条数和校验和
+func ReadBackup(archivePath string) (*BackupManifest, Snapshot, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("打开备份文件失败: %v", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, nil, fmt.Errorf("备份文件格式错误: %v", err)
+	}
+	defer gz.Close()
+
+	var manifest *BackupManifest
+	snapshot := make(Snapshot)
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("读取备份文件失败: %v", err)
+		}
+
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("读取备份文件失败: %v", err)
+		}
+
+		if header.Name == backupManifestName {
+			manifest = &BackupManifest{}
+			if err := json.Unmarshal(data, manifest); err != nil {
+				return nil, nil, fmt.Errorf("备份清单格式错误: %v", err)
+			}
+			continue
+		}
+
+		// tables/<table>/<key>.json
+		parts := strings.Split(header.Name, "/")
+		if len(parts) != 3 || parts[0] != "tables" || !strings.HasSuffix(parts[2], ".json") {
+			continue
+		}
+		table, key := parts[1], strings.TrimSuffix(parts[2], ".json")
+		if snapshot[table] == nil {
+			snapshot[table] = make(map[string]json.RawMessage)
+		}
+		snapshot[table][key] = data
+	}
+
+	if manifest == nil {
+		return nil, nil, fmt.Errorf("备份文件缺少清单")
+	}
+	if manifest.FormatVersion > backupFormatVersion {
+		return nil, nil, fmt.Errorf("备份格式版本 %d 高于当前支持的版本 %d", manifest.FormatVersion, backupFormatVersion)
+	}
+	for table, info := range manifest.Tables {
+		records := snapshot[table]
+		if len(records) != info.Count || snapshotChecksum(records) != info.Checksum {
+			return nil, nil, fmt.Errorf("备份文件损坏: 表 %s 校验不一致", table)
+		}
+	}
+
+	return manifest, snapshot, nil
+}
+
+// Restore 从备份恢复数据（整库、单表或单个玩家）
+// 恢复的记录版本号会设置为当前版本+1，持有旧数据的并发写入将因版本冲突而重试
+func Restore(s IStorage, archivePath string, opts RestoreOptions) (map[string]int, error) {
+	_, snapshot, err := ReadBackup(archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	restored := make(map[string]int)
+	for _, table := range sortedTables(snapshot) {
+		if opts.Table != "" && table != opts.Table {
+			continue
+		}
+
+		items := make(map[string]interface{})
+		for key, raw := range snapshot[table] {
+			var record map[string]interface{}
+			if err := json.Unmarshal(raw, &record); err != nil {
+				if opts.PlayerID == "" {
+					items[key] = raw // 非对象记录原样恢复
+				}
+				continue
+			}
+			if opts.PlayerID != "" && !recordBelongsToPlayer(key, record, opts.PlayerID) {
+				continue
+			}
+
+			if _, versioned := record["version"]; versioned {
+				var current VersionedRecord
+				if err := s.Get(table, key, &current); err == nil {
+					record["version"] = current.Version + 1
+				}
+			}
+			items[key] = record
+		}
+
+		if len(items) == 0 {
+			continue
+		}
+		if !opts.DryRun {
+			if err := s.SaveBatch(table, items); err != nil {
+				return restored, fmt.Errorf("恢复表 %s 失败: %v", table, err)
+			}
+		}
+		restored[table] = len(items)
+	}
+
+	return restored, nil
+}
+
+// recordBelongsToPlayer 记录是否属于指定玩家
+func recordBelongsToPlayer(key string, record map[string]interface{}, playerID string) bool {
+	if key == playerID {
+		return true
+	}
+	if id, ok := record["player_id"].(string); ok && id == playerID {
+		return true
+	}
+	return false
+}
+
+func sortedTables(snapshot Snapshot) []string {
+	tables := make([]string, 0, len(snapshot))
+	for table := range snapshot {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	return tables
+}
+
+// RotateBackups 只保留最新的 keep 个备份文件
+func RotateBackups(dir string, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	backups := make([]string, 0)
+	for _, file := range files {
+		name := file.Name()
+		if !file.IsDir() && strings.HasPrefix(name, backupFilePrefix) && strings.HasSuffix(name, backupFileSuffix) {
+			backups = append(backups, name)
+		}
+	}
+	sort.Strings(backups) // 文件名带时间戳，字典序即时间序
+
+	for i := 0; i < len(backups)-keep; i++ {
+		if err := os.Remove(filepath.Join(dir, backups[i])); err != nil {
+			return err
+		}
+		utils.Info("删除过期备份: %s", backups[i])
+	}
+	return nil
+}
+
+// BackupConfig 定时备份配置
+type BackupConfig struct {
+	Dir      string
+	Interval time.Duration
+	Keep     int
+}
+
+// ParseBackupConfig 从存储配置的 backup 段解析定时备份配置，未配置时返回 nil
+//
+//	"backup": { "dir": "./backups", "interval_minutes": 60, "keep": 24 }
+func ParseBackupConfig(settings map[string]interface{}) *BackupConfig {
+	raw, ok := settings["backup"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	cfg := &BackupConfig{
+		Dir:      "./backups",
+		Interval: time.Duration(settingInt(raw, "interval_minutes", 60)) * time.Minute,
+		Keep:     settingInt(raw, "keep", 24),
+	}
+	if dir, ok := raw["dir"].(string); ok && dir != "" {
+		cfg.Dir = dir
+	}
+	if cfg.Interval <= 0 {
+		return nil
+	}
+	return cfg
+}
+
+var backupSchedulerOnce sync.Once
+
+// StartBackupScheduler 启动定时备份（对全局存储做一致性快照并轮换旧备份）
+func StartBackupScheduler(cfg BackupConfig) {
+	backupSchedulerOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(cfg.Interval)
+			defer ticker.Stop()
+
+			for range ticker.C {
+				filePath, manifest, err := Backup(GetStorage(), cfg.Dir)
+				if err != nil {
+					utils.Error("定时备份失败: %v", err)
+					continue
+				}
+				utils.Info("定时备份完成: %s, 表: %d 张", filePath, len(manifest.Tables))
+
+				if err := RotateBackups(cfg.Dir, cfg.Keep); err != nil {
+					utils.Error("备份轮换失败: %v", err)
+				}
+			}
+		}()
+		utils.Info("定时备份已启用，目录: %s，间隔: %v，保留: %d 份", cfg.Dir, cfg.Interval, cfg.Keep)
+	})
+}
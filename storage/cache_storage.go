@@ -0,0 +1,673 @@
+package storage
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"towerdefense/utils"
+)
+
+// CacheTableConfig 单表缓存配置
+type CacheTableConfig struct {
+	Capacity    int  // LRU 容量（条）
+	WriteBehind bool // true: 写入只进缓存，定时批量刷盘；false: 写穿透
+	Shared      bool // 多个进程（账号服和游戏服）都会写入该表，缓存只在本进程内有效，强制写穿透
+}
+
+// CacheConfig 缓存配置
+type CacheConfig struct {
+	FlushInterval time.Duration
+	Tables        map[string]CacheTableConfig
+}
+
+// cacheEntry 缓存条目（保存序列化后的数据，避免调用方修改缓存中的对象）
+type cacheEntry struct {
+	key     string
+	data    json.RawMessage
+	version int64
+	dirty   bool
+	gen     uint64 // 每次写入递增，刷盘期间被再次写入的条目保持 dirty
+}
+
+// tableCache 单表 LRU 缓存
+type tableCache struct {
+	config  CacheTableConfig
+	entries map[string]*list.Element
+	lru     *list.List             // 前端为最近使用
+	evicted map[string]*cacheEntry // 已被淘汰、等待刷盘写回的脏条目
+}
+
+// CachedStorage 缓存装饰器
+// 为配置的表提供 LRU 读缓存；WriteBehind 表的写入先进入缓存并标记为脏，由后台定时批量刷入下层存储
+// 同一进程内缓存是这些表的权威副本，乐观锁版本校验在缓存中完成
+// 写回下层存储的操作（刷盘、淘汰条目写回、写穿透表的写入、删除和多键事务）都在 flushMu 下串行执行，
+// 避免较早复制的脏数据覆盖较新的写入；cs.mu 只保护缓存本身，写穿透时不在持有 cs.mu 时写下层存储；
+// 锁顺序为 flushMu -> mu
+type CachedStorage struct {
+	inner    IStorage
+	config   CacheConfig
+	tables   map[string]*tableCache
+	gen      uint64
+	mu       sync.Mutex
+	flushMu  sync.Mutex // 串行化刷盘
+	stopChan chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// ParseCacheConfig 从存储配置的 cache 段解析缓存配置，未配置时返回 nil
+//
+//	"cache": {
+//	  "flush_interval": 5,
+//	  "tables": { "players": { "capacity": 10000, "write_behind": true, "shared": false } }
+//	}
+//
+// 多个进程都会写入的表需配置 "shared": true，此时不启用写回缓存
+func ParseCacheConfig(settings map[string]interface{}) *CacheConfig {
+	raw, ok := settings["cache"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	cfg := &CacheConfig{
+		FlushInterval: time.Duration(settingInt(raw, "flush_interval", 5)) * time.Second,
+		Tables:        make(map[string]CacheTableConfig),
+	}
+
+	tables, _ := raw["tables"].(map[string]interface{})
+	for table, v := range tables {
+		tableRaw, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		writeBehind, _ := tableRaw["write_behind"].(bool)
+		shared, _ := tableRaw["shared"].(bool)
+		if shared && writeBehind {
+			utils.Warn("缓存表 %s 由多个进程写入，已关闭 write_behind", table)
+			writeBehind = false
+		}
+		cfg.Tables[table] = CacheTableConfig{
+			Capacity:    settingInt(tableRaw, "capacity", 10000),
+			WriteBehind: writeBehind,
+			Shared:      shared,
+		}
+	}
+
+	if len(cfg.Tables) == 0 {
+		return nil
+	}
+	return cfg
+}
+
+// settingInt 读取整数配置（JSON 数字解析为 float64）
+func settingInt(settings map[string]interface{}, key string, def int) int {
+	switch v := settings[key].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	}
+	return def
+}
+
+// NewCachedStorage 创建缓存装饰器并启动后台刷盘
+func NewCachedStorage(inner IStorage, config CacheConfig) *CachedStorage {
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = 5 * time.Second
+	}
+
+	cs := &CachedStorage{
+		inner:    inner,
+		config:   config,
+		tables:   make(map[string]*tableCache),
+		stopChan: make(chan struct{}),
+	}
+	for table, tc := range config.Tables {
+		if tc.Capacity <= 0 {
+			tc.Capacity = 10000
+		}
+		if tc.Shared {
+			tc.WriteBehind = false
+		}
+		cs.tables[table] = &tableCache{
+			config:  tc,
+			entries: make(map[string]*list.Element),
+			lru:     list.New(),
+			evicted: make(map[string]*cacheEntry),
+		}
+	}
+
+	cs.wg.Add(1)
+	go cs.flushLoop()
+
+	utils.Info("存储缓存已启用，缓存表: %s，刷盘间隔: %v", strings.Join(cs.cachedTableNames(), ","), config.FlushInterval)
+	return cs
+}
+
+func (cs *CachedStorage) cachedTableNames() []string {
+	names := make([]string, 0, len(cs.tables))
+	for table := range cs.tables {
+		names = append(names, table)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// flushLoop 定时刷盘
+func (cs *CachedStorage) flushLoop() {
+	defer cs.wg.Done()
+
+	ticker := time.NewTicker(cs.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := cs.Flush(); err != nil {
+				utils.Error("缓存刷盘失败: %v", err)
+			}
+		case <-cs.stopChan:
+			return
+		}
+	}
+}
+
+// Init 初始化存储（下层存储已在创建前初始化）
+func (cs *CachedStorage) Init(config map[string]interface{}) error {
+	return nil
+}
+
+// Close 停止后台刷盘，写回所有脏数据后关闭下层存储
+func (cs *CachedStorage) Close() error {
+	cs.stopOnce.Do(func() {
+		close(cs.stopChan)
+	})
+	cs.wg.Wait()
+
+	if err := cs.Flush(); err != nil {
+		utils.Error("关闭前缓存刷盘失败: %v", err)
+		return err
+	}
+	return cs.inner.Close()
+}
+
+// ========== 缓存内部操作（调用时已持有 cs.mu） ==========
+
+// lookupLocked 查找缓存条目并刷新 LRU 位置
+func (cs *CachedStorage) lookupLocked(tc *tableCache, key string) *cacheEntry {
+	elem, ok := tc.entries[key]
+	if !ok {
+		return nil
+	}
+	tc.lru.MoveToFront(elem)
+	return elem.Value.(*cacheEntry)
+}
+
+// putLocked 写入缓存条目，超出容量时淘汰最久未使用的条目
+// 淘汰的脏条目交给下一次刷盘写回，不在持有 cs.mu 时直接写下层存储
+func (cs *CachedStorage) putLocked(tc *tableCache, key string, data json.RawMessage, version int64, dirty bool) {
+	cs.gen++
+	delete(tc.evicted, key)
+	if elem, ok := tc.entries[key]; ok {
+		entry := elem.Value.(*cacheEntry)
+		entry.data = data
+		entry.version = version
+		entry.dirty = entry.dirty || dirty
+		entry.gen = cs.gen
+		tc.lru.MoveToFront(elem)
+		return
+	}
+
+	entry := &cacheEntry{key: key, data: data, version: version, dirty: dirty, gen: cs.gen}
+	tc.entries[key] = tc.lru.PushFront(entry)
+
+	for tc.lru.Len() > tc.config.Capacity {
+		oldest := tc.lru.Back()
+		victim := oldest.Value.(*cacheEntry)
+		if victim.dirty {
+			tc.evicted[victim.key] = victim
+		}
+		tc.lru.Remove(oldest)
+		delete(tc.entries, victim.key)
+	}
+}
+
+// removeLocked 移除缓存条目（包括等待写回的淘汰条目）
+func (cs *CachedStorage) removeLocked(tc *tableCache, key string) {
+	if elem, ok := tc.entries[key]; ok {
+		tc.lru.Remove(elem)
+		delete(tc.entries, key)
+	}
+	delete(tc.evicted, key)
+}
+
+// loadLocked 缓存未命中时从下层存储加载，记录不存在返回 nil
+func (cs *CachedStorage) loadLocked(table string, tc *tableCache, key string) (*cacheEntry, error) {
+	if entry := cs.lookupLocked(tc, key); entry != nil {
+		return entry, nil
+	}
+	// 尚未写回的淘汰条目比下层存储新，重新放回缓存
+	if victim, ok := tc.evicted[key]; ok {
+		cs.putLocked(tc, key, victim.data, victim.version, true)
+		return cs.lookupLocked(tc, key), nil
+	}
+
+	exists, err := cs.inner.Exists(table, key)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	var raw json.RawMessage
+	if err := cs.inner.Get(table, key, &raw); err != nil {
+		return nil, err
+	}
+	var record VersionedRecord
+	json.Unmarshal(raw, &record)
+
+	cs.putLocked(tc, key, raw, record.Version, false)
+	return cs.lookupLocked(tc, key), nil
+}
+
+// ========== IStorage ==========
+
+// Save 保存数据
+func (cs *CachedStorage) Save(table string, key string, data interface{}) error {
+	tc, ok := cs.tables[table]
+	if !ok {
+		return cs.inner.Save(table, key, data)
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("JSON序列化失败: %v", err)
+	}
+	// 版本号以序列化结果为准（data 可能是 map 或原始JSON）
+	var record VersionedRecord
+	json.Unmarshal(raw, &record)
+	version := record.Version
+
+	if tc.config.WriteBehind {
+		cs.mu.Lock()
+		cs.putLocked(tc, key, raw, version, true)
+		cs.mu.Unlock()
+		return nil
+	}
+
+	// 写穿透：下层写入在 flushMu 下执行，cs.mu 只在更新缓存条目时持有
+	cs.flushMu.Lock()
+	defer cs.flushMu.Unlock()
+
+	if err := cs.inner.Save(table, key, data); err != nil {
+		return err
+	}
+	cs.mu.Lock()
+	cs.putLocked(tc, key, raw, version, false)
+	cs.mu.Unlock()
+	return nil
+}
+
+// SaveWithTTL 保存数据并设置过期时间
+// 缓存中的数据不会过期，临时数据所在的表不应配置缓存
+func (cs *CachedStorage) SaveWithTTL(table string, key string, data interface{}, ttl time.Duration) error {
+	if _, ok := cs.tables[table]; ok {
+		return fmt.Errorf("缓存表 %s 不支持设置过期时间", table)
+	}
+	return cs.inner.SaveWithTTL(table, key, data, ttl)
+}
+
+// Get 获取数据
+func (cs *CachedStorage) Get(table string, key string, result interface{}) error {
+	tc, ok := cs.tables[table]
+	if !ok {
+		return cs.inner.Get(table, key, result)
+	}
+
+	cs.mu.Lock()
+	entry, err := cs.loadLocked(table, tc, key)
+	var raw json.RawMessage
+	if entry != nil {
+		raw = entry.data
+	}
+	cs.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		return fmt.Errorf("数据不存在: %s/%s", table, key)
+	}
+	if err := json.Unmarshal(raw, result); err != nil {
+		return fmt.Errorf("JSON反序列化失败: %v", err)
+	}
+	return nil
+}
+
+// Delete 删除数据（立即删除下层数据）
+// 在 flushMu 下执行，进行中的刷盘不会把已删除的记录写回
+func (cs *CachedStorage) Delete(table string, key string) error {
+	tc, ok := cs.tables[table]
+	if !ok {
+		return cs.inner.Delete(table, key)
+	}
+
+	cs.flushMu.Lock()
+	defer cs.flushMu.Unlock()
+
+	cs.mu.Lock()
+	cs.removeLocked(tc, key)
+	cs.mu.Unlock()
+	return cs.inner.Delete(table, key)
+}
+
+// GetAll 获取所有数据（先写回该表脏数据）
+func (cs *CachedStorage) GetAll(table string) ([]interface{}, error) {
+	if err := cs.FlushTable(table); err != nil {
+		return nil, err
+	}
+	return cs.inner.GetAll(table)
+}
+
+// Query 条件查询（先写回该表脏数据）
+func (cs *CachedStorage) Query(table string, condition map[string]interface{}) ([]interface{}, error) {
+	if err := cs.FlushTable(table); err != nil {
+		return nil, err
+	}
+	return cs.inner.Query(table, condition)
+}
+
+// SaveBatch 批量保存
+func (cs *CachedStorage) SaveBatch(table string, items map[string]interface{}) error {
+	tc, ok := cs.tables[table]
+	if !ok {
+		return cs.inner.SaveBatch(table, items)
+	}
+
+	if !tc.config.WriteBehind {
+		cs.flushMu.Lock()
+		defer cs.flushMu.Unlock()
+
+		if err := cs.inner.SaveBatch(table, items); err != nil {
+			return err
+		}
+		cs.mu.Lock()
+		for key := range items {
+			cs.removeLocked(tc, key)
+		}
+		cs.mu.Unlock()
+		return nil
+	}
+
+	for key, data := range items {
+		if err := cs.Save(table, key, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Exists 检查是否存在
+func (cs *CachedStorage) Exists(table string, key string) (bool, error) {
+	if tc, ok := cs.tables[table]; ok {
+		cs.mu.Lock()
+		entry := cs.lookupLocked(tc, key)
+		_, pending := tc.evicted[key]
+		cs.mu.Unlock()
+		if entry != nil || pending {
+			return true, nil
+		}
+	}
+	return cs.inner.Exists(table, key)
+}
+
+// Tables 列出所有表（先写回全部脏数据，保证新表可见）
+func (cs *CachedStorage) Tables() ([]string, error) {
+	if err := cs.Flush(); err != nil {
+		return nil, err
+	}
+	return cs.inner.Tables()
+}
+
+// Keys 列出表中所有键（先写回该表脏数据）
+func (cs *CachedStorage) Keys(table string) ([]string, error) {
+	if err := cs.FlushTable(table); err != nil {
+		return nil, err
+	}
+	return cs.inner.Keys(table)
+}
+
+// CompareAndSave 比较并保存（缓存表的版本校验在缓存中完成）
+func (cs *CachedStorage) CompareAndSave(table string, key string, expectedVersion int64, data Versioned) error {
+	tc, ok := cs.tables[table]
+	if !ok {
+		return cs.inner.CompareAndSave(table, key, expectedVersion, data)
+	}
+
+	if !tc.config.WriteBehind {
+		return cs.compareAndSaveThrough(table, tc, key, expectedVersion, data)
+	}
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	entry, err := cs.loadLocked(table, tc, key)
+	if err != nil {
+		return err
+	}
+	current := int64(0)
+	if entry != nil {
+		current = entry.version
+	}
+	if current != expectedVersion {
+		return fmt.Errorf("%w: %s/%s 期望版本 %d，当前版本 %d", ErrVersionConflict, table, key, expectedVersion, current)
+	}
+
+	data.SetVersion(expectedVersion + 1)
+	raw, err := json.Marshal(data)
+	if err != nil {
+		data.SetVersion(expectedVersion)
+		return fmt.Errorf("JSON序列化失败: %v", err)
+	}
+	cs.putLocked(tc, key, raw, expectedVersion+1, true)
+	return nil
+}
+
+// compareAndSaveThrough 写穿透表的比较并保存：版本校验和写入由下层存储在 flushMu 下完成，
+// cs.mu 只在更新缓存条目时持有；写入失败时使缓存条目失效，下次读取时重新加载
+func (cs *CachedStorage) compareAndSaveThrough(table string, tc *tableCache, key string, expectedVersion int64, data Versioned) error {
+	cs.flushMu.Lock()
+	defer cs.flushMu.Unlock()
+
+	err := cs.inner.CompareAndSave(table, key, expectedVersion, data)
+	var raw json.RawMessage
+	if err == nil {
+		raw, _ = json.Marshal(data)
+	}
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if err != nil || raw == nil {
+		cs.removeLocked(tc, key)
+		return err
+	}
+	cs.putLocked(tc, key, raw, data.GetVersion(), false)
+	return nil
+}
+
+// CompareAndSaveMulti 多键事务写入
+// 先写回涉及表的脏数据并使相关缓存失效，再由下层存储完成事务
+// 整个过程持有 flushMu 和 cs.mu，写回与事务之间不会插入新的缓存写入
+func (cs *CachedStorage) CompareAndSaveMulti(ops []TxOp) error {
+	cs.flushMu.Lock()
+	defer cs.flushMu.Unlock()
+
+	touched := make(map[string]bool)
+	for _, op := range ops {
+		if _, ok := cs.tables[op.Table]; ok && !touched[op.Table] {
+			touched[op.Table] = true
+			if err := cs.flushTableLocked(op.Table); err != nil {
+				return err
+			}
+		}
+	}
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	err := CompareAndSaveMulti(cs.inner, ops)
+	for _, op := range ops {
+		if tc, ok := cs.tables[op.Table]; ok {
+			cs.removeLocked(tc, op.Key)
+		}
+	}
+	return err
+}
+
+// ========== 刷盘 ==========
+
+// Flush 写回所有表的脏数据
+func (cs *CachedStorage) Flush() error {
+	for _, table := range cs.cachedTableNames() {
+		if err := cs.FlushTable(table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FlushTable 批量写回单表脏数据
+func (cs *CachedStorage) FlushTable(table string) error {
+	cs.flushMu.Lock()
+	defer cs.flushMu.Unlock()
+	return cs.flushTableLocked(table)
+}
+
+// flushTableLocked 批量写回单表脏数据和等待写回的淘汰条目（调用时已持有 flushMu）
+// 写回期间被再次写入的条目保持 dirty，由下一次刷盘写回
+func (cs *CachedStorage) flushTableLocked(table string) error {
+	tc, ok := cs.tables[table]
+	if !ok || !tc.config.WriteBehind {
+		return nil
+	}
+
+	cs.mu.Lock()
+	items := make(map[string]interface{})
+	flushed := make(map[string]*cacheEntry)
+	for key, victim := range tc.evicted {
+		items[key] = victim.data
+		flushed[key] = victim
+	}
+	for key, elem := range tc.entries {
+		entry := elem.Value.(*cacheEntry)
+		if !entry.dirty {
+			continue
+		}
+		// 同一键只写回版本最新的数据
+		if prev, ok := flushed[key]; ok && prev.version > entry.version {
+			continue
+		}
+		items[key] = entry.data
+		flushed[key] = entry
+	}
+	gens := make(map[string]uint64, len(flushed))
+	for key, entry := range flushed {
+		gens[key] = entry.gen
+	}
+	cs.mu.Unlock()
+
+	if len(items) == 0 {
+		return nil
+	}
+	if err := cs.inner.SaveBatch(table, items); err != nil {
+		return err
+	}
+
+	cs.mu.Lock()
+	for key, gen := range gens {
+		if victim, ok := tc.evicted[key]; ok && victim.gen == gen {
+			delete(tc.evicted, key)
+		}
+		if elem, ok := tc.entries[key]; ok {
+			entry := elem.Value.(*cacheEntry)
+			if entry.gen == gen {
+				entry.dirty = false
+			}
+		}
+	}
+	cs.mu.Unlock()
+	return nil
+}
+
+// FlushKey 写回单条脏数据（例如玩家登出时）
+func (cs *CachedStorage) FlushKey(table string, key string) error {
+	tc, ok := cs.tables[table]
+	if !ok || !tc.config.WriteBehind {
+		return nil
+	}
+
+	cs.flushMu.Lock()
+	defer cs.flushMu.Unlock()
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	entry, ok := tc.evicted[key]
+	if elem, cached := tc.entries[key]; cached {
+		entry, ok = elem.Value.(*cacheEntry), true
+	}
+	if !ok || !entry.dirty {
+		return nil
+	}
+	if err := cs.inner.Save(table, key, entry.data); err != nil {
+		return err
+	}
+	entry.dirty = false
+	delete(tc.evicted, key)
+	return nil
+}
+
+// HasExpiry 记录是否设置了过期时间（缓存表不支持过期时间，由下层存储判断）
+func (cs *CachedStorage) HasExpiry(table, key string) (bool, error) {
+	return recordHasExpiry(cs.inner, table, key)
+}
+
+// wrappedSnapshot 阻塞写入，写回全部脏数据后对下层存储做快照，下层存储支持时为一致性快照
+func (cs *CachedStorage) wrappedSnapshot() (Snapshot, bool, error) {
+	cs.flushMu.Lock()
+	defer cs.flushMu.Unlock()
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	for _, table := range cs.cachedTableNames() {
+		tc := cs.tables[table]
+		items := make(map[string]interface{})
+		for key, victim := range tc.evicted {
+			items[key] = victim.data
+		}
+		for key, elem := range tc.entries {
+			if entry := elem.Value.(*cacheEntry); entry.dirty {
+				items[key] = entry.data
+			}
+		}
+		if len(items) == 0 {
+			continue
+		}
+		if err := cs.inner.SaveBatch(table, items); err != nil {
+			return nil, false, err
+		}
+		for key := range items {
+			if elem, ok := tc.entries[key]; ok {
+				elem.Value.(*cacheEntry).dirty = false
+			}
+			delete(tc.evicted, key)
+		}
+	}
+
+	return TakeSnapshot(cs.inner)
+}
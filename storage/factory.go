@@ -8,6 +8,7 @@ import (
 
 var (
 	globalStorage IStorage
+	globalCache   *CachedStorage // 配置了缓存时的缓存层（用于按键刷盘）
 	storageOnce   sync.Once
 )
 
@@ -16,30 +17,46 @@ func InitStorage(storageType StorageType, config map[string]interface{}) error {
 	var err error
 	storageOnce.Do(func() {
 		var storage IStorage
-		
-		switch storageType {
-		case StorageTypeTXT:
-			storage = NewTxtStorage()
-		case StorageTypeMySQL:
-			storage = NewMySQLStorage()
-		case StorageTypeRedis:
-			storage = NewRedisStorage()
-		default:
-			err = fmt.Errorf("不支持的存储类型: %s", storageType)
+		storage, err = NewStorage(storageType, config)
+		if err != nil {
 			return
 		}
 		
-		if err = storage.Init(config); err != nil {
-			return
+		// 按配置在下层存储之上加缓存层
+		if cacheConfig := ParseCacheConfig(config); cacheConfig != nil {
+			globalCache = NewCachedStorage(storage, *cacheConfig)
+			storage = globalCache
 		}
 		
-		globalStorage = storage
+		// 业务读写经过结构版本装饰器，旧数据在读取时按需升级
+		globalStorage = NewSchemaStorage(storage)
 		utils.Info("存储层初始化完成，类型: %s", storageType)
 	})
 	
 	return err
 }
 
+// NewStorage 创建并初始化一个独立的存储实例（不影响全局存储，用于迁移、备份等工具）
+func NewStorage(storageType StorageType, config map[string]interface{}) (IStorage, error) {
+	var storage IStorage
+	
+	switch storageType {
+	case StorageTypeTXT:
+		storage = NewTxtStorage()
+	case StorageTypeMySQL:
+		storage = NewMySQLStorage()
+	case StorageTypeRedis:
+		storage = NewRedisStorage()
+	default:
+		return nil, fmt.Errorf("不支持的存储类型: %s", storageType)
+	}
+	
+	if err := storage.Init(config); err != nil {
+		return nil, err
+	}
+	return storage, nil
+}
+
 // GetStorage 获取全局存储实例
 func GetStorage() IStorage {
 	if globalStorage == nil {
@@ -51,7 +68,15 @@ func GetStorage() IStorage {
 	return globalStorage
 }
 
-// CloseStorage 关闭存储
+// FlushKey 将缓存中的单条脏数据立即写回（未启用缓存时无操作）
+func FlushKey(table string, key string) error {
+	if globalCache == nil {
+		return nil
+	}
+	return globalCache.FlushKey(table, key)
+}
+
+// CloseStorage 关闭存储（启用缓存时会先写回全部脏数据）
 func CloseStorage() error {
 	if globalStorage != nil {
 		return globalStorage.Close()
@@ -1,5 +1,20 @@
 package storage
 
+import (
+	"errors"
+	"time"
+)
+
+// ErrVersionConflict 乐观锁版本冲突（记录已被其他写入者修改）
+var ErrVersionConflict = errors.New("数据版本冲突")
+
+// Versioned 带版本号的记录
+// 每次成功的 CompareAndSave 都会让版本号 +1，版本号 0 表示记录尚未写入
+type Versioned interface {
+	GetVersion() int64
+	SetVersion(version int64)
+}
+
 // IStorage 统一存储接口
 // 支持 TXT、MySQL、Redis 等多种存储方式
 type IStorage interface {
@@ -10,8 +25,14 @@ type IStorage interface {
 	Close() error
 	
 	// 保存数据（通用）
+	// 覆盖写入会清除记录原有的过期时间
 	Save(table string, key string, data interface{}) error
 	
+	// 保存数据并设置过期时间
+	// 过期后记录对 Get/Exists/GetAll/Query/Keys 不可见，并由存储在后台删除
+	// 用于登录令牌、重连保护、频率限制等临时数据
+	SaveWithTTL(table string, key string, data interface{}, ttl time.Duration) error
+	
 	// 获取数据（通用）
 	Get(table string, key string, result interface{}) error
 	
@@ -29,6 +50,32 @@ type IStorage interface {
 	
 	// 检查是否存在
 	Exists(table string, key string) (bool, error)
+	
+	// 列出所有表
+	Tables() ([]string, error)
+	
+	// 列出表中所有键（按字典序）
+	Keys(table string) ([]string, error)
+	
+	// 比较并保存（乐观锁）
+	// 仅当存储中的版本号等于 expectedVersion 时写入，写入后版本号为 expectedVersion+1
+	// 记录不存在（或为未带版本号的旧数据）时视为版本 0，因此 expectedVersion 为 0 可用于"仅在不存在时创建"
+	// 版本不匹配返回 ErrVersionConflict
+	CompareAndSave(table string, key string, expectedVersion int64, data Versioned) error
+}
+
+// TxOp 事务中的单条写操作
+type TxOp struct {
+	Table           string
+	Key             string
+	ExpectedVersion int64
+	Data            Versioned
+}
+
+// ITransactional 支持多键事务的存储（可选能力）
+// 所有操作的版本校验通过后才会写入，任一冲突则全部不写入
+type ITransactional interface {
+	CompareAndSaveMulti(ops []TxOp) error
 }
 
 // StorageType 存储类型
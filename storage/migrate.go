@@ -0,0 +1,291 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"towerdefense/utils"
+)
+
+// MigrateOptions 迁移选项
+type MigrateOptions struct {
+	Tables         []string // 要迁移的表，为空表示全部
+	BatchSize      int      // 每批写入条数
+	DryRun         bool     // 只统计不写入
+	CheckpointFile string   // 断点文件，为空则不支持断点续传
+	Source         string   // 迁移源标识（见 StorageFingerprint），记录在断点文件中
+	Target         string   // 迁移目标标识，与断点文件中记录的源或目标不一致时拒绝续传
+	Verify         bool     // 迁移后校验条数与校验和
+	// Progress 进度回调（每批写入后调用）
+	Progress func(table string, done, total int)
+}
+
+// TableMigrateResult 单表迁移结果
+type TableMigrateResult struct {
+	Table          string `json:"table"`
+	SourceCount    int    `json:"source_count"`
+	Copied         int    `json:"copied"`
+	Skipped        int    `json:"skipped"`  // 断点续传跳过的条数
+	Excluded       int    `json:"excluded"` // 带过期时间的临时数据，不迁移
+	SourceChecksum string `json:"source_checksum,omitempty"`
+	TargetCount    int    `json:"target_count,omitempty"`
+	TargetChecksum string `json:"target_checksum,omitempty"`
+	Verified       bool   `json:"verified"`
+}
+
+// migrateCheckpoint 迁移断点，只对记录的源和目标有效
+type migrateCheckpoint struct {
+	Source string                      `json:"source"`
+	Target string                      `json:"target"`
+	Tables map[string]*tableCheckpoint `json:"tables"`
+}
+
+type tableCheckpoint struct {
+	LastKey string `json:"last_key"` // 已写入的最后一个键（键按字典序迁移）
+	Done    bool   `json:"done"`     // 已写入全部数据并通过校验（未要求校验时为已写入全部数据）
+}
+
+// StorageFingerprint 存储的标识：存储类型加配置的摘要（不含明文配置，避免密码写入断点文件）
+func StorageFingerprint(storageType StorageType, settings map[string]interface{}) string {
+	canonical, _ := json.Marshal(settings)
+	sum := sha256.Sum256(canonical)
+	return fmt.Sprintf("%s:%s", storageType, hex.EncodeToString(sum[:8]))
+}
+
+// Migrate 将 src 中的数据逐表流式复制到 dst，带过期时间的临时数据（会话等）不迁移
+// 键按字典序分批读取和写入，每批写入后记录断点，中断后重新执行会从断点继续；
+// 表在校验通过后才标记为完成，校验不一致时清除该表的断点，重新执行会整表重新复制
+func Migrate(src, dst IStorage, opts MigrateOptions) ([]*TableMigrateResult, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+
+	tables := opts.Tables
+	if len(tables) == 0 {
+		var err error
+		tables, err = src.Tables()
+		if err != nil {
+			return nil, fmt.Errorf("读取源表列表失败: %v", err)
+		}
+	}
+
+	checkpoint, err := loadCheckpoint(opts.CheckpointFile, opts.Source, opts.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*TableMigrateResult, 0, len(tables))
+	for _, table := range tables {
+		result, err := migrateTable(src, dst, table, opts, checkpoint)
+		if err != nil {
+			return results, fmt.Errorf("迁移表 %s 失败: %v", table, err)
+		}
+		results = append(results, result)
+		if opts.DryRun {
+			continue
+		}
+
+		cp := checkpoint.Tables[table]
+		if opts.Verify {
+			if err := verifyTable(src, dst, result); err != nil {
+				return results, fmt.Errorf("校验表 %s 失败: %v", table, err)
+			}
+			if !result.Verified {
+				// 目标中的数据与源不一致，断点之前的数据也不可信
+				*cp = tableCheckpoint{}
+				if err := saveCheckpoint(opts.CheckpointFile, checkpoint); err != nil {
+					return results, err
+				}
+				continue
+			}
+		}
+		if !cp.Done {
+			cp.Done = true
+			if err := saveCheckpoint(opts.CheckpointFile, checkpoint); err != nil {
+				return results, err
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// migrateTable 迁移单表（完成标记由 Migrate 在校验后设置）
+func migrateTable(src, dst IStorage, table string, opts MigrateOptions, checkpoint *migrateCheckpoint) (*TableMigrateResult, error) {
+	keys, err := src.Keys(table)
+	if err != nil {
+		return nil, fmt.Errorf("读取键列表失败: %v", err)
+	}
+
+	result := &TableMigrateResult{Table: table, SourceCount: len(keys)}
+
+	cp := checkpoint.Tables[table]
+	if cp == nil {
+		cp = &tableCheckpoint{}
+		checkpoint.Tables[table] = cp
+	}
+	if cp.Done && !opts.DryRun {
+		result.Skipped = len(keys)
+		utils.Info("表 %s 已迁移完成，跳过", table)
+		return result, nil
+	}
+
+	batch := make(map[string]interface{}, opts.BatchSize)
+	batchLastKey := ""
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if !opts.DryRun {
+			if err := dst.SaveBatch(table, batch); err != nil {
+				return err
+			}
+			cp.LastKey = batchLastKey
+			if err := saveCheckpoint(opts.CheckpointFile, checkpoint); err != nil {
+				return err
+			}
+		}
+		result.Copied += len(batch)
+		batch = make(map[string]interface{}, opts.BatchSize)
+		if opts.Progress != nil {
+			opts.Progress(table, result.Copied+result.Skipped+result.Excluded, result.SourceCount)
+		}
+		return nil
+	}
+
+	for _, key := range keys {
+		if !opts.DryRun && cp.LastKey != "" && key <= cp.LastKey {
+			result.Skipped++
+			continue
+		}
+
+		temporary, err := recordHasExpiry(src, table, key)
+		if err != nil {
+			return result, fmt.Errorf("读取 %s/%s 的过期时间失败: %v", table, key, err)
+		}
+		if temporary {
+			result.Excluded++
+			continue
+		}
+
+		var raw json.RawMessage
+		if err := src.Get(table, key, &raw); err != nil {
+			return result, fmt.Errorf("读取 %s/%s 失败: %v", table, key, err)
+		}
+		batch[key] = raw
+		batchLastKey = key
+
+		if len(batch) >= opts.BatchSize {
+			if err := flush(); err != nil {
+				return result, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// verifyTable 校验源表与目标表的条数和校验和
+func verifyTable(src, dst IStorage, result *TableMigrateResult) error {
+	srcCount, srcSum, err := TableChecksum(src, result.Table)
+	if err != nil {
+		return err
+	}
+	dstCount, dstSum, err := TableChecksum(dst, result.Table)
+	if err != nil {
+		return err
+	}
+
+	result.SourceChecksum = srcSum
+	result.TargetCount = dstCount
+	result.TargetChecksum = dstSum
+	result.Verified = srcCount == dstCount && srcSum == dstSum
+	return nil
+}
+
+// TableChecksum 计算表的条数和校验和（不含带过期时间的临时数据，与迁移和快照的范围一致）
+// 每条记录先规范化（重新序列化，字段按字典序）再参与计算，与存储格式（缩进等）无关
+func TableChecksum(s IStorage, table string) (int, string, error) {
+	keys, err := s.Keys(table)
+	if err != nil {
+		return 0, "", err
+	}
+	sort.Strings(keys)
+
+	count := 0
+	hash := sha256.New()
+	for _, key := range keys {
+		temporary, err := recordHasExpiry(s, table, key)
+		if err != nil {
+			return 0, "", err
+		}
+		if temporary {
+			continue
+		}
+		count++
+
+		var value interface{}
+		if err := s.Get(table, key, &value); err != nil {
+			return 0, "", fmt.Errorf("读取 %s/%s 失败: %v", table, key, err)
+		}
+		canonical, err := json.Marshal(value)
+		if err != nil {
+			return 0, "", err
+		}
+		hash.Write([]byte(key))
+		hash.Write([]byte{0})
+		hash.Write(canonical)
+		hash.Write([]byte{'\n'})
+	}
+
+	return count, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// loadCheckpoint 读取断点文件，断点记录的源或目标与本次迁移不一致时返回错误
+func loadCheckpoint(path, source, target string) (*migrateCheckpoint, error) {
+	checkpoint := &migrateCheckpoint{Source: source, Target: target, Tables: make(map[string]*tableCheckpoint)}
+	if path == "" {
+		return checkpoint, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return checkpoint, nil
+		}
+		return nil, fmt.Errorf("读取断点文件失败: %v", err)
+	}
+	var saved migrateCheckpoint
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("断点文件格式错误: %v", err)
+	}
+	if saved.Source != source || saved.Target != target {
+		return nil, fmt.Errorf("断点文件 %s 属于另一次迁移（%s -> %s），请删除断点文件或使用 -checkpoint 指定其他文件", path, saved.Source, saved.Target)
+	}
+	checkpoint = &saved
+	if checkpoint.Tables == nil {
+		checkpoint.Tables = make(map[string]*tableCheckpoint)
+	}
+	return checkpoint, nil
+}
+
+// saveCheckpoint 写入断点文件
+func saveCheckpoint(path string, checkpoint *migrateCheckpoint) error {
+	if path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(checkpoint, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return fmt.Errorf("写入断点文件失败: %v", err)
+	}
+	return nil
+}
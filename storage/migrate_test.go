@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+// 带过期时间的记录不迁移（写入目标后会变为永久数据），其余记录迁移后校验一致
+func TestMigrateSkipsRecordsWithExpiry(t *testing.T) {
+	src := openTxtStorage(t, t.TempDir())
+	defer src.Close()
+	dst := openTxtStorage(t, t.TempDir())
+	defer dst.Close()
+
+	if err := src.Save("sessions", "keep", journalTestRecord{Name: "permanent"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.SaveWithTTL("sessions", "token", journalTestRecord{Name: "temporary"}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := Migrate(src, dst, MigrateOptions{Tables: []string{"sessions"}, Verify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := results[0]; r.Copied != 1 || r.Excluded != 1 || !r.Verified {
+		t.Fatalf("迁移结果错误: %+v", r)
+	}
+	if exists, _ := dst.Exists("sessions", "token"); exists {
+		t.Fatal("带过期时间的记录不应迁移")
+	}
+	if got := loadName(t, dst, "sessions", "keep"); got != "permanent" {
+		t.Fatalf("keep = %q", got)
+	}
+
+	snapshot, _, err := TakeSnapshot(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := snapshot["sessions"]["token"]; ok {
+		t.Fatal("带过期时间的记录不应进入快照")
+	}
+}
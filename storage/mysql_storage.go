@@ -2,6 +2,7 @@ package storage
 
 import (
 	"fmt"
+	"time"
 	"towerdefense/utils"
 )
 
@@ -61,6 +62,14 @@ func (ms *MySQLStorage) Save(table string, key string, data interface{}) error {
 	return fmt.Errorf("MySQL存储暂未实现")
 }
 
+// SaveWithTTL 保存数据并设置过期时间
+func (ms *MySQLStorage) SaveWithTTL(table string, key string, data interface{}, ttl time.Duration) error {
+	// TODO: 表增加 expire_at 列
+	// INSERT INTO {table} (id, data, expire_at) VALUES (?, ?, NOW() + INTERVAL ? SECOND) ON DUPLICATE KEY UPDATE ...
+	// 读取时附加 WHERE expire_at IS NULL OR expire_at > NOW()，由 EVENT 定时 DELETE 过期行
+	return fmt.Errorf("MySQL存储暂未实现")
+}
+
 // Get 获取数据
 func (ms *MySQLStorage) Get(table string, key string, result interface{}) error {
 	// TODO: 实现 SELECT 逻辑
@@ -102,3 +111,30 @@ func (ms *MySQLStorage) Exists(table string, key string) (bool, error) {
 	// SELECT COUNT(*) FROM {table} WHERE id = ?
 	return false, fmt.Errorf("MySQL存储暂未实现")
 }
+
+// CompareAndSave 比较并保存（乐观锁）
+func (ms *MySQLStorage) CompareAndSave(table string, key string, expectedVersion int64, data Versioned) error {
+	// TODO: 实现乐观锁更新
+	// expectedVersion == 0: INSERT INTO {table} (id, version, data) VALUES (?, 1, ?)，主键冲突视为版本冲突
+	// 否则: UPDATE {table} SET data = ?, version = version + 1 WHERE id = ? AND version = ?
+	// 影响行数为 0 时返回 ErrVersionConflict
+	return fmt.Errorf("MySQL存储暂未实现")
+}
+
+// CompareAndSaveMulti 多键事务写入
+func (ms *MySQLStorage) CompareAndSaveMulti(ops []TxOp) error {
+	// TODO: BEGIN; 对每个操作执行带版本条件的 UPDATE/INSERT; 任一影响行数为 0 则 ROLLBACK 并返回 ErrVersionConflict; COMMIT
+	return fmt.Errorf("MySQL存储暂未实现")
+}
+
+// Tables 列出所有表
+func (ms *MySQLStorage) Tables() ([]string, error) {
+	// TODO: SHOW TABLES
+	return nil, fmt.Errorf("MySQL存储暂未实现")
+}
+
+// Keys 列出表中所有键
+func (ms *MySQLStorage) Keys(table string) ([]string, error) {
+	// TODO: SELECT id FROM {table} ORDER BY id
+	return nil, fmt.Errorf("MySQL存储暂未实现")
+}
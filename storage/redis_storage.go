@@ -2,6 +2,7 @@ package storage
 
 import (
 	"fmt"
+	"time"
 	"towerdefense/utils"
 )
 
@@ -56,6 +57,14 @@ func (rs *RedisStorage) Save(table string, key string, data interface{}) error {
 	return fmt.Errorf("Redis存储暂未实现")
 }
 
+// SaveWithTTL 保存数据并设置过期时间
+func (rs *RedisStorage) SaveWithTTL(table string, key string, data interface{}, ttl time.Duration) error {
+	// TODO: 实现 Redis 原生过期
+	// Redis 7.4+: HSET {table} {key} {json_data} + HPEXPIRE {table} {ttl_ms} FIELDS 1 {key}
+	// 低版本: 临时数据单独存为 SET {table}:{key} {json_data} PX {ttl_ms}
+	return fmt.Errorf("Redis存储暂未实现")
+}
+
 // Get 获取数据
 func (rs *RedisStorage) Get(table string, key string, result interface{}) error {
 	// TODO: 实现 Redis HGET
@@ -96,3 +105,29 @@ func (rs *RedisStorage) Exists(table string, key string) (bool, error) {
 	// HEXISTS {table} {key}
 	return false, fmt.Errorf("Redis存储暂未实现")
 }
+
+// CompareAndSave 比较并保存（乐观锁）
+func (rs *RedisStorage) CompareAndSave(table string, key string, expectedVersion int64, data Versioned) error {
+	// TODO: 实现乐观锁更新
+	// WATCH {table}:{key}:version，校验版本后 MULTI / HSET / INCR / EXEC
+	// EXEC 返回 nil 时返回 ErrVersionConflict
+	return fmt.Errorf("Redis存储暂未实现")
+}
+
+// CompareAndSaveMulti 多键事务写入
+func (rs *RedisStorage) CompareAndSaveMulti(ops []TxOp) error {
+	// TODO: WATCH 所有键的版本号，全部校验通过后在同一个 MULTI/EXEC 中写入
+	return fmt.Errorf("Redis存储暂未实现")
+}
+
+// Tables 列出所有表
+func (rs *RedisStorage) Tables() ([]string, error) {
+	// TODO: SCAN 0 TYPE hash，每个 hash 对应一张表
+	return nil, fmt.Errorf("Redis存储暂未实现")
+}
+
+// Keys 列出表中所有键
+func (rs *RedisStorage) Keys(table string) ([]string, error) {
+	// TODO: HKEYS {table}，排序后返回
+	return nil, fmt.Errorf("Redis存储暂未实现")
+}
@@ -0,0 +1,437 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+	"towerdefense/utils"
+)
+
+// ErrSchemaTooNew 数据的结构版本高于当前代码支持的版本（数据由更新版本的服务写入）
+var ErrSchemaTooNew = errors.New("数据结构版本高于当前代码版本")
+
+// TableSchemaMeta 记录每张表已写入数据的最高结构版本
+const TableSchemaMeta = "schema_meta"
+
+// SchemaMigration 结构迁移函数：把一条记录从 fromVersion 升级到 fromVersion+1
+// record 为记录的 JSON 对象，直接在原 map 上修改
+type SchemaMigration func(record map[string]interface{}) error
+
+// SchemaVersioned 带结构版本号的记录
+type SchemaVersioned interface {
+	GetSchemaVersion() int
+	SetSchemaVersion(version int)
+}
+
+// SchemaRecord 可直接嵌入数据模型的结构版本号字段
+type SchemaRecord struct {
+	SchemaVersion int `json:"schema_version"`
+}
+
+// GetSchemaVersion 获取结构版本号
+func (sr *SchemaRecord) GetSchemaVersion() int {
+	return sr.SchemaVersion
+}
+
+// SetSchemaVersion 设置结构版本号
+func (sr *SchemaRecord) SetSchemaVersion(version int) {
+	sr.SchemaVersion = version
+}
+
+// schemaMetaData 表结构版本元数据
+type schemaMetaData struct {
+	VersionedRecord
+	Table         string    `json:"table"`
+	SchemaVersion int       `json:"schema_version"`
+	UpdateTime    time.Time `json:"update_time"`
+}
+
+// tableSchema 单张表的结构定义
+type tableSchema struct {
+	current    int
+	migrations map[int]SchemaMigration // fromVersion -> 迁移函数
+}
+
+var (
+	schemaRegistry = make(map[string]*tableSchema)
+	schemaMu       sync.RWMutex
+)
+
+// RegisterSchema 注册表的当前结构版本（由各仓储在 init 中调用）
+// 旧数据没有 schema_version 字段时视为版本 0
+func RegisterSchema(table string, currentVersion int) {
+	schemaMu.Lock()
+	defer schemaMu.Unlock()
+
+	schema := getOrCreateSchemaLocked(table)
+	schema.current = currentVersion
+}
+
+// RegisterMigration 注册从 fromVersion 升级到 fromVersion+1 的迁移函数
+func RegisterMigration(table string, fromVersion int, migration SchemaMigration) {
+	schemaMu.Lock()
+	defer schemaMu.Unlock()
+
+	schema := getOrCreateSchemaLocked(table)
+	if _, exists := schema.migrations[fromVersion]; exists {
+		panic(fmt.Sprintf("重复注册结构迁移: %s v%d", table, fromVersion))
+	}
+	schema.migrations[fromVersion] = migration
+}
+
+func getOrCreateSchemaLocked(table string) *tableSchema {
+	schema, ok := schemaRegistry[table]
+	if !ok {
+		schema = &tableSchema{migrations: make(map[int]SchemaMigration)}
+		schemaRegistry[table] = schema
+	}
+	return schema
+}
+
+// CurrentSchemaVersion 获取表的当前结构版本，未注册的表返回 0
+func CurrentSchemaVersion(table string) int {
+	schemaMu.RLock()
+	defer schemaMu.RUnlock()
+
+	if schema, ok := schemaRegistry[table]; ok {
+		return schema.current
+	}
+	return 0
+}
+
+// SchemaTables 获取所有注册了结构版本的表
+func SchemaTables() []string {
+	schemaMu.RLock()
+	defer schemaMu.RUnlock()
+
+	tables := make([]string, 0, len(schemaRegistry))
+	for table := range schemaRegistry {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	return tables
+}
+
+// recordSchemaVersion 读取记录中的结构版本号
+func recordSchemaVersion(record map[string]interface{}) int {
+	if v, ok := record["schema_version"].(float64); ok {
+		return int(v)
+	}
+	return 0
+}
+
+// MigrateRecord 将一条记录按注册的迁移函数依次升级到当前版本
+// 返回记录是否发生了变化
+func MigrateRecord(table string, record map[string]interface{}) (bool, error) {
+	schemaMu.RLock()
+	schema, ok := schemaRegistry[table]
+	schemaMu.RUnlock()
+	if !ok {
+		return false, nil
+	}
+
+	version := recordSchemaVersion(record)
+	if version > schema.current {
+		return false, fmt.Errorf("%w: %s 数据版本 %d，代码版本 %d", ErrSchemaTooNew, table, version, schema.current)
+	}
+	if version == schema.current {
+		return false, nil
+	}
+
+	for v := version; v < schema.current; v++ {
+		migration, ok := schema.migrations[v]
+		if ok {
+			if err := migration(record); err != nil {
+				return false, fmt.Errorf("结构迁移 %s v%d->v%d 失败: %v", table, v, v+1, err)
+			}
+		}
+		record["schema_version"] = v + 1
+	}
+	return true, nil
+}
+
+// SchemaStorage 结构版本装饰器
+// 读取时按需（惰性）升级旧数据，写入时为记录打上当前结构版本
+type SchemaStorage struct {
+	IStorage
+	metaWritten map[string]bool // 本进程已更新过元数据的表
+	mu          sync.Mutex
+}
+
+// NewSchemaStorage 创建结构版本装饰器
+func NewSchemaStorage(inner IStorage) *SchemaStorage {
+	return &SchemaStorage{
+		IStorage:    inner,
+		metaWritten: make(map[string]bool),
+	}
+}
+
+// Get 获取数据（自动升级旧结构）
+func (ss *SchemaStorage) Get(table string, key string, result interface{}) error {
+	if CurrentSchemaVersion(table) == 0 {
+		return ss.IStorage.Get(table, key, result)
+	}
+
+	var record map[string]interface{}
+	if err := ss.IStorage.Get(table, key, &record); err != nil {
+		return err
+	}
+	if _, err := MigrateRecord(table, record); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("JSON序列化失败: %v", err)
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		return fmt.Errorf("JSON反序列化失败: %v", err)
+	}
+	return nil
+}
+
+// GetAll 获取所有数据（自动升级旧结构）
+func (ss *SchemaStorage) GetAll(table string) ([]interface{}, error) {
+	results, err := ss.IStorage.GetAll(table)
+	if err != nil {
+		return nil, err
+	}
+	return ss.migrateResults(table, results)
+}
+
+// Query 条件查询（自动升级旧结构）
+func (ss *SchemaStorage) Query(table string, condition map[string]interface{}) ([]interface{}, error) {
+	results, err := ss.IStorage.Query(table, condition)
+	if err != nil {
+		return nil, err
+	}
+	return ss.migrateResults(table, results)
+}
+
+func (ss *SchemaStorage) migrateResults(table string, results []interface{}) ([]interface{}, error) {
+	if CurrentSchemaVersion(table) == 0 {
+		return results, nil
+	}
+	for _, item := range results {
+		if record, ok := item.(map[string]interface{}); ok {
+			if _, err := MigrateRecord(table, record); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return results, nil
+}
+
+// Save 保存数据
+func (ss *SchemaStorage) Save(table string, key string, data interface{}) error {
+	if err := ss.stamp(table, data); err != nil {
+		return err
+	}
+	return ss.IStorage.Save(table, key, data)
+}
+
+// SaveWithTTL 保存数据并设置过期时间
+func (ss *SchemaStorage) SaveWithTTL(table string, key string, data interface{}, ttl time.Duration) error {
+	if err := ss.stamp(table, data); err != nil {
+		return err
+	}
+	return ss.IStorage.SaveWithTTL(table, key, data, ttl)
+}
+
+// SaveBatch 批量保存
+func (ss *SchemaStorage) SaveBatch(table string, items map[string]interface{}) error {
+	for _, data := range items {
+		if err := ss.stamp(table, data); err != nil {
+			return err
+		}
+	}
+	return ss.IStorage.SaveBatch(table, items)
+}
+
+// CompareAndSave 比较并保存
+func (ss *SchemaStorage) CompareAndSave(table string, key string, expectedVersion int64, data Versioned) error {
+	if err := ss.stamp(table, data); err != nil {
+		return err
+	}
+	return ss.IStorage.CompareAndSave(table, key, expectedVersion, data)
+}
+
+// CompareAndSaveMulti 多键事务写入
+func (ss *SchemaStorage) CompareAndSaveMulti(ops []TxOp) error {
+	for _, op := range ops {
+		if err := ss.stamp(op.Table, op.Data); err != nil {
+			return err
+		}
+	}
+	return CompareAndSaveMulti(ss.IStorage, ops)
+}
+
+// stamp 为记录打上当前结构版本，并在首次写入时更新表的元数据
+func (ss *SchemaStorage) stamp(table string, data interface{}) error {
+	current := CurrentSchemaVersion(table)
+	if current == 0 {
+		return nil
+	}
+	record, ok := data.(SchemaVersioned)
+	if !ok {
+		return nil
+	}
+	record.SetSchemaVersion(current)
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if ss.metaWritten[table] {
+		return nil
+	}
+	if err := raiseSchemaMeta(ss.IStorage, table, current); err != nil {
+		return err
+	}
+	ss.metaWritten[table] = true
+	return nil
+}
+
+// raiseSchemaMeta 将表的元数据版本提升到 version（只升不降）
+func raiseSchemaMeta(s IStorage, table string, version int) error {
+	for i := 0; i < MaxUpdateRetries; i++ {
+		var meta schemaMetaData
+		if err := s.Get(TableSchemaMeta, table, &meta); err != nil {
+			exists, existsErr := s.Exists(TableSchemaMeta, table)
+			if existsErr != nil || exists {
+				return fmt.Errorf("读取结构元数据失败: %v", err)
+			}
+			meta = schemaMetaData{Table: table}
+		}
+
+		if meta.SchemaVersion > version {
+			return fmt.Errorf("%w: %s 数据版本 %d，代码版本 %d", ErrSchemaTooNew, table, meta.SchemaVersion, version)
+		}
+		if meta.SchemaVersion == version {
+			return nil
+		}
+
+		expected := meta.GetVersion()
+		meta.SchemaVersion = version
+		meta.UpdateTime = time.Now()
+		err := s.CompareAndSave(TableSchemaMeta, table, expected, &meta)
+		if !errors.Is(err, ErrVersionConflict) {
+			return err
+		}
+	}
+	return ErrVersionConflict
+}
+
+// CheckSchemaCompatibility 启动检查：存储中任一表的结构版本高于代码版本时拒绝启动
+func CheckSchemaCompatibility(s IStorage) error {
+	for _, table := range SchemaTables() {
+		var meta schemaMetaData
+		if err := s.Get(TableSchemaMeta, table, &meta); err != nil {
+			continue // 尚未写入过元数据
+		}
+		current := CurrentSchemaVersion(table)
+		if meta.SchemaVersion > current {
+			return fmt.Errorf("%w: 表 %s 数据版本 %d，代码版本 %d，请升级服务后再启动", ErrSchemaTooNew, table, meta.SchemaVersion, current)
+		}
+	}
+	return nil
+}
+
+// mapRecord 以 JSON 对象形式读写的记录（用于批量迁移时保留未知字段）
+type mapRecord map[string]interface{}
+
+// GetVersion 获取版本号
+func (mr mapRecord) GetVersion() int64 {
+	if v, ok := mr["version"].(float64); ok {
+		return int64(v)
+	}
+	return 0
+}
+
+// SetVersion 设置版本号
+func (mr mapRecord) SetVersion(version int64) {
+	mr["version"] = version
+}
+
+// SchemaMigrateResult 单表批量迁移结果
+type SchemaMigrateResult struct {
+	Table    string
+	Version  int
+	Total    int
+	Migrated int
+}
+
+// MigrateAllSchemas 批量（立即）升级所有注册表中的旧数据，并更新表的元数据版本
+// s 应为未经 SchemaStorage 装饰的原始存储，且没有服务进程同时读写（乐观锁只在同一进程内生效，服务的读缓存也看不到迁移写入的数据）
+func MigrateAllSchemas(s IStorage, dryRun bool) ([]*SchemaMigrateResult, error) {
+	results := make([]*SchemaMigrateResult, 0)
+	for _, table := range SchemaTables() {
+		if table == TableSchemaMeta {
+			continue
+		}
+
+		keys, err := s.Keys(table)
+		if err != nil {
+			return results, fmt.Errorf("读取表 %s 键列表失败: %v", table, err)
+		}
+
+		result := &SchemaMigrateResult{Table: table, Version: CurrentSchemaVersion(table), Total: len(keys)}
+		for _, key := range keys {
+			migrated, err := migrateStoredRecord(s, table, key, dryRun)
+			if err != nil {
+				return results, err
+			}
+			if migrated {
+				result.Migrated++
+			}
+		}
+
+		if !dryRun {
+			if err := raiseSchemaMeta(s, table, result.Version); err != nil {
+				return results, err
+			}
+		}
+		results = append(results, result)
+		utils.Info("结构迁移 %s: 共 %d 条，升级 %d 条，当前版本 v%d", table, result.Total, result.Migrated, result.Version)
+	}
+	return results, nil
+}
+
+// migrateStoredRecord 升级并写回一条记录
+func migrateStoredRecord(s IStorage, table, key string, dryRun bool) (bool, error) {
+	for i := 0; i < MaxUpdateRetries; i++ {
+		record := make(mapRecord)
+		if err := s.Get(table, key, &record); err != nil {
+			return false, fmt.Errorf("读取 %s/%s 失败: %v", table, key, err)
+		}
+
+		expected := record.GetVersion()
+		migrated, err := MigrateRecord(table, record)
+		if err != nil {
+			return false, fmt.Errorf("%s/%s: %v", table, key, err)
+		}
+		if !migrated || dryRun {
+			return migrated, nil
+		}
+
+		err = s.CompareAndSave(table, key, expected, record)
+		if err == nil {
+			return true, nil
+		}
+		if !errors.Is(err, ErrVersionConflict) {
+			return false, err
+		}
+	}
+	return false, fmt.Errorf("%w: %s/%s", ErrVersionConflict, table, key)
+}
+
+// HasExpiry 记录是否设置了过期时间，由下层存储判断
+func (ss *SchemaStorage) HasExpiry(table, key string) (bool, error) {
+	return recordHasExpiry(ss.IStorage, table, key)
+}
+
+// wrappedSnapshot 对下层存储做快照，下层存储支持时为一致性快照
+func (ss *SchemaStorage) wrappedSnapshot() (Snapshot, bool, error) {
+	return TakeSnapshot(ss.IStorage)
+}
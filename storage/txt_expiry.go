@@ -0,0 +1,214 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"towerdefense/utils"
+)
+
+const (
+	expiryFileSuffix    = ".ttl"         // 过期时间文件后缀：<table>/<key>.ttl 与数据文件 <key>.json 同目录
+	legacyExpiryFile    = "_expiry.json" // 旧版集中式过期索引，启动时迁移为过期时间文件
+	defaultReapInterval = 60             // 默认过期清理间隔（秒）
+)
+
+// expiryRecord 过期时间文件内容
+type expiryRecord struct {
+	ExpireAt int64 `json:"expire_at"` // 过期时间（毫秒时间戳）
+}
+
+// expiryPath 记录的过期时间文件路径
+// 过期时间跟随记录保存在磁盘上，不在进程内缓存：多个进程共享数据目录时，
+// 任一进程续期后其他进程读取和清理时都能看到最新的过期时间
+func (ts *TxtStorage) expiryPath(table, key string) string {
+	return filepath.Join(ts.getTablePath(table), key+expiryFileSuffix)
+}
+
+// migrateLegacyExpiry 将旧版 _expiry.json 中的过期时间迁移为每条记录的过期时间文件
+// 已存在过期时间文件的记录以文件为准
+func (ts *TxtStorage) migrateLegacyExpiry() error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	legacyPath := filepath.Join(ts.dataDir, legacyExpiryFile)
+	data, err := ioutil.ReadFile(legacyPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("读取过期索引失败: %v", err)
+	}
+
+	var expiries map[string]map[string]int64
+	if err := json.Unmarshal(data, &expiries); err != nil {
+		// 索引损坏时丢弃（对应记录变为永久数据，不影响正确性）
+		utils.Error("过期索引损坏，已忽略: %v", err)
+		expiries = nil
+	}
+	for table, keys := range expiries {
+		for key, expireAt := range keys {
+			if _, ok := ts.readExpiryLocked(table, key); ok {
+				continue
+			}
+			if _, err := os.Stat(ts.getFilePath(table, key)); err != nil {
+				continue
+			}
+			if err := ts.writeExpiryLocked(table, key, expireAt); err != nil {
+				return err
+			}
+		}
+	}
+	return os.Remove(legacyPath)
+}
+
+// readExpiryLocked 从磁盘读取记录的过期时间，未设置时返回 false
+func (ts *TxtStorage) readExpiryLocked(table, key string) (int64, bool) {
+	data, err := ioutil.ReadFile(ts.expiryPath(table, key))
+	if err != nil {
+		return 0, false
+	}
+	var record expiryRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		// 过期时间文件损坏时视为未设置（记录变为永久数据，不影响正确性）
+		return 0, false
+	}
+	return record.ExpireAt, true
+}
+
+// writeExpiryLocked 原子写入记录的过期时间文件
+func (ts *TxtStorage) writeExpiryLocked(table, key string, expireAt int64) error {
+	if err := ts.ensureTableDir(table); err != nil {
+		return err
+	}
+	data, err := json.Marshal(expiryRecord{ExpireAt: expireAt})
+	if err != nil {
+		return fmt.Errorf("过期时间序列化失败: %v", err)
+	}
+	if err := writeFileAtomic(ts.expiryPath(table, key), data); err != nil {
+		return fmt.Errorf("写入过期时间失败: %v", err)
+	}
+	return nil
+}
+
+// setExpiryLocked 设置记录过期时间
+func (ts *TxtStorage) setExpiryLocked(table, key string, expireAt time.Time) error {
+	return ts.writeExpiryLocked(table, key, expireAt.UnixMilli())
+}
+
+// clearExpiryLocked 清除记录过期时间（普通写入和删除后记录不再过期）
+func (ts *TxtStorage) clearExpiryLocked(table, key string) error {
+	if err := os.Remove(ts.expiryPath(table, key)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("删除过期时间失败: %v", err)
+	}
+	return nil
+}
+
+// hasExpiryLocked 记录是否设置了过期时间
+func (ts *TxtStorage) hasExpiryLocked(table, key string) bool {
+	_, ok := ts.readExpiryLocked(table, key)
+	return ok
+}
+
+// HasExpiry 记录是否设置了过期时间（实现 IExpiryReporter）
+func (ts *TxtStorage) HasExpiry(table, key string) (bool, error) {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	return ts.hasExpiryLocked(table, key), nil
+}
+
+// isExpiredLocked 记录是否已过期（过期但尚未被清理的记录对外不可见）
+func (ts *TxtStorage) isExpiredLocked(table, key string) bool {
+	expireAt, ok := ts.readExpiryLocked(table, key)
+	return ok && time.Now().UnixMilli() >= expireAt
+}
+
+// SaveWithTTL 保存数据并设置过期时间
+// 先写过期时间再写数据文件：中途崩溃最多让旧记录带上过期时间，不会产生永不过期的临时数据
+func (ts *TxtStorage) SaveWithTTL(table string, key string, data interface{}, ttl time.Duration) error {
+	if ttl <= 0 {
+		return fmt.Errorf("无效的过期时间: %v", ttl)
+	}
+
+	jsonData, err := marshalRecord(data)
+	if err != nil {
+		return err
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if err := ts.setExpiryLocked(table, key, time.Now().Add(ttl)); err != nil {
+		return err
+	}
+	return ts.writeRawLocked(table, key, jsonData)
+}
+
+// reapLoop 后台定时清理过期记录
+func (ts *TxtStorage) reapLoop(interval time.Duration) {
+	defer ts.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ts.reapExpired()
+		case <-ts.stopChan:
+			return
+		}
+	}
+}
+
+// reapExpired 删除已过期的记录
+// 删除前从磁盘重新读取过期时间，其他进程刚续期的记录不会被删除
+func (ts *TxtStorage) reapExpired() {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	tables, err := ioutil.ReadDir(ts.dataDir)
+	if err != nil {
+		utils.Warn("清理过期记录时读取数据目录失败: %v", err)
+		return
+	}
+
+	now := time.Now().UnixMilli()
+	reaped := 0
+	for _, table := range tables {
+		if !table.IsDir() || isReservedName(table.Name()) {
+			continue
+		}
+		files, err := ioutil.ReadDir(ts.getTablePath(table.Name()))
+		if err != nil {
+			continue
+		}
+		for _, file := range files {
+			name := file.Name()
+			if file.IsDir() || strings.HasPrefix(name, ".") || filepath.Ext(name) != expiryFileSuffix {
+				continue
+			}
+			key := strings.TrimSuffix(name, expiryFileSuffix)
+			expireAt, ok := ts.readExpiryLocked(table.Name(), key)
+			if !ok || now < expireAt {
+				continue
+			}
+			if err := os.Remove(ts.getFilePath(table.Name(), key)); err != nil && !os.IsNotExist(err) {
+				utils.Warn("删除过期记录失败: %s/%s, %v", table.Name(), key, err)
+				continue
+			}
+			if err := ts.clearExpiryLocked(table.Name(), key); err != nil {
+				utils.Warn("删除过期时间失败: %s/%s, %v", table.Name(), key, err)
+			}
+			reaped++
+		}
+	}
+
+	if reaped > 0 {
+		utils.Info("TXT存储清理过期记录: %d 条", reaped)
+	}
+}
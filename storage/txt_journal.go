@@ -0,0 +1,371 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"towerdefense/utils"
+
+	"github.com/google/uuid"
+)
+
+const (
+	journalFilePrefix  = "_journal"    // 预写日志文件前缀（每个进程一个日志文件：_journal-<实例ID>.log）
+	quarantineDirName  = "_quarantine" // 损坏文件隔离目录
+	journalCompactSize = 4 * 1024 * 1024
+
+	journalOpBegin  = "begin"
+	journalOpCommit = "commit"
+	journalOpAbort  = "abort"
+)
+
+// 完整性检查模式
+const (
+	IntegrityCheckOff        = "off"        // 不检查
+	IntegrityCheckReport     = "report"     // 只报告损坏文件
+	IntegrityCheckQuarantine = "quarantine" // 将损坏文件移入隔离目录
+)
+
+// journalWrite 日志中的单条写入
+type journalWrite struct {
+	Table   string          `json:"table"`
+	Key     string          `json:"key"`
+	Data    json.RawMessage `json:"data"`
+	Prev    json.RawMessage `json:"prev,omitempty"` // 写入前的内容，写入失败时用于回滚
+	Existed bool            `json:"existed"`        // 写入前记录是否存在，不存在时回滚即删除
+}
+
+// journalEntry 日志记录（一行一条）
+type journalEntry struct {
+	TxID   string         `json:"tx_id"`
+	Op     string         `json:"op"`
+	Writes []journalWrite `json:"writes,omitempty"`
+}
+
+// isReservedName 是否为存储内部使用的文件/目录（不是数据表）
+func isReservedName(name string) bool {
+	return strings.HasPrefix(name, "_") || strings.HasPrefix(name, ".")
+}
+
+// writeFileAtomic 原子写文件：写临时文件 -> fsync -> rename -> fsync 目录
+// 任意时刻崩溃，目标文件要么是旧内容，要么是完整的新内容
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	return syncDir(dir)
+}
+
+// syncDir 刷新目录项，保证 rename 持久化（部分平台不支持对目录 fsync，忽略该错误）
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	d.Sync()
+	return nil
+}
+
+// journalPath 本进程的日志文件路径
+// 多个进程共用数据目录时各写各的日志，清空日志不会影响其他进程未提交的批次
+func (ts *TxtStorage) journalPath() string {
+	return filepath.Join(ts.dataDir, ts.journalName)
+}
+
+// appendJournalLocked 追加日志并 fsync（调用时已持有写锁）
+func (ts *TxtStorage) appendJournalLocked(entry *journalEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("日志序列化失败: %v", err)
+	}
+
+	f, err := os.OpenFile(ts.journalPath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("打开日志文件失败: %v", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("写入日志失败: %v", err)
+	}
+	return f.Sync()
+}
+
+// writeBatchLocked 以日志保护的方式写入多条记录（调用时已持有写锁）
+// 先把全部新数据和原内容写入日志，再逐条原子写入数据文件，最后追加提交记录
+// 中途崩溃时，启动恢复会根据日志重放未提交的批次；
+// 写入失败时恢复原内容并追加回滚记录，启动恢复会跳过已回滚的批次
+func (ts *TxtStorage) writeBatchLocked(writes []journalWrite) error {
+	if err := ts.capturePrevLocked(writes); err != nil {
+		return err
+	}
+
+	txID := uuid.New().String()
+	if err := ts.appendJournalLocked(&journalEntry{TxID: txID, Op: journalOpBegin, Writes: writes}); err != nil {
+		return err
+	}
+
+	if err := ts.applyWritesLocked(writes); err != nil {
+		if rbErr := ts.rollbackWritesLocked(writes); rbErr != nil {
+			// 无法恢复原内容时保留未提交的批次，下次启动时整体重放，保证批次不会只写入一部分
+			// 在此之前不能清空日志
+			ts.pendingTx[txID] = true
+			utils.Error("批次 %s 回滚失败，保留在日志中等待下次启动重放", txID)
+			return fmt.Errorf("%v（回滚失败: %v）", err, rbErr)
+		}
+		if abortErr := ts.appendJournalLocked(&journalEntry{TxID: txID, Op: journalOpAbort}); abortErr != nil {
+			utils.Error("写入回滚记录失败: %s, %v", txID, abortErr)
+		}
+		return err
+	}
+
+	if err := ts.appendJournalLocked(&journalEntry{TxID: txID, Op: journalOpCommit}); err != nil {
+		return err
+	}
+
+	ts.compactJournalLocked()
+	return nil
+}
+
+// applyWritesLocked 写入数据文件
+func (ts *TxtStorage) applyWritesLocked(writes []journalWrite) error {
+	for _, w := range writes {
+		if err := ts.writeRawLocked(w.Table, w.Key, w.Data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// capturePrevLocked 读取每条写入的原内容，记录在日志中用于回滚
+func (ts *TxtStorage) capturePrevLocked(writes []journalWrite) error {
+	for i := range writes {
+		prev, err := ioutil.ReadFile(ts.getFilePath(writes[i].Table, writes[i].Key))
+		switch {
+		case os.IsNotExist(err):
+			writes[i].Existed = false
+		case err != nil:
+			return fmt.Errorf("读取文件失败: %v", err)
+		default:
+			writes[i].Existed = true
+			writes[i].Prev = prev
+		}
+	}
+	return nil
+}
+
+// rollbackWritesLocked 恢复写入前的内容，原来不存在的记录直接删除
+func (ts *TxtStorage) rollbackWritesLocked(writes []journalWrite) error {
+	for _, w := range writes {
+		if !w.Existed {
+			if err := os.Remove(ts.getFilePath(w.Table, w.Key)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		}
+		if err := ts.writeRawLocked(w.Table, w.Key, w.Prev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compactJournalLocked 日志超过大小上限时清空本进程的日志
+// 有回滚失败、等待重放的批次时不清空
+func (ts *TxtStorage) compactJournalLocked() {
+	if len(ts.pendingTx) > 0 {
+		return
+	}
+	info, err := os.Stat(ts.journalPath())
+	if err != nil {
+		return
+	}
+	if info.Size() < journalCompactSize {
+		return
+	}
+	if err := os.Truncate(ts.journalPath(), 0); err != nil {
+		utils.Warn("清空日志失败: %v", err)
+	}
+}
+
+// recoverJournal 启动时根据数据目录中遗留的全部日志恢复，恢复后删除日志文件
+// 只在独占数据目录时调用，此时日志的所属进程都已退出
+func (ts *TxtStorage) recoverJournal() error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	paths, err := filepath.Glob(filepath.Join(ts.dataDir, journalFilePrefix+"*.log"))
+	if err != nil {
+		return fmt.Errorf("查找日志文件失败: %v", err)
+	}
+	for _, path := range paths {
+		if err := ts.recoverJournalFileLocked(path); err != nil {
+			return err
+		}
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("删除日志文件失败: %v", err)
+		}
+	}
+	return nil
+}
+
+// recoverJournalFileLocked 根据单个日志文件恢复
+// 有 begin 无 commit 的批次：日志中已有完整新数据，重放写入
+// 有 abort 的批次：运行时写入失败并已恢复原内容，跳过
+// 末尾不完整的行：批次尚未开始写数据文件，直接丢弃（等同回滚）
+func (ts *TxtStorage) recoverJournalFileLocked(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("打开日志文件失败: %v", err)
+	}
+
+	pending := make(map[string]*journalEntry)
+	order := make([]string, 0)
+	torn := 0
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var entry journalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			torn++
+			continue
+		}
+		switch entry.Op {
+		case journalOpBegin:
+			pending[entry.TxID] = &entry
+			order = append(order, entry.TxID)
+		case journalOpCommit, journalOpAbort:
+			delete(pending, entry.TxID)
+		}
+	}
+	scanErr := scanner.Err()
+	f.Close()
+	if scanErr != nil {
+		return fmt.Errorf("读取日志失败: %v", scanErr)
+	}
+
+	replayed := 0
+	for _, txID := range order {
+		entry, ok := pending[txID]
+		if !ok {
+			continue
+		}
+		if err := ts.applyWritesLocked(entry.Writes); err != nil {
+			return fmt.Errorf("重放日志批次 %s 失败: %v", txID, err)
+		}
+		replayed++
+	}
+
+	if replayed > 0 || torn > 0 {
+		utils.Warn("TXT存储日志恢复 %s: 重放未提交批次 %d 个，丢弃不完整记录 %d 条", filepath.Base(path), replayed, torn)
+	}
+	return nil
+}
+
+// checkIntegrity 启动完整性扫描：清理残留临时文件，报告或隔离无法解析的数据文件
+// 只在独占数据目录时调用，不会删除其他进程正在写入的临时文件
+func (ts *TxtStorage) checkIntegrity(mode string) error {
+	if mode == IntegrityCheckOff {
+		return nil
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	tables, err := ioutil.ReadDir(ts.dataDir)
+	if err != nil {
+		return fmt.Errorf("读取数据目录失败: %v", err)
+	}
+
+	corrupt := 0
+	for _, table := range tables {
+		if !table.IsDir() || isReservedName(table.Name()) {
+			continue
+		}
+
+		tablePath := ts.getTablePath(table.Name())
+		files, err := ioutil.ReadDir(tablePath)
+		if err != nil {
+			return fmt.Errorf("读取目录失败: %v", err)
+		}
+
+		for _, file := range files {
+			filePath := filepath.Join(tablePath, file.Name())
+
+			// 崩溃残留的临时文件
+			if strings.HasPrefix(file.Name(), ".") && strings.Contains(file.Name(), ".tmp-") {
+				os.Remove(filePath)
+				continue
+			}
+			if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+				continue
+			}
+
+			jsonData, err := ioutil.ReadFile(filePath)
+			if err == nil && json.Valid(jsonData) {
+				continue
+			}
+
+			corrupt++
+			if mode != IntegrityCheckQuarantine {
+				utils.Error("发现损坏的数据文件: %s", filePath)
+				continue
+			}
+
+			if err := ts.quarantineLocked(table.Name(), file.Name()); err != nil {
+				utils.Error("隔离损坏文件失败: %s, %v", filePath, err)
+				continue
+			}
+			utils.Error("发现损坏的数据文件，已隔离: %s", filePath)
+		}
+	}
+
+	if corrupt > 0 {
+		utils.Warn("TXT存储完整性检查完成，损坏文件: %d 个 (模式: %s)", corrupt, mode)
+	}
+	return nil
+}
+
+// quarantineLocked 将损坏文件移入隔离目录
+func (ts *TxtStorage) quarantineLocked(table, fileName string) error {
+	dir := filepath.Join(ts.dataDir, quarantineDirName, table)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	target := filepath.Join(dir, fmt.Sprintf("%s.%d", fileName, time.Now().UnixNano()))
+	return os.Rename(filepath.Join(ts.getTablePath(table), fileName), target)
+}
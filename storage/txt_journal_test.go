@@ -0,0 +1,240 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type journalTestRecord struct {
+	Name string `json:"name"`
+}
+
+func openTxtStorage(t *testing.T, dir string) *TxtStorage {
+	t.Helper()
+	ts := NewTxtStorage()
+	if err := ts.Init(map[string]interface{}{"data_dir": dir}); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	return ts
+}
+
+func loadName(t *testing.T, ts *TxtStorage, table, key string) string {
+	t.Helper()
+	var record journalTestRecord
+	if err := ts.Get(table, key, &record); err != nil {
+		t.Fatalf("Get %s/%s: %v", table, key, err)
+	}
+	return record.Name
+}
+
+func rawRecord(t *testing.T, name string) json.RawMessage {
+	t.Helper()
+	data, err := marshalRecord(journalTestRecord{Name: name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// 写入中途失败的批次应恢复原内容并记录回滚，重启后不会被重放覆盖之后的写入
+func TestJournalFailedBatchIsRolledBackAndNotReplayed(t *testing.T) {
+	dir := t.TempDir()
+	ts := openTxtStorage(t, dir)
+
+	if err := ts.Save("players", "p1", journalTestRecord{Name: "old"}); err != nil {
+		t.Fatal(err)
+	}
+	// 同名普通文件让第二张表的目录无法创建，批次在第一条写入之后失败
+	if err := os.WriteFile(filepath.Join(dir, "broken"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ts.mu.Lock()
+	err := ts.writeBatchLocked([]journalWrite{
+		{Table: "players", Key: "p1", Data: rawRecord(t, "failed")},
+		{Table: "players", Key: "p2", Data: rawRecord(t, "failed")},
+		{Table: "broken", Key: "k", Data: rawRecord(t, "failed")},
+	})
+	ts.mu.Unlock()
+	if err == nil {
+		t.Fatal("批次应写入失败")
+	}
+
+	if got := loadName(t, ts, "players", "p1"); got != "old" {
+		t.Fatalf("失败的批次应恢复原内容, got %q", got)
+	}
+	if exists, _ := ts.Exists("players", "p2"); exists {
+		t.Fatal("失败的批次新建的记录应被删除")
+	}
+
+	// 之后的普通写入不应在重启恢复时被失败的批次覆盖
+	if err := ts.Save("players", "p1", journalTestRecord{Name: "newer"}); err != nil {
+		t.Fatal(err)
+	}
+	ts.Close()
+
+	ts = openTxtStorage(t, dir)
+	defer ts.Close()
+	if got := loadName(t, ts, "players", "p1"); got != "newer" {
+		t.Fatalf("重启后不应重放已回滚的批次, got %q", got)
+	}
+	if exists, _ := ts.Exists("players", "p2"); exists {
+		t.Fatal("重启后不应重放已回滚的批次")
+	}
+}
+
+// 只有 begin 没有 commit 的批次（写入过程中崩溃）应在启动时重放
+func TestJournalReplaysUncommittedBatch(t *testing.T) {
+	dir := t.TempDir()
+	ts := openTxtStorage(t, dir)
+	if err := ts.Save("players", "p1", journalTestRecord{Name: "old"}); err != nil {
+		t.Fatal(err)
+	}
+
+	ts.mu.Lock()
+	writes := []journalWrite{
+		{Table: "players", Key: "p1", Data: rawRecord(t, "crashed")},
+		{Table: "players", Key: "p2", Data: rawRecord(t, "crashed")},
+	}
+	if err := ts.capturePrevLocked(writes); err != nil {
+		t.Fatal(err)
+	}
+	if err := ts.appendJournalLocked(&journalEntry{TxID: "tx-crash", Op: journalOpBegin, Writes: writes}); err != nil {
+		t.Fatal(err)
+	}
+	// 模拟崩溃：只写入了第一条
+	if err := ts.writeRawLocked("players", "p1", writes[0].Data); err != nil {
+		t.Fatal(err)
+	}
+	ts.mu.Unlock()
+	ts.Close()
+
+	ts = openTxtStorage(t, dir)
+	defer ts.Close()
+	for _, key := range []string{"p1", "p2"} {
+		if got := loadName(t, ts, "players", key); got != "crashed" {
+			t.Fatalf("未提交的批次应整体重放, %s got %q", key, got)
+		}
+	}
+}
+
+// 已回滚和已提交的批次在启动时都不重放
+func TestJournalSkipsAbortedAndCommittedBatches(t *testing.T) {
+	dir := t.TempDir()
+	ts := openTxtStorage(t, dir)
+	if err := ts.Save("players", "p1", journalTestRecord{Name: "current"}); err != nil {
+		t.Fatal(err)
+	}
+
+	ts.mu.Lock()
+	for _, entry := range []*journalEntry{
+		{TxID: "tx-aborted", Op: journalOpBegin, Writes: []journalWrite{{Table: "players", Key: "p1", Data: rawRecord(t, "aborted")}}},
+		{TxID: "tx-aborted", Op: journalOpAbort},
+		{TxID: "tx-committed", Op: journalOpBegin, Writes: []journalWrite{{Table: "players", Key: "p1", Data: rawRecord(t, "committed")}}},
+		{TxID: "tx-committed", Op: journalOpCommit},
+	} {
+		if err := ts.appendJournalLocked(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ts.mu.Unlock()
+	ts.Close()
+
+	ts = openTxtStorage(t, dir)
+	defer ts.Close()
+	if got := loadName(t, ts, "players", "p1"); got != "current" {
+		t.Fatalf("不应重放已回滚或已提交的批次, got %q", got)
+	}
+	if journals := journalFiles(t, dir); len(journals) != 0 {
+		t.Fatalf("恢复后日志应被删除: %v", journals)
+	}
+}
+
+func journalFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join(dir, journalFilePrefix+"*.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return paths
+}
+
+// 有回滚失败的批次时，日志超过大小上限也不能清空，否则下次启动无法重放
+func TestJournalKeepsPendingBatchWhenCompacting(t *testing.T) {
+	dir := t.TempDir()
+	ts := openTxtStorage(t, dir)
+	defer ts.Close()
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if err := ts.appendJournalLocked(&journalEntry{TxID: "tx-pending", Op: journalOpBegin}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(ts.journalPath(), journalCompactSize); err != nil {
+		t.Fatal(err)
+	}
+
+	ts.pendingTx["tx-pending"] = true
+	ts.compactJournalLocked()
+	if info, err := os.Stat(ts.journalPath()); err != nil || info.Size() != journalCompactSize {
+		t.Fatalf("有待重放的批次时不应清空日志: %v", err)
+	}
+
+	delete(ts.pendingTx, "tx-pending")
+	ts.compactJournalLocked()
+	if info, err := os.Stat(ts.journalPath()); err != nil || info.Size() != 0 {
+		t.Fatalf("没有待重放的批次时应清空超过上限的日志: %v", err)
+	}
+}
+
+// 数据目录正被其他进程使用时，启动不重放日志、不清理临时文件；独占打开被拒绝
+func TestInitSkipsRecoveryWhileDataDirInUse(t *testing.T) {
+	dir := t.TempDir()
+	running := openTxtStorage(t, dir)
+	if err := running.Save("players", "p1", journalTestRecord{Name: "live"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 运行中进程的未提交批次和正在写入的临时文件
+	running.mu.Lock()
+	err := running.appendJournalLocked(&journalEntry{TxID: "tx-live", Op: journalOpBegin, Writes: []journalWrite{
+		{Table: "players", Key: "p1", Data: rawRecord(t, "in-flight")},
+	}})
+	running.mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpPath := filepath.Join(dir, "players", ".p1.json.tmp-live")
+	if err := os.WriteFile(tmpPath, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	other := openTxtStorage(t, dir)
+	if got := loadName(t, other, "players", "p1"); got != "live" {
+		t.Fatalf("其他进程运行中时不应重放其日志, got %q", got)
+	}
+	if _, err := os.Stat(tmpPath); err != nil {
+		t.Fatalf("不应删除其他进程的临时文件: %v", err)
+	}
+
+	tool := NewTxtStorage()
+	if err := tool.Init(map[string]interface{}{"data_dir": dir, "exclusive": true}); !errors.Is(err, ErrDataDirLocked) {
+		t.Fatalf("数据目录被使用时独占打开应返回 ErrDataDirLocked, got %v", err)
+	}
+
+	other.Close()
+	running.Close()
+
+	// 所有进程退出后，下一个启动的进程执行恢复
+	ts := openTxtStorage(t, dir)
+	defer ts.Close()
+	if got := loadName(t, ts, "players", "p1"); got != "in-flight" {
+		t.Fatalf("进程退出后应重放其未提交的批次, got %q", got)
+	}
+	if _, err := os.Stat(tmpPath); !os.IsNotExist(err) {
+		t.Fatalf("进程退出后应清理残留的临时文件: %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// lockFileName 数据目录锁文件
+// 使用数据目录的进程在整个生命周期中持有共享锁；启动恢复和备份、恢复、迁移等工具需要独占锁
+const lockFileName = "_lock"
+
+// ErrDataDirLocked 数据目录正被其他进程使用（独占打开时返回）
+var ErrDataDirLocked = errors.New("数据目录正被其他进程使用")
+
+// errLockHeld 文件锁被其他进程持有（非阻塞加锁时返回）
+var errLockHeld = errors.New("文件锁被占用")
+
+// lockDataDir 锁定数据目录，返回是否独占（独占时才能执行日志恢复和完整性检查）
+// exclusive 为 true 时整个生命周期独占，目录被其他进程使用时返回 ErrDataDirLocked；
+// 否则独占失败时等待共享锁（其他进程正在执行启动恢复时等待其完成）
+func (ts *TxtStorage) lockDataDir(exclusive bool) (bool, error) {
+	f, err := os.OpenFile(filepath.Join(ts.dataDir, lockFileName), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return false, fmt.Errorf("打开数据目录锁失败: %v", err)
+	}
+
+	err = lockFile(f, true, false)
+	if err == nil {
+		ts.dirLock = f
+		return true, nil
+	}
+	if err != errLockHeld {
+		f.Close()
+		return false, fmt.Errorf("锁定数据目录失败: %v", err)
+	}
+	if exclusive {
+		f.Close()
+		return false, fmt.Errorf("%w: %s", ErrDataDirLocked, ts.dataDir)
+	}
+
+	if err := lockFile(f, false, true); err != nil {
+		f.Close()
+		return false, fmt.Errorf("锁定数据目录失败: %v", err)
+	}
+	ts.dirLock = f
+	return false, nil
+}
+
+// shareDataDir 启动恢复完成后把独占锁换成共享锁，允许其他进程使用数据目录
+func (ts *TxtStorage) shareDataDir() error {
+	if err := unlockFile(ts.dirLock); err != nil {
+		return fmt.Errorf("释放数据目录锁失败: %v", err)
+	}
+	if err := lockFile(ts.dirLock, false, true); err != nil {
+		return fmt.Errorf("锁定数据目录失败: %v", err)
+	}
+	return nil
+}
+
+// unlockDataDir 释放数据目录锁
+func (ts *TxtStorage) unlockDataDir() {
+	if ts.dirLock == nil {
+		return
+	}
+	unlockFile(ts.dirLock)
+	ts.dirLock.Close()
+	ts.dirLock = nil
+}
@@ -6,15 +6,31 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 	"towerdefense/utils"
+	
+	"github.com/google/uuid"
 )
 
 // TxtStorage TXT文件存储实现
 // 使用 JSON 格式存储，便于后期迁移到数据库
+// 单条写入采用 临时文件+fsync+rename 保证原子性，批量写入额外通过预写日志保证可恢复
+// 带过期时间的记录在数据文件旁保存过期时间文件，由后台协程定时清理
+// 多个进程可以共用数据目录（账号服、游戏服），启动恢复只在没有其他进程使用该目录时执行
 type TxtStorage struct {
-	dataDir string // 数据目录
-	mu      sync.RWMutex
+	dataDir  string // 数据目录
+	mu       sync.RWMutex
+	
+	journalName string          // 本进程的日志文件名
+	pendingTx   map[string]bool // 回滚失败、等待下次启动重放的批次
+	dirLock     *os.File        // 数据目录锁
+	
+	stopChan  chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
 }
 
 // NewTxtStorage 创建TXT存储
@@ -32,17 +48,78 @@ func (ts *TxtStorage) Init(config map[string]interface{}) error {
 	
 	ts.dataDir = dataDir
 	
+	// 完整性检查模式: off / report / quarantine
+	integrityMode := IntegrityCheckQuarantine
+	if mode, ok := config["integrity_check"].(string); ok {
+		integrityMode = mode
+	}
+	
 	// 创建数据目录
 	if err := os.MkdirAll(dataDir, 0755); err != nil {
 		return fmt.Errorf("创建数据目录失败: %v", err)
 	}
 	
+	ts.journalName = fmt.Sprintf("%s-%s.log", journalFilePrefix, uuid.New().String()[:8])
+	ts.pendingTx = make(map[string]bool)
+	
+	// 锁定数据目录："exclusive": true 用于备份、恢复、迁移等工具，目录被其他进程使用时返回 ErrDataDirLocked
+	exclusive, _ := config["exclusive"].(bool)
+	owner, err := ts.lockDataDir(exclusive)
+	if err != nil {
+		return err
+	}
+	
+	if owner {
+		if err := ts.recoverOnStart(integrityMode); err != nil {
+			ts.unlockDataDir()
+			return err
+		}
+		if !exclusive {
+			if err := ts.shareDataDir(); err != nil {
+				ts.unlockDataDir()
+				return err
+			}
+		}
+	} else {
+		// 其他进程的日志和临时文件可能仍在使用中，恢复留给下一个独占启动的进程
+		utils.Warn("数据目录 %s 正被其他进程使用，跳过日志恢复和完整性检查", dataDir)
+	}
+	
+	// 启动过期清理
+	reapInterval := settingInt(config, "ttl_reap_interval", defaultReapInterval)
+	if reapInterval <= 0 {
+		reapInterval = defaultReapInterval
+	}
+	ts.stopChan = make(chan struct{})
+	ts.wg.Add(1)
+	go ts.reapLoop(time.Duration(reapInterval) * time.Second)
+	
 	utils.Info("TXT存储初始化完成，数据目录: %s", dataDir)
 	return nil
 }
 
+// recoverOnStart 独占数据目录时执行的启动恢复：重放未完成的批量写入、扫描损坏文件、迁移旧版过期索引
+func (ts *TxtStorage) recoverOnStart(integrityMode string) error {
+	if err := ts.recoverJournal(); err != nil {
+		return fmt.Errorf("日志恢复失败: %v", err)
+	}
+	if err := ts.checkIntegrity(integrityMode); err != nil {
+		return fmt.Errorf("完整性检查失败: %v", err)
+	}
+	return ts.migrateLegacyExpiry()
+}
+
 // Close 关闭存储
 func (ts *TxtStorage) Close() error {
+	ts.closeOnce.Do(func() {
+		if ts.stopChan != nil {
+			close(ts.stopChan)
+			ts.wg.Wait()
+		}
+		ts.mu.Lock()
+		ts.unlockDataDir()
+		ts.mu.Unlock()
+	})
 	utils.Info("TXT存储关闭")
 	return nil
 }
@@ -69,31 +146,55 @@ func (ts *TxtStorage) Save(table string, key string, data interface{}) error {
 	ts.mu.Lock()
 	defer ts.mu.Unlock()
 	
-	// 确保表目录存在
-	if err := ts.ensureTableDir(table); err != nil {
+	if err := ts.writeLocked(table, key, data); err != nil {
 		return err
 	}
-	
-	// 序列化为JSON
-	jsonData, err := json.MarshalIndent(data, "", "  ")
+	return ts.clearExpiryLocked(table, key)
+}
+
+// writeLocked 写入数据文件（调用时已持有写锁）
+func (ts *TxtStorage) writeLocked(table string, key string, data interface{}) error {
+	jsonData, err := marshalRecord(data)
 	if err != nil {
-		return fmt.Errorf("JSON序列化失败: %v", err)
+		return err
+	}
+	return ts.writeRawLocked(table, key, jsonData)
+}
+
+// writeRawLocked 原子写入已序列化的数据（调用时已持有写锁）
+func (ts *TxtStorage) writeRawLocked(table string, key string, jsonData []byte) error {
+	// 确保表目录存在
+	if err := ts.ensureTableDir(table); err != nil {
+		return err
 	}
 	
 	// 写入文件
 	filePath := ts.getFilePath(table, key)
-	if err := ioutil.WriteFile(filePath, jsonData, 0644); err != nil {
+	if err := writeFileAtomic(filePath, jsonData); err != nil {
 		return fmt.Errorf("写入文件失败: %v", err)
 	}
 	
 	return nil
 }
 
+// marshalRecord 序列化为JSON
+func marshalRecord(data interface{}) ([]byte, error) {
+	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("JSON序列化失败: %v", err)
+	}
+	return jsonData, nil
+}
+
 // Get 获取数据
 func (ts *TxtStorage) Get(table string, key string, result interface{}) error {
 	ts.mu.RLock()
 	defer ts.mu.RUnlock()
 	
+	if ts.isExpiredLocked(table, key) {
+		return fmt.Errorf("数据不存在: %s/%s", table, key)
+	}
+	
 	// 读取文件
 	filePath := ts.getFilePath(table, key)
 	jsonData, err := ioutil.ReadFile(filePath)
@@ -118,14 +219,12 @@ func (ts *TxtStorage) Delete(table string, key string) error {
 	defer ts.mu.Unlock()
 	
 	filePath := ts.getFilePath(table, key)
-	if err := os.Remove(filePath); err != nil {
-		if os.IsNotExist(err) {
-			return nil // 文件不存在，视为删除成功
-		}
+	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("删除文件失败: %v", err)
 	}
 	
-	return nil
+	// 文件不存在视为删除成功
+	return ts.clearExpiryLocked(table, key)
 }
 
 // GetAll 获取所有数据
@@ -151,6 +250,9 @@ func (ts *TxtStorage) GetAll(table string) ([]interface{}, error) {
 		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
 			continue
 		}
+		if ts.isExpiredLocked(table, strings.TrimSuffix(file.Name(), ".json")) {
+			continue
+		}
 		
 		// 读取文件内容
 		filePath := filepath.Join(tablePath, file.Name())
@@ -160,10 +262,10 @@ func (ts *TxtStorage) GetAll(table string) ([]interface{}, error) {
 			continue
 		}
 		
-		// 反序列化为 map
+		// 反序列化为 map（损坏文件在启动完整性检查时隔离，运行期出现说明有外部改动）
 		var data map[string]interface{}
 		if err := json.Unmarshal(jsonData, &data); err != nil {
-			utils.Warn("JSON反序列化失败: %s, %v", filePath, err)
+			utils.Error("JSON反序列化失败，跳过损坏文件: %s, %v", filePath, err)
 			continue
 		}
 		
@@ -211,10 +313,25 @@ func (ts *TxtStorage) Query(table string, condition map[string]interface{}) ([]i
 	return results, nil
 }
 
-// SaveBatch 批量保存
+// SaveBatch 批量保存（通过预写日志保证崩溃后可整体重放）
 func (ts *TxtStorage) SaveBatch(table string, items map[string]interface{}) error {
+	writes := make([]journalWrite, 0, len(items))
 	for key, data := range items {
-		if err := ts.Save(table, key, data); err != nil {
+		jsonData, err := marshalRecord(data)
+		if err != nil {
+			return err
+		}
+		writes = append(writes, journalWrite{Table: table, Key: key, Data: jsonData})
+	}
+	
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	
+	if err := ts.writeBatchLocked(writes); err != nil {
+		return err
+	}
+	for key := range items {
+		if err := ts.clearExpiryLocked(table, key); err != nil {
 			return err
 		}
 	}
@@ -226,6 +343,10 @@ func (ts *TxtStorage) Exists(table string, key string) (bool, error) {
 	ts.mu.RLock()
 	defer ts.mu.RUnlock()
 	
+	if ts.isExpiredLocked(table, key) {
+		return false, nil
+	}
+	
 	filePath := ts.getFilePath(table, key)
 	_, err := os.Stat(filePath)
 	if err != nil {
@@ -237,3 +358,181 @@ func (ts *TxtStorage) Exists(table string, key string) (bool, error) {
 	
 	return true, nil
 }
+
+// CompareAndSave 比较并保存（乐观锁）
+func (ts *TxtStorage) CompareAndSave(table string, key string, expectedVersion int64, data Versioned) error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	
+	if err := ts.checkVersionLocked(table, key, expectedVersion); err != nil {
+		return err
+	}
+	
+	data.SetVersion(expectedVersion + 1)
+	if err := ts.writeLocked(table, key, data); err != nil {
+		data.SetVersion(expectedVersion)
+		return err
+	}
+	return ts.clearExpiryLocked(table, key)
+}
+
+// CompareAndSaveMulti 多键事务写入
+// 先校验全部版本，再通过预写日志写入；TXT存储以全局写锁保证其他写入者看不到中间状态
+func (ts *TxtStorage) CompareAndSaveMulti(ops []TxOp) error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	
+	for _, op := range ops {
+		if err := ts.checkVersionLocked(op.Table, op.Key, op.ExpectedVersion); err != nil {
+			return err
+		}
+	}
+	
+	writes := make([]journalWrite, 0, len(ops))
+	for _, op := range ops {
+		op.Data.SetVersion(op.ExpectedVersion + 1)
+		jsonData, err := marshalRecord(op.Data)
+		if err != nil {
+			return err
+		}
+		writes = append(writes, journalWrite{Table: op.Table, Key: op.Key, Data: jsonData})
+	}
+	
+	if err := ts.writeBatchLocked(writes); err != nil {
+		for _, op := range ops {
+			op.Data.SetVersion(op.ExpectedVersion)
+		}
+		return err
+	}
+	for _, op := range ops {
+		if err := ts.clearExpiryLocked(op.Table, op.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkVersionLocked 校验存储中的版本号（调用时已持有锁）
+func (ts *TxtStorage) checkVersionLocked(table string, key string, expectedVersion int64) error {
+	current, err := ts.readVersionLocked(table, key)
+	if err != nil {
+		return err
+	}
+	if current != expectedVersion {
+		return fmt.Errorf("%w: %s/%s 期望版本 %d，当前版本 %d", ErrVersionConflict, table, key, expectedVersion, current)
+	}
+	return nil
+}
+
+// readVersionLocked 读取记录当前版本号，记录不存在（或已过期）时返回 0
+func (ts *TxtStorage) readVersionLocked(table string, key string) (int64, error) {
+	if ts.isExpiredLocked(table, key) {
+		return 0, nil
+	}
+	
+	jsonData, err := ioutil.ReadFile(ts.getFilePath(table, key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("读取文件失败: %v", err)
+	}
+	
+	var record VersionedRecord
+	if err := json.Unmarshal(jsonData, &record); err != nil {
+		return 0, fmt.Errorf("JSON反序列化失败: %v", err)
+	}
+	return record.Version, nil
+}
+
+// Tables 列出所有表
+func (ts *TxtStorage) Tables() ([]string, error) {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	
+	entries, err := ioutil.ReadDir(ts.dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("读取数据目录失败: %v", err)
+	}
+	
+	tables := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() && !isReservedName(entry.Name()) {
+			tables = append(tables, entry.Name())
+		}
+	}
+	sort.Strings(tables)
+	return tables, nil
+}
+
+// Keys 列出表中所有键
+func (ts *TxtStorage) Keys(table string) ([]string, error) {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	
+	files, err := ioutil.ReadDir(ts.getTablePath(table))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, fmt.Errorf("读取目录失败: %v", err)
+	}
+	
+	keys := make([]string, 0, len(files))
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || strings.HasPrefix(name, ".") || filepath.Ext(name) != ".json" {
+			continue
+		}
+		if ts.isExpiredLocked(table, strings.TrimSuffix(name, ".json")) {
+			continue
+		}
+		keys = append(keys, strings.TrimSuffix(name, ".json"))
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Snapshot 一致性快照（持有读锁期间读取全部表，写入被阻塞）
+// 带过期时间的临时数据不进入快照
+func (ts *TxtStorage) Snapshot() (Snapshot, error) {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	
+	entries, err := ioutil.ReadDir(ts.dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("读取数据目录失败: %v", err)
+	}
+	
+	snapshot := make(Snapshot)
+	for _, entry := range entries {
+		if !entry.IsDir() || isReservedName(entry.Name()) {
+			continue
+		}
+		
+		table := entry.Name()
+		files, err := ioutil.ReadDir(ts.getTablePath(table))
+		if err != nil {
+			return nil, fmt.Errorf("读取目录失败: %v", err)
+		}
+		
+		records := make(map[string]json.RawMessage, len(files))
+		for _, file := range files {
+			name := file.Name()
+			if file.IsDir() || strings.HasPrefix(name, ".") || filepath.Ext(name) != ".json" {
+				continue
+			}
+			if ts.hasExpiryLocked(table, strings.TrimSuffix(name, ".json")) {
+				continue
+			}
+			jsonData, err := ioutil.ReadFile(filepath.Join(ts.getTablePath(table), name))
+			if err != nil {
+				return nil, fmt.Errorf("读取文件失败: %v", err)
+			}
+			records[strings.TrimSuffix(name, ".json")] = jsonData
+		}
+		snapshot[table] = records
+	}
+	
+	return snapshot, nil
+}
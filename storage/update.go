@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MaxUpdateRetries 乐观锁冲突时的最大重试次数
+const MaxUpdateRetries = 5
+
+// VersionedRecord 可直接嵌入数据模型的版本号字段
+type VersionedRecord struct {
+	Version int64 `json:"version"`
+}
+
+// GetVersion 获取版本号
+func (vr *VersionedRecord) GetVersion() int64 {
+	return vr.Version
+}
+
+// SetVersion 设置版本号
+func (vr *VersionedRecord) SetVersion(version int64) {
+	vr.Version = version
+}
+
+// Update 读取-修改-写回，版本冲突时自动重试
+// fn 每次重试都会拿到最新读取的数据，返回错误则放弃本次更新
+func Update[T any, PT interface {
+	*T
+	Versioned
+}](s IStorage, table string, key string, fn func(PT) error) error {
+	for i := 0; i < MaxUpdateRetries; i++ {
+		record := PT(new(T))
+		if err := s.Get(table, key, record); err != nil {
+			return err
+		}
+
+		expected := record.GetVersion()
+		if err := fn(record); err != nil {
+			return err
+		}
+
+		err := s.CompareAndSave(table, key, expected, record)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, ErrVersionConflict) {
+			return err
+		}
+	}
+
+	return fmt.Errorf("%w: %s/%s 重试 %d 次仍失败", ErrVersionConflict, table, key, MaxUpdateRetries)
+}
+
+// CompareAndSaveMulti 多键事务写入
+// 存储支持事务时原子执行，否则返回错误（调用方不应退化为逐条写入）
+func CompareAndSaveMulti(s IStorage, ops []TxOp) error {
+	tx, ok := s.(ITransactional)
+	if !ok {
+		return fmt.Errorf("当前存储不支持多键事务")
+	}
+	return tx.CompareAndSaveMulti(ops)
+}
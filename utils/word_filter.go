@@ -0,0 +1,220 @@
+package utils
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// WordFilter 敏感词过滤器（Aho-Corasick 自动机，按字符匹配，忽略大小写）
+// 只由英文字母和数字组成的词按整词匹配（前后不能紧挨字母或数字，如 gm 不匹配 dogma），其余词按子串匹配
+// 构建后只读，可在多个协程中共享（玩家名称、聊天等）
+type WordFilter struct {
+	nodes []acNode
+}
+
+// acNode 自动机节点
+type acNode struct {
+	next    map[rune]int
+	fail    int
+	outputs []int // 以该节点结尾的全部敏感词长度（字符数），从长到短
+}
+
+// NewWordFilter 由敏感词列表构建过滤器（空词和重复词会被忽略）
+func NewWordFilter(words []string) *WordFilter {
+	wf := &WordFilter{nodes: []acNode{{next: make(map[rune]int)}}}
+	for _, word := range words {
+		wf.insert(normalizeWord(word))
+	}
+	wf.build()
+	return wf
+}
+
+// LoadWordFilter 从词表文件构建过滤器（每行一个词，# 开头为注释）
+func LoadWordFilter(path string) (*WordFilter, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	words := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return NewWordFilter(words), nil
+}
+
+// Contains 文本中是否包含敏感词
+func (wf *WordFilter) Contains(text string) bool {
+	found := false
+	wf.scan(text, func(start, end int) bool {
+		found = true
+		return false
+	})
+	return found
+}
+
+// Replace 将文本中的敏感词逐字替换为 mask
+func (wf *WordFilter) Replace(text string, mask rune) string {
+	runes := []rune(text)
+	masked := false
+	wf.scan(text, func(start, end int) bool {
+		for i := start; i < end; i++ {
+			runes[i] = mask
+		}
+		masked = true
+		return true
+	})
+	if !masked {
+		return text
+	}
+	return string(runes)
+}
+
+// insert 插入一个敏感词
+func (wf *WordFilter) insert(word []rune) {
+	if len(word) == 0 {
+		return
+	}
+	cur := 0
+	for _, r := range word {
+		next, ok := wf.nodes[cur].next[r]
+		if !ok {
+			next = len(wf.nodes)
+			wf.nodes = append(wf.nodes, acNode{next: make(map[rune]int)})
+			wf.nodes[cur].next[r] = next
+		}
+		cur = next
+	}
+	if len(wf.nodes[cur].outputs) == 0 {
+		wf.nodes[cur].outputs = []int{len(word)}
+	}
+}
+
+// build 按层次遍历构建失败指针，并把失败链上的词尾合并到当前节点
+func (wf *WordFilter) build() {
+	queue := make([]int, 0, len(wf.nodes))
+	for _, child := range wf.nodes[0].next {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for r, child := range wf.nodes[cur].next {
+			fail := wf.nodes[cur].fail
+			for fail != 0 {
+				if _, ok := wf.nodes[fail].next[r]; ok {
+					break
+				}
+				fail = wf.nodes[fail].fail
+			}
+			if next, ok := wf.nodes[fail].next[r]; ok && next != child {
+				wf.nodes[child].fail = next
+			}
+			// 失败链上的词都是当前词的后缀，比当前节点的词短
+			wf.nodes[child].outputs = append(wf.nodes[child].outputs, wf.nodes[wf.nodes[child].fail].outputs...)
+			queue = append(queue, child)
+		}
+	}
+}
+
+// scan 扫描文本，每个结束位置命中的最长敏感词回调一次（字符下标区间 [start, end)），回调返回 false 时停止
+func (wf *WordFilter) scan(text string, hit func(start, end int) bool) {
+	if len(wf.nodes) <= 1 {
+		return
+	}
+	runes := normalizeWord(text)
+	cur := 0
+	for i, r := range runes {
+		for cur != 0 {
+			if _, ok := wf.nodes[cur].next[r]; ok {
+				break
+			}
+			cur = wf.nodes[cur].fail
+		}
+		if next, ok := wf.nodes[cur].next[r]; ok {
+			cur = next
+		}
+		for _, out := range wf.nodes[cur].outputs {
+			start, end := i+1-out, i+1
+			if isASCIIWord(runes[start:end]) && !isWordBoundary(runes, start, end) {
+				continue
+			}
+			if !hit(start, end) {
+				return
+			}
+			break
+		}
+	}
+}
+
+// isASCIIWord 是否只由英文字母和数字组成（这类词按整词匹配）
+func isASCIIWord(word []rune) bool {
+	for _, r := range word {
+		if !isASCIIAlnum(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// isWordBoundary 区间 [start, end) 前后是否都不紧挨英文字母或数字
+func isWordBoundary(runes []rune, start, end int) bool {
+	if start > 0 && isASCIIAlnum(runes[start-1]) {
+		return false
+	}
+	if end < len(runes) && isASCIIAlnum(runes[end]) {
+		return false
+	}
+	return true
+}
+
+// isASCIIAlnum 是否为英文字母或数字
+func isASCIIAlnum(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+}
+
+// normalizeWord 统一为小写字符序列，保证下标与原文本的字符一一对应
+func normalizeWord(text string) []rune {
+	runes := []rune(text)
+	for i, r := range runes {
+		runes[i] = unicode.ToLower(r)
+	}
+	return runes
+}
+
+var (
+	wordFilter   = NewWordFilter(nil)
+	wordFilterMu sync.RWMutex
+)
+
+// InitWordFilter 加载全局敏感词过滤器（名称、聊天等共用）
+func InitWordFilter(path string) error {
+	filter, err := LoadWordFilter(path)
+	if err != nil {
+		return err
+	}
+	wordFilterMu.Lock()
+	wordFilter = filter
+	wordFilterMu.Unlock()
+	Info("敏感词库加载成功: %s", path)
+	return nil
+}
+
+// GetWordFilter 获取全局敏感词过滤器（未加载词库时不过滤任何内容）
+func GetWordFilter() *WordFilter {
+	wordFilterMu.RLock()
+	defer wordFilterMu.RUnlock()
+	return wordFilter
+}
@@ -0,0 +1,83 @@
+package utils
+
+import "testing"
+
+// 重叠和互为后缀的敏感词都应被完整替换（英文词仍要求整词）
+func TestWordFilterOverlappingWords(t *testing.T) {
+	wf := NewWordFilter([]string{"he", "she", "hers", "官方", "方客服"})
+
+	cases := map[string]string{
+		"she, he, hers": "***, **, ****",
+		"ushers":        "ushers",
+		"官方客服电话":        "****电话",
+		"联系方客服":         "联系***",
+		"官方方客服":         "*****",
+	}
+	for text, want := range cases {
+		if got := wf.Replace(text, '*'); got != want {
+			t.Errorf("Replace(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+// 匹配忽略大小写，替换保留原文其余字符的大小写
+func TestWordFilterCaseFolding(t *testing.T) {
+	wf := NewWordFilter([]string{"Admin", "GM"})
+
+	for _, text := range []string{"admin", "ADMIN", "i am Gm", "AdMiN!"} {
+		if !wf.Contains(text) {
+			t.Errorf("Contains(%q) = false, want true", text)
+		}
+	}
+	if got := wf.Replace("Hi ADMIN!", '*'); got != "Hi *****!" {
+		t.Errorf("Replace = %q", got)
+	}
+}
+
+// 英文字母和数字组成的词按整词匹配，中文等其余词按子串匹配
+func TestWordFilterWholeWordForASCII(t *testing.T) {
+	wf := NewWordFilter([]string{"gm", "管理员"})
+
+	for text, want := range map[string]bool{
+		"gm":        true,
+		"call a gm": true,
+		"gm管理":      true,
+		"[GM]小明":    true,
+		"dogma":     false,
+		"gm123":     false,
+		"Sigma":     false,
+		"超级管理员2号":   true,
+		"abc管理员":    true,
+	} {
+		if got := wf.Contains(text); got != want {
+			t.Errorf("Contains(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
+
+// 替换按字符下标对齐，多字节字符和大小写转换不会造成错位
+func TestWordFilterReplaceIndexAlignment(t *testing.T) {
+	wf := NewWordFilter([]string{"管理员", "客服", "admin"})
+
+	cases := map[string]string{
+		"你好管理员abc":        "你好***abc",
+		"İ客服x":            "İ**x",
+		"😀admin😀":         "😀*****😀",
+		"管理员客服":           "*****",
+		"没有敏感词":           "没有敏感词",
+		"ÄÖ admin 管理员 ÜS": "ÄÖ ***** *** ÜS",
+	}
+	for text, want := range cases {
+		if got := wf.Replace(text, '*'); got != want {
+			t.Errorf("Replace(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+// 空词库不过滤任何内容
+func TestWordFilterEmpty(t *testing.T) {
+	wf := NewWordFilter([]string{"", ""})
+	if wf.Contains("anything") || wf.Replace("anything", '*') != "anything" {
+		t.Fatal("空词库不应命中")
+	}
+}